@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/deploy/manifest"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+func deployManifest(args []string) {
+	if len(args) < 1 || (args[0] != "plan" && args[0] != "apply") {
+		fmt.Fprintln(os.Stderr, "usage: fpmon deploy plan|apply [flags]")
+		os.Exit(2)
+	}
+	command := args[0]
+	flags := flag.NewFlagSet("deploy "+command, flag.ExitOnError)
+	path := flags.String("manifest", "fpmon.yaml", "manifest listing the networks and the monitors to run on them")
+	format := flags.String("format", "text", "output format: text or json")
+	autoApprove := flags.Bool("auto-approve", false, "apply the plan without asking for confirmation")
+	flags.Parse(args[1:])
+
+	write := manifest.WriteText
+	switch *format {
+	case "text":
+	case "json":
+		write = manifest.WriteJSON
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format %s\n", *format)
+		os.Exit(2)
+	}
+
+	m, err := manifest.Load(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading manifest %s: %v\n", *path, err)
+		os.Exit(2)
+	}
+	client := hexagate.NewClient(hexagate.WithAPIKey(hexagate.DotEnvKey(".env", hexagate.API_KEY_ENV)))
+	ctx := context.Background()
+	plan, err := manifest.MakePlan(ctx, client, m)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error planning: %v\n", err)
+		os.Exit(2)
+	}
+	if err := write(os.Stdout, plan); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing plan: %v\n", err)
+		os.Exit(2)
+	}
+
+	if command == "plan" {
+		if !plan.Empty() {
+			os.Exit(1)
+		}
+		return
+	}
+	if plan.Empty() {
+		return
+	}
+	if !*autoApprove && !confirm(os.Stdin, len(plan.Changes)) {
+		fmt.Fprintln(os.Stderr, "Apply cancelled.")
+		os.Exit(1)
+	}
+	n, err := plan.Apply(ctx, client)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error applying the plan after %d of %d changes: %v\n", n, len(plan.Changes), err)
+		os.Exit(2)
+	}
+	fmt.Fprintf(os.Stderr, "Applied %d changes.\n", n)
+}
+
+// confirm asks on stderr whether to apply the changes and reports whether the answer read from in is yes.
+func confirm(in io.Reader, changes int) bool {
+	fmt.Fprintf(os.Stderr, "Apply %d changes? Only yes is accepted: ", changes)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
@@ -0,0 +1,62 @@
+// Command fpmon runs the deployment workflows of the fault proof monitors against the Hexagate monitor
+// management API.
+//
+// Usage:
+//
+//	fpmon serve [-addr host:port] [-chain-id id] [-channel id]... [-param name=value]... [-state file]
+//	fpmon reap [-rpc url] [-chain-id id] [-grace duration] [-dry-run] [-format text|json] [-state file]
+//	fpmon deploy plan|apply [-manifest file] [-format text|json] [-auto-approve]
+//
+// serve listens for the webhook of a Contract Event monitor on the DisputeGameCreated events of the
+// DisputeGameFactory at /game-created, and deploys every Per DisputeGame monitor to the disputeProxy of each
+// event. It also listens for the webhook of fault_proof_detection_parent at /parent-alert, and deploys
+// fault_proof_detection_child once to every game the parent alerts on. The params every game shares, such as
+// honestChallenger, multicall3 or cbChallenger, are set with -param, and serve refuses to start unless they
+// match the declarations of every monitor it deploys. Every deployed monitor is recorded with its game in the
+// -state file.
+//
+// reap tears down the monitors of the games in the -state file whose lifecycle has ended: games that are
+// resolved, have no credit left to claim, and were resolved longer ago than the DelayedWETH delay plus the
+// -grace period. The games are read from the JSON-RPC endpoint given by -rpc or FPMON_RPC_URL. With -dry-run it
+// only reports what it would tear down. It exits with 1 if any game could not be read or torn down.
+//
+// deploy plan compares the Single Instance monitors of every network in the -manifest file (default fpmon.yaml)
+// to the monitors deployed to Hexagate, and prints the monitors to create, update and delete. It exits with 1
+// if there are any, including the monitors of networks removed from the manifest. deploy apply prints the same
+// plan and makes its changes once they are confirmed with yes on stdin, or without asking with -auto-approve.
+//
+// The Hexagate API key is read from HEXAGATE_API_KEY, or from a .env file in the working directory, and the
+// webhook secret from FPMON_WEBHOOK_SECRET.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	WEBHOOK_SECRET_ENV = "FPMON_WEBHOOK_SECRET"
+	RPC_URL_ENV        = "FPMON_RPC_URL"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "serve":
+		serve(args)
+	case "reap":
+		reap(args)
+	case "deploy":
+		deployManifest(args)
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: fpmon serve|reap|deploy [flags]")
+	os.Exit(2)
+}
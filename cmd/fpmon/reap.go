@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/base-org/fault-proof-monitors/deploy"
+	"github.com/base-org/fault-proof-monitors/deploy/state"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+func reap(args []string) {
+	flags := flag.NewFlagSet("reap", flag.ExitOnError)
+	chainId := flags.Int("chain-id", hexagate.CHAIN_ID_MAINNET, "chain ID the monitors are deployed on")
+	rpc := flags.String("rpc", os.Getenv(RPC_URL_ENV), "JSON-RPC URL of the L1 chain the games are on")
+	grace := flags.Duration("grace", 24*time.Hour, "how long to keep the monitors after the DelayedWETH delay has passed")
+	dryRun := flags.Bool("dry-run", false, "report which games would be torn down without changing anything")
+	format := flags.String("format", "text", "output format: text or json")
+	statePath := flags.String("state", "deployments.jsonl", "file recording the monitors deployed to every game")
+	flags.Parse(args)
+
+	if *rpc == "" {
+		fmt.Fprintf(os.Stderr, "Error: -rpc or %s is required\n", RPC_URL_ENV)
+		os.Exit(2)
+	}
+	write := deploy.WriteText
+	switch *format {
+	case "text":
+	case "json":
+		write = deploy.WriteJSON
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format %s\n", *format)
+		os.Exit(2)
+	}
+
+	store, err := state.OpenFile(*statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening state %s: %v\n", *statePath, err)
+		os.Exit(2)
+	}
+	defer store.Close()
+
+	client := hexagate.NewClient(
+		hexagate.WithChainID(*chainId),
+		hexagate.WithAPIKey(hexagate.DotEnvKey(".env", hexagate.API_KEY_ENV)),
+	)
+	reaper := deploy.NewReaper(client, store, deploy.NewRPCChain(*rpc, nil), deploy.ReaperConfig{Grace: *grace, DryRun: *dryRun})
+	report, err := reaper.Reap(context.Background())
+	if err != nil {
+		store.Close()
+		fmt.Fprintf(os.Stderr, "Error reaping: %v\n", err)
+		os.Exit(2)
+	}
+	if err := write(os.Stdout, report); err != nil {
+		store.Close()
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		os.Exit(2)
+	}
+	for _, d := range report.Games {
+		if d.Error != "" {
+			store.Close()
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/deploy"
+	"github.com/base-org/fault-proof-monitors/deploy/state"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	chainId := flags.Int("chain-id", hexagate.CHAIN_ID_MAINNET, "chain ID to deploy the monitors on")
+	var channels channelsFlag
+	flags.Var(&channels, "channel", "notification channel ID to send alerts to (repeatable)")
+	params := paramsFlag{}
+	flags.Var(params, "param", "name=value of a param shared by every game (repeatable)")
+	statePath := flags.String("state", "deployments.jsonl", "file recording the monitors deployed to every game")
+	flags.Parse(args)
+
+	store, err := state.OpenFile(*statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening state %s: %v\n", *statePath, err)
+		os.Exit(2)
+	}
+	defer store.Close()
+
+	client := hexagate.NewClient(
+		hexagate.WithChainID(*chainId),
+		hexagate.WithAPIKey(hexagate.DotEnvKey(".env", hexagate.API_KEY_ENV)),
+	)
+	deployer := deploy.NewDeployer(client, deploy.Config{ChainId: *chainId, NotificationChannelIds: channels, Params: params, Store: store})
+	if err := deployer.Check(); err != nil {
+		store.Close()
+		fmt.Fprintf(os.Stderr, "Error checking params: %v\n", err)
+		os.Exit(2)
+	}
+	opts := deploy.WebhookOptions{Secret: os.Getenv(WEBHOOK_SECRET_ENV)}
+
+	mux := http.NewServeMux()
+	mux.Handle("/game-created", deployer.GameCreatedHandler(opts))
+	mux.Handle("/parent-alert", deployer.ParentAlertHandler(opts))
+	slog.Info("listening for webhooks", "addr", *addr, "chain", *chainId)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		store.Close()
+		fmt.Fprintf(os.Stderr, "Error serving webhooks: %v\n", err)
+		os.Exit(2)
+	}
+}
+
+// channelsFlag collects repeated -channel flags.
+type channelsFlag []int
+
+func (c *channelsFlag) String() string {
+	return fmt.Sprint(*c)
+}
+
+func (c *channelsFlag) Set(value string) error {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("channel ID %s is not an integer", value)
+	}
+	*c = append(*c, id)
+	return nil
+}
+
+// paramsFlag collects repeated -param name=value flags, converted with deploy.ParamValue.
+type paramsFlag map[string]any
+
+func (p paramsFlag) String() string {
+	return fmt.Sprint(map[string]any(p))
+}
+
+func (p paramsFlag) Set(value string) error {
+	name, text, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("param %s must be name=value", value)
+	}
+	v, err := deploy.ParamValue(name, text)
+	if err != nil {
+		return err
+	}
+	p[name] = v
+	return nil
+}
@@ -0,0 +1,60 @@
+// Command gatecheck type checks gate monitor files, validates the Solidity signatures of their chain reads
+// and prints any diagnostics.
+//
+// Usage:
+//
+//	gatecheck [-abi dir] [files...]
+//
+// Without arguments it checks the embedded monitors. With -abi, signatures are also compared against the ABI
+// JSON files in dir, e.g. FaultDisputeGame.json. It exits with status 1 if any file has errors.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/abi"
+	"github.com/base-org/fault-proof-monitors/gate/check"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+func main() {
+	abiDir := flag.String("abi", "", "directory of contract ABI JSON files to compare signatures against")
+	flag.Parse()
+
+	var contracts []*abi.Contract
+	if *abiDir != "" {
+		var err error
+		if contracts, err = abi.LoadContracts(*abiDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading ABIs: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	sources, err := monitors.Sources(flag.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading monitors: %v\n", err)
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, source := range sources {
+		file, err := gate.ParseFile(source.Path, source.Src)
+		if err != nil {
+			fmt.Println(err)
+			failed = true
+			continue
+		}
+		info := check.Check(file)
+		for _, d := range append(info.Diagnostics, abi.Validate(file, info, contracts...)...) {
+			fmt.Println(d)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,69 @@
+// Command gatecov reports which invariants of the gate monitors the test suite sees firing and not firing,
+// from the records written by go test ./tests -coverage.
+//
+// Usage:
+//
+//	gatecov [-records file] [-format text|json] [-threshold percent] [files...]
+//
+// Without arguments it reports on the embedded monitors using the records in tests/coverage.json. Monitors
+// without tests are listed as N/A and count as uncovered. With -threshold it exits with status 1 if less than
+// the given percentage of invariant outcomes is covered.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/base-org/fault-proof-monitors/coverage"
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+func main() {
+	recordsPath := flag.String("records", "tests/coverage.json", "records written by go test ./tests -coverage")
+	format := flag.String("format", "text", "output format: text or json")
+	threshold := flag.Float64("threshold", 0, "minimum percentage of invariant outcomes that must be covered")
+	flag.Parse()
+
+	records, err := coverage.ReadFile(*recordsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading records: %v\n", err)
+		os.Exit(2)
+	}
+
+	sources, err := monitors.Sources(flag.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading monitors: %v\n", err)
+		os.Exit(2)
+	}
+
+	var files []*gate.File
+	for _, source := range sources {
+		file, err := gate.ParseFile(source.Path, source.Src)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		files = append(files, file)
+	}
+
+	report := coverage.Build(files, records)
+	switch *format {
+	case "text":
+		err = coverage.WriteText(os.Stdout, report)
+	case "json":
+		err = coverage.WriteJSON(os.Stdout, report)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		os.Exit(2)
+	}
+
+	if report.Percent < *threshold {
+		fmt.Fprintf(os.Stderr, "Invariant coverage %.1f%% is below the threshold of %.1f%%\n", report.Percent, *threshold)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CONTEXT_LINES is the number of unchanged lines shown around each change.
+const CONTEXT_LINES = 3
+
+type edit struct {
+	op   byte // ' ', '-' or '+'
+	line string
+}
+
+// unifiedDiff returns a unified diff between the original and formatted source of path. Monitor files are
+// small, so the edit script is computed from the full longest common subsequence table.
+func unifiedDiff(path string, a, b []byte) string {
+	x, y := splitLines(string(a)), splitLines(string(b))
+
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			edits = append(edits, edit{' ', x[i]})
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', x[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', y[j]})
+			j++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s.orig\n+++ %s\n", path, path)
+
+	// line numbers of the first edit of the current hunk in both files
+	aLine, bLine := 1, 1
+	for start := 0; start < len(edits); {
+		if edits[start].op == ' ' {
+			aLine++
+			bLine++
+			start++
+			continue
+		}
+
+		// extend the hunk until there are more than 2*CONTEXT_LINES unchanged lines after a change
+		end, unchanged := start, 0
+		for k := start; k < len(edits) && unchanged <= 2*CONTEXT_LINES; k++ {
+			if edits[k].op == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+				end = k + 1
+			}
+		}
+
+		from := max(start-CONTEXT_LINES, 0)
+		to := min(end+CONTEXT_LINES, len(edits))
+		hunkA, hunkB := aLine-(start-from), bLine-(start-from)
+		var countA, countB int
+		var body strings.Builder
+		for _, e := range edits[from:to] {
+			body.WriteString(string(e.op) + e.line + "\n")
+			if e.op != '+' {
+				countA++
+			}
+			if e.op != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n%s", hunkA, countA, hunkB, countB, body.String())
+
+		for _, e := range edits[start:to] {
+			if e.op != '+' {
+				aLine++
+			}
+			if e.op != '-' {
+				bLine++
+			}
+		}
+		start = to
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,132 @@
+// Command gatefmt formats gate monitor files in canonical form.
+//
+// Usage:
+//
+//	gatefmt [-l] [-d] [-w] [path ...]
+//
+// Paths may be files or directories, in which case every *.gate file in the directory is formatted. Without
+// paths it formats standard input. Like gofmt it prints the formatted source to standard output unless -l,
+// -d or -w is given. Like gofmt, a file that cannot be read or parsed is reported and the remaining files are
+// still formatted, and the command exits with status 2 at the end. Unlike gofmt it exits with status 1 when
+// -l or -d find unformatted files, so it can be used as a CI check.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/base-org/fault-proof-monitors/gate/format"
+)
+
+var (
+	list  = flag.Bool("l", false, "list files whose formatting differs from gatefmt's")
+	diff  = flag.Bool("d", false, "display diffs instead of rewriting files")
+	write = flag.Bool("w", false, "write result to (source) file instead of stdout")
+)
+
+func main() {
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "Error: cannot use -w with standard input")
+			os.Exit(2)
+		}
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(2)
+		}
+		changed, err := process("<standard input>", src, os.Stdout)
+		exit(changed, err)
+	}
+
+	var paths []string
+	for _, arg := range flag.Args() {
+		info, err := os.Stat(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(arg, "*.gate"))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		paths = append(paths, matches...)
+	}
+
+	anyChanged, failed := false, false
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		changed, err := process(path, src, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		anyChanged = anyChanged || changed
+	}
+	if failed {
+		os.Exit(2)
+	}
+	exit(anyChanged, nil)
+}
+
+// process formats a single file and reports whether its formatting changed.
+func process(path string, src []byte, out io.Writer) (bool, error) {
+	formatted, err := format.Source(path, src)
+	if err != nil {
+		return false, err
+	}
+	changed := !bytes.Equal(src, formatted)
+
+	if !*list && !*diff && !*write {
+		_, err := out.Write(formatted)
+		return false, err
+	}
+	if !changed {
+		return false, nil
+	}
+
+	if *list {
+		fmt.Fprintln(out, path)
+	}
+	if *write {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(path, formatted, info.Mode().Perm()); err != nil {
+			return false, err
+		}
+	}
+	if *diff {
+		fmt.Fprint(out, unifiedDiff(path, src, formatted))
+	}
+	return *list || *diff, nil
+}
+
+func exit(changed bool, err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if changed {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,77 @@
+// Command gategraph prints the dependency graph of gate monitors: the params, sources and invariants of each
+// monitor, with an edge from every value to the declarations computed from it. Sources that read the chain,
+// with Call, Calls, Events, HistoricalCalls, HistoricalEvents or the block, state and trace builtins, are
+// marked apart from computed sources.
+//
+// Usage:
+//
+//	gategraph [-format dot|mermaid|mocks] [files...]
+//
+// Without arguments it prints the graphs of the embedded monitors. The mocks format lists, for every invariant,
+// the chain reading sources a test must mock to evaluate it without the chain.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/graph"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+func main() {
+	format := flag.String("format", "dot", "output format: dot, mermaid or mocks")
+	flag.Parse()
+
+	sources, err := monitors.Sources(flag.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading monitors: %v\n", err)
+		os.Exit(2)
+	}
+
+	for i, source := range sources {
+		file, err := gate.ParseFile(source.Path, source.Src)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		g := graph.Build(file)
+		switch *format {
+		case "dot":
+			err = graph.WriteDOT(os.Stdout, g)
+		case "mermaid":
+			err = graph.WriteMermaid(os.Stdout, g)
+		case "mocks":
+			err = writeMocks(os.Stdout, g)
+		default:
+			err = fmt.Errorf("unknown format %q", *format)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing graph: %v\n", err)
+			os.Exit(2)
+		}
+	}
+}
+
+// writeMocks writes the sources to mock for every invariant of the monitor.
+func writeMocks(w io.Writer, g *graph.Graph) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:\n", g.Name)
+	for _, invariant := range g.Invariants() {
+		mocks := g.Mocks(invariant.ID)
+		if len(mocks) == 0 {
+			mocks = []string{"(none)"}
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", invariant.Label, strings.Join(mocks, ", "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
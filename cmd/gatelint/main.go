@@ -0,0 +1,76 @@
+// Command gatelint reports unused imports, unused params, sources that do not reach any invariant, shadowed
+// names and invariants missing the FilterAddressesInTrace guard in gate monitor files.
+//
+// Usage:
+//
+//	gatelint [-format text|json|sarif] [-config gatelint.yaml] [files...]
+//
+// Without arguments it lints the embedded monitors. Findings disabled by the config are not reported; without
+// -config the embedded monitors/gatelint.yaml is used. It exits with status 1 if there are any findings.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/lint"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+func main() {
+	format := flag.String("format", "text", "output format: text, json or sarif")
+	configPath := flag.String("config", "", "lint config disabling rules for monitors (default monitors/gatelint.yaml)")
+	flag.Parse()
+
+	data := monitors.LintConfig
+	if *configPath != "" {
+		var err error
+		data, err = os.ReadFile(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading config %s: %v\n", *configPath, err)
+			os.Exit(2)
+		}
+	}
+	config, err := lint.ParseConfig(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		os.Exit(2)
+	}
+
+	sources, err := monitors.Sources(flag.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading monitors: %v\n", err)
+		os.Exit(2)
+	}
+
+	findings := []lint.Finding{}
+	for _, source := range sources {
+		file, err := gate.ParseFile(source.Path, source.Src)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		findings = append(findings, config.Filter(lint.Lint(file))...)
+	}
+
+	switch *format {
+	case "text":
+		err = lint.WriteText(os.Stdout, findings)
+	case "json":
+		err = lint.WriteJSON(os.Stdout, findings)
+	case "sarif":
+		err = lint.WriteSARIF(os.Stdout, "gatelint", lint.Rules, findings)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing findings: %v\n", err)
+		os.Exit(2)
+	}
+
+	if len(findings) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,84 @@
+// Command gatemut runs mutation tests on gate monitors. It applies one small change at a time to a monitor,
+// such as a flipped comparison, a negated condition, a tweaked constant or a dropped comprehension filter, and
+// runs the tests of that monitor against the offline Hexagate fake. A mutant the tests still pass on has
+// survived, and points at logic the tests would not notice breaking.
+//
+// Usage:
+//
+//	gatemut [-tests dir] [-parallel n] [-timeout d] [-format text|json] [-threshold percent] [files...]
+//
+// Without arguments it mutates the embedded monitors. The tests of each monitor are found by running the test
+// suite once with coverage records, so monitors without tests are reported without running their mutants.
+// With -threshold it exits with status 1 if less than the given percentage of mutants is killed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/mutate"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+func main() {
+	testsDir := flag.String("tests", "tests", "directory of the monitor test suite")
+	parallel := flag.Int("parallel", runtime.NumCPU(), "number of mutants to test at once")
+	timeout := flag.Duration("timeout", time.Minute, "time limit for the tests of a single mutant")
+	format := flag.String("format", "text", "output format: text or json")
+	threshold := flag.Float64("threshold", 0, "minimum percentage of mutants that must be killed")
+	flag.Parse()
+
+	sources, err := monitors.Sources(flag.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading monitors: %v\n", err)
+		os.Exit(2)
+	}
+
+	var mutants []mutant
+	for _, source := range sources {
+		file, err := gate.ParseFile(source.Path, source.Src)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		for _, m := range mutate.Mutants(file, source.Src) {
+			mutants = append(mutants, mutant{Mutant: m, Monitor: source.Path})
+		}
+	}
+
+	r, err := newRunner(*testsDir, *timeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error preparing the test suite: %v\n", err)
+		os.Exit(2)
+	}
+	results, err := r.run(mutants, max(1, *parallel))
+	// close before exiting, as os.Exit skips deferred calls
+	r.close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error testing mutants: %v\n", err)
+		os.Exit(2)
+	}
+
+	report := newReport(results)
+	switch *format {
+	case "text":
+		err = writeText(os.Stdout, report)
+	case "json":
+		err = writeJSON(os.Stdout, report)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		os.Exit(2)
+	}
+
+	if report.Score < *threshold {
+		fmt.Fprintf(os.Stderr, "Mutation score %.1f%% is below the threshold of %.1f%%\n", report.Score, *threshold)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/gate/mutate"
+)
+
+// report summarizes the results per monitor. The score counts mutants of monitors without tests as not
+// killed, as nothing would notice them either.
+type report struct {
+	Monitors []monitorReport `json:"monitors"`
+	Killed   int             `json:"killed"`
+	Total    int             `json:"total"`
+	Score    float64         `json:"score"`
+}
+
+type monitorReport struct {
+	Monitor   string     `json:"monitor"`
+	Tests     []string   `json:"tests"`
+	Mutants   int        `json:"mutants"`
+	Killed    int        `json:"killed"`
+	TimedOut  int        `json:"timed_out"`
+	Survivors []survivor `json:"survivors"`
+}
+
+type survivor struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Operator string `json:"operator"`
+	Original string `json:"original"`
+	Mutated  string `json:"mutated"`
+}
+
+func newReport(results []result) *report {
+	r := &report{Monitors: []monitorReport{}}
+	index := make(map[string]int)
+	for _, res := range results {
+		name := filepath.Base(res.Monitor)
+		i, ok := index[name]
+		if !ok {
+			i = len(r.Monitors)
+			index[name] = i
+			r.Monitors = append(r.Monitors, monitorReport{Monitor: name, Tests: append([]string{}, res.Tests...), Survivors: []survivor{}})
+		}
+		m := &r.Monitors[i]
+		m.Mutants++
+		r.Total++
+
+		switch res.Status {
+		case KILLED, TIMEOUT:
+			m.Killed++
+			r.Killed++
+			if res.Status == TIMEOUT {
+				m.TimedOut++
+			}
+		case SURVIVED:
+			m.Survivors = append(m.Survivors, survivor{
+				File:     res.Pos.Filename,
+				Line:     res.Pos.Line,
+				Column:   res.Pos.Column,
+				Operator: res.Operator,
+				Original: res.Original,
+				Mutated:  res.Mutated,
+			})
+		}
+	}
+	if r.Total > 0 {
+		r.Score = 100 * float64(r.Killed) / float64(r.Total)
+	}
+	return r
+}
+
+// writeText writes the surviving mutants with their positions, followed by a summary per monitor.
+//
+//	monitors/eth_deficit.gate:38:29: survived: comparison: <= -> <
+//	eth_deficit.gate: 14 of 19 mutants killed by 4 tests
+//	fault_proof_detection_child.gate: 29 mutants not run, no tests
+//	mutation score: 207 of 300 mutants killed (69.0%)
+func writeText(w io.Writer, r *report) error {
+	var b strings.Builder
+	for _, m := range r.Monitors {
+		for _, s := range m.Survivors {
+			fmt.Fprintf(&b, "%s:%d:%d: survived: %s: %s -> %s\n", s.File, s.Line, s.Column, s.Operator, mutate.OneLine(s.Original), mutate.OneLine(s.Mutated))
+		}
+	}
+	for _, m := range r.Monitors {
+		if len(m.Tests) == 0 {
+			fmt.Fprintf(&b, "%s: %d mutants not run, no tests\n", m.Monitor, m.Mutants)
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %d of %d mutants killed by %s", m.Monitor, m.Killed, m.Mutants, plural(len(m.Tests), "test"))
+		if m.TimedOut > 0 {
+			fmt.Fprintf(&b, " (%d timed out)", m.TimedOut)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "mutation score: %d of %d mutants killed (%.1f%%)\n", r.Killed, r.Total, r.Score)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// writeJSON writes the report as a JSON object.
+func writeJSON(w io.Writer, r *report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
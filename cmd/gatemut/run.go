@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/base-org/fault-proof-monitors/coverage"
+	"github.com/base-org/fault-proof-monitors/gate/mutate"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+const (
+	KILLED   = "killed"
+	SURVIVED = "survived"
+	TIMEOUT  = "timeout"
+	NO_TESTS = "no-tests"
+)
+
+// mutant is a mutant of the monitor at path Monitor.
+type mutant struct {
+	mutate.Mutant
+	Monitor string
+}
+
+// result is the outcome of running the tests of a monitor against one of its mutants.
+type result struct {
+	mutant
+	Status string
+	Tests  []string
+}
+
+// runner runs the compiled monitor test suite against mutants, each in a directory of monitors of its own.
+type runner struct {
+	dir      string // temporary directory holding the test binary and the mutant directories
+	binary   string
+	testsDir string
+	timeout  time.Duration
+
+	// tests maps the file name of every monitor to the top level tests that validate it
+	tests map[string][]string
+}
+
+// newRunner compiles the test suite in testsDir and runs it once on the unmutated monitors, recording which
+// tests validate which monitor. It fails if the tests do not pass without mutations.
+func newRunner(testsDir string, timeout time.Duration) (*runner, error) {
+	dir, err := os.MkdirTemp("", "gatemut")
+	if err != nil {
+		return nil, err
+	}
+	r := &runner{dir: dir, binary: filepath.Join(dir, "tests.test"), timeout: timeout, tests: make(map[string][]string)}
+	if r.testsDir, err = filepath.Abs(testsDir); err != nil {
+		r.close()
+		return nil, err
+	}
+
+	build := exec.Command("go", "test", "-c", "-o", r.binary, ".")
+	build.Dir = r.testsDir
+	if out, err := build.CombinedOutput(); err != nil {
+		r.close()
+		return nil, fmt.Errorf("compiling tests: %v\n%s", err, out)
+	}
+
+	records := filepath.Join(dir, "coverage.json")
+	baseline := exec.Command(r.binary, "-test.count=1", "-offline", "-coverage", records)
+	baseline.Dir = r.testsDir
+	if out, err := baseline.CombinedOutput(); err != nil {
+		r.close()
+		return nil, fmt.Errorf("tests fail without mutations: %v\n%s", err, out)
+	}
+	recorded, err := coverage.ReadFile(records)
+	if err != nil {
+		r.close()
+		return nil, err
+	}
+	for _, record := range recorded {
+		// subtests such as TestCases/challenger_loses/challenger_wins are run through their top level test
+		test := strings.SplitN(record.Test, "/", 2)[0]
+		monitor := filepath.Base(record.Monitor)
+		if !contains(r.tests[monitor], test) {
+			r.tests[monitor] = append(r.tests[monitor], test)
+		}
+	}
+	for _, tests := range r.tests {
+		sort.Strings(tests)
+	}
+	return r, nil
+}
+
+func (r *runner) close() {
+	os.RemoveAll(r.dir)
+}
+
+// run tests every mutant with up to parallel test binaries at once and returns the results in the order of
+// the mutants. It stops at the first mutant that cannot be tested and returns its error.
+func (r *runner) run(mutants []mutant, parallel int) ([]result, error) {
+	results := make([]result, len(mutants))
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if failed() {
+					continue
+				}
+				res, err := r.test(i, mutants[i])
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					continue
+				}
+				results[i] = res
+			}
+		}()
+	}
+	for i := range mutants {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results, firstErr
+}
+
+// test runs the tests of the mutated monitor against a copy of the monitors directory holding the mutant.
+func (r *runner) test(i int, m mutant) (result, error) {
+	name := filepath.Base(m.Monitor)
+	res := result{mutant: m, Tests: r.tests[name]}
+	if len(res.Tests) == 0 {
+		res.Status = NO_TESTS
+		return res, nil
+	}
+
+	dir := filepath.Join(r.dir, fmt.Sprintf("mutant-%d", i))
+	defer os.RemoveAll(dir)
+	if err := writeMonitors(dir); err != nil {
+		return res, fmt.Errorf("writing monitors for %s: %w", m, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), m.Source, 0o644); err != nil {
+		return res, fmt.Errorf("writing mutant %s: %w", m, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	pattern := "^(" + strings.Join(quoteAll(res.Tests), "|") + ")$"
+	cmd := exec.CommandContext(ctx, r.binary, "-test.count=1", "-test.failfast", "-test.run", pattern, "-offline", "-monitors", dir)
+	cmd.Dir = r.testsDir
+	err := cmd.Run()
+
+	var exit *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		res.Status = TIMEOUT
+	case err == nil:
+		res.Status = SURVIVED
+	case errors.As(err, &exit):
+		res.Status = KILLED
+	default:
+		return res, fmt.Errorf("running tests for %s: %w", m, err)
+	}
+	return res, nil
+}
+
+// writeMonitors writes every embedded monitor to a new directory dst, for the mutant to replace one of them.
+func writeMonitors(dst string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	sources, err := monitors.Sources()
+	if err != nil {
+		return err
+	}
+	for _, source := range sources {
+		if err := os.WriteFile(filepath.Join(dst, filepath.Base(source.Path)), source.Src, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return quoted
+}
+
+func contains(list []string, s string) bool {
+	for _, existing := range list {
+		if existing == s {
+			return true
+		}
+	}
+	return false
+}
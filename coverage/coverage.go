@@ -0,0 +1,209 @@
+// Package coverage reports which invariants of the gate monitors the test suite exercises. The test harness
+// records the alerts fired by every validate request, and a report lists for each invariant the tests in which
+// it fired and did not fire, so that invariants only ever tested one way stand out.
+package coverage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// Record is the outcome of one validate request made by a test.
+type Record struct {
+	Test    string   `json:"test"`
+	Monitor string   `json:"monitor"`
+	Fired   []string `json:"fired"`
+
+	// Exceptions lists the exceptions raised while evaluating the monitor, in which case Fired says nothing
+	// about the invariants that did not fire
+	Exceptions []string `json:"exceptions,omitempty"`
+}
+
+// Recorder collects records from concurrent tests.
+type Recorder struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+// Add records the outcome of a validate request.
+func (r *Recorder) Add(record Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record)
+}
+
+// Records returns the records in the order they were added.
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Record(nil), r.records...)
+}
+
+// WriteFile writes the records to path as a JSON array.
+func (r *Recorder) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r.Records(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ReadFile reads records written by WriteFile.
+func ReadFile(path string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+type testKey struct{}
+
+// WithTest returns a context naming the test a validate request is made by. Requests made without one are
+// not recorded.
+func WithTest(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, testKey{}, name)
+}
+
+// TestFrom returns the test name set by WithTest, or "".
+func TestFrom(ctx context.Context) string {
+	name, _ := ctx.Value(testKey{}).(string)
+	return name
+}
+
+// Invariant is the coverage of a single invariant.
+type Invariant struct {
+	Description string   `json:"description"`
+	Fired       []string `json:"fired"`
+	NotFired    []string `json:"not_fired"`
+}
+
+// Covered returns how many of the two outcomes of the invariant, firing and not firing, are tested.
+func (i *Invariant) Covered() int {
+	covered := 0
+	if len(i.Fired) > 0 {
+		covered++
+	}
+	if len(i.NotFired) > 0 {
+		covered++
+	}
+	return covered
+}
+
+// Monitor is the coverage of the invariants of one monitor file.
+type Monitor struct {
+	Name       string      `json:"monitor"`
+	Tests      []string    `json:"tests"`
+	Invariants []Invariant `json:"invariants"`
+
+	// Unknown lists fired alerts that match no invariant description of the monitor
+	Unknown []string `json:"unknown,omitempty"`
+
+	// Errored lists the tests whose requests raised exceptions. They count towards neither outcome.
+	Errored []string `json:"errored,omitempty"`
+}
+
+// Tested reports whether any test validated the monitor.
+func (m *Monitor) Tested() bool {
+	return len(m.Tests) > 0
+}
+
+// Report is the invariant coverage of a set of monitors.
+type Report struct {
+	Monitors []Monitor `json:"monitors"`
+	Covered  int       `json:"covered"`
+	Total    int       `json:"total"`
+	Percent  float64   `json:"percent"`
+}
+
+// Untested returns the names of the monitors without any test.
+func (r *Report) Untested() []string {
+	var names []string
+	for _, m := range r.Monitors {
+		if !m.Tested() {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+// Build computes the coverage of the invariants in files from the records of a test run. Records are matched
+// to files by base name, e.g. challenger_loses.gate. Every invariant counts two outcomes towards the total,
+// including the invariants of monitors without tests. Records with exceptions are listed as errored and not
+// counted, as an exception can keep any invariant from firing.
+func Build(files []*gate.File, records []Record) *Report {
+	byMonitor := make(map[string][]Record)
+	for _, record := range records {
+		name := filepath.Base(record.Monitor)
+		byMonitor[name] = append(byMonitor[name], record)
+	}
+
+	report := &Report{Monitors: []Monitor{}}
+	for _, file := range files {
+		m := Monitor{Name: filepath.Base(file.Name), Tests: []string{}, Invariants: []Invariant{}}
+		known := make(map[string]bool)
+		for _, decl := range file.Invariants() {
+			m.Invariants = append(m.Invariants, Invariant{Description: decl.Description(), Fired: []string{}, NotFired: []string{}})
+			known[decl.Description()] = true
+		}
+
+		unknown := make(map[string]bool)
+		for _, record := range byMonitor[m.Name] {
+			m.Tests = appendUnique(m.Tests, record.Test)
+			if len(record.Exceptions) > 0 {
+				m.Errored = appendUnique(m.Errored, record.Test)
+				continue
+			}
+			fired := make(map[string]bool)
+			for _, description := range record.Fired {
+				fired[description] = true
+				if !known[description] {
+					unknown[description] = true
+				}
+			}
+			for i := range m.Invariants {
+				invariant := &m.Invariants[i]
+				if fired[invariant.Description] {
+					invariant.Fired = appendUnique(invariant.Fired, record.Test)
+				} else {
+					invariant.NotFired = appendUnique(invariant.NotFired, record.Test)
+				}
+			}
+		}
+		for description := range unknown {
+			m.Unknown = append(m.Unknown, description)
+		}
+		sort.Strings(m.Unknown)
+
+		for i := range m.Invariants {
+			report.Covered += m.Invariants[i].Covered()
+			report.Total += 2
+		}
+		report.Monitors = append(report.Monitors, m)
+	}
+	sort.Slice(report.Monitors, func(i, j int) bool { return report.Monitors[i].Name < report.Monitors[j].Name })
+
+	if report.Total > 0 {
+		report.Percent = 100 * float64(report.Covered) / float64(report.Total)
+	}
+	return report
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
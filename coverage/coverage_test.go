@@ -0,0 +1,107 @@
+package coverage
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+func parse(t *testing.T, name, src string) *gate.File {
+	file, err := gate.ParseFile(name, []byte(src))
+	if err != nil {
+		t.Fatalf("Error parsing %s: %v", name, err)
+	}
+	return file
+}
+
+func TestBuild(t *testing.T) {
+	// We expect every invariant to list the tests it fired and did not fire in, and untested monitors to be N/A
+	tested := parse(t, "monitors/tested.gate", `source a: integer = 1;
+invariant { description: "first", condition: a < 1 };
+invariant { description: "second", condition: a < 2 };
+`)
+	untested := parse(t, "monitors/untested.gate", `source a: integer = 1;
+invariant { description: "third", condition: a < 1 };
+`)
+
+	report := Build([]*gate.File{untested, tested}, []Record{
+		{Test: "TestFirst", Monitor: "tested.gate", Fired: []string{"first"}},
+		{Test: "TestNone", Monitor: "tested.gate", Fired: []string{}},
+		{Test: "TestTypo", Monitor: "tested.gate", Fired: []string{"frist"}},
+		{Test: "TestException", Monitor: "tested.gate", Fired: []string{}, Exceptions: []string{"a: division by zero"}},
+	})
+
+	if len(report.Monitors) != 2 || report.Monitors[0].Name != "tested.gate" {
+		t.Fatalf("Expected the monitors sorted by name, got %+v", report.Monitors)
+	}
+	m := report.Monitors[0]
+	if !reflect.DeepEqual(m.Invariants[0].Fired, []string{"TestFirst"}) || !reflect.DeepEqual(m.Invariants[0].NotFired, []string{"TestNone", "TestTypo"}) {
+		t.Errorf("Unexpected coverage of the first invariant: %+v", m.Invariants[0])
+	}
+	if len(m.Invariants[1].Fired) != 0 || len(m.Invariants[1].NotFired) != 3 {
+		t.Errorf("Unexpected coverage of the second invariant: %+v", m.Invariants[1])
+	}
+	if !reflect.DeepEqual(m.Unknown, []string{"frist"}) {
+		t.Errorf("Expected the alert without invariant to be unknown, got %v", m.Unknown)
+	}
+
+	// We expect a test that raised exceptions to be listed as errored rather than as not firing
+	if !reflect.DeepEqual(m.Errored, []string{"TestException"}) || len(m.Tests) != 4 {
+		t.Errorf("Expected TestException to be errored, got %+v", m)
+	}
+
+	// 2 outcomes of first, 1 of second and none of the untested third
+	if report.Covered != 3 || report.Total != 6 || report.Percent != 50 {
+		t.Errorf("Expected 3 of 6 outcomes covered, got %d of %d (%.1f%%)", report.Covered, report.Total, report.Percent)
+	}
+	if !reflect.DeepEqual(report.Untested(), []string{"untested.gate"}) {
+		t.Errorf("Expected untested.gate to be untested, got %v", report.Untested())
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	// We expect untested monitors to be reported as N/A
+	file := parse(t, "untested.gate", `source a: integer = 1;
+invariant { description: "third", condition: a < 1 };
+`)
+	var out bytes.Buffer
+	if err := WriteText(&out, Build([]*gate.File{file}, nil)); err != nil {
+		t.Fatalf("Error writing report: %v", err)
+	}
+
+	expected := `untested.gate: N/A, no tests
+  fires: -    no-fire: -    third
+coverage: 0 of 2 invariant outcomes (0.0%)
+monitors without tests: untested.gate
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected report:\n%s\nwant:\n%s", out.String(), expected)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	// We expect records to round trip through a file, and tests to be named through the context
+	var recorder Recorder
+	ctx := WithTest(context.Background(), "TestCases/challenger_loses/challenger_wins")
+	recorder.Add(Record{Test: TestFrom(ctx), Monitor: "challenger_loses.gate", Fired: []string{}})
+
+	path := filepath.Join(t.TempDir(), "coverage.json")
+	if err := recorder.WriteFile(path); err != nil {
+		t.Fatalf("Error writing records: %v", err)
+	}
+	records, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading records: %v", err)
+	}
+	if !reflect.DeepEqual(records, recorder.Records()) {
+		t.Errorf("Expected %v, got %v", recorder.Records(), records)
+	}
+	if TestFrom(context.Background()) != "" || !strings.HasPrefix(records[0].Test, "TestCases/") {
+		t.Errorf("Unexpected test names %v", records)
+	}
+}
@@ -0,0 +1,60 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText writes the report as one block per monitor, marking each invariant with whether it was seen
+// firing and not firing, followed by the total. Tests whose requests raised exceptions are listed after the
+// invariants.
+//
+//	challenger_loses.gate: 8 tests
+//	  fires: yes  no-fire: yes  Challenger lost one or more subgames
+//	fault_proof_detection_child.gate: N/A, no tests
+//	  fires: -    no-fire: -    Attacker is defending the output root
+func WriteText(w io.Writer, report *Report) error {
+	var b strings.Builder
+	for _, m := range report.Monitors {
+		if m.Tested() {
+			fmt.Fprintf(&b, "%s: %d tests\n", m.Name, len(m.Tests))
+		} else {
+			fmt.Fprintf(&b, "%s: N/A, no tests\n", m.Name)
+		}
+		for _, invariant := range m.Invariants {
+			fmt.Fprintf(&b, "  fires: %-4s no-fire: %-4s %s\n", outcome(m, invariant.Fired), outcome(m, invariant.NotFired), invariant.Description)
+		}
+		for _, description := range m.Unknown {
+			fmt.Fprintf(&b, "  unknown alert: %s\n", description)
+		}
+		if len(m.Errored) > 0 {
+			fmt.Fprintf(&b, "  not counted, exceptions in: %s\n", strings.Join(m.Errored, ", "))
+		}
+	}
+	fmt.Fprintf(&b, "coverage: %d of %d invariant outcomes (%.1f%%)\n", report.Covered, report.Total, report.Percent)
+	if untested := report.Untested(); len(untested) > 0 {
+		fmt.Fprintf(&b, "monitors without tests: %s\n", strings.Join(untested, ", "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func outcome(m Monitor, tests []string) string {
+	switch {
+	case !m.Tested():
+		return "-"
+	case len(tests) > 0:
+		return "yes"
+	default:
+		return "no"
+	}
+}
+
+// WriteJSON writes the report as a JSON object.
+func WriteJSON(w io.Writer, report *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,94 @@
+package deploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Alert is the body of a Hexagate webhook notification. Only the fields the workflows rely on are decoded:
+// alerts of Contract Event monitors carry the decoded event, such as the disputeProxy argument of a
+// DisputeGameCreated event, and alerts of invariant monitors the values of the sources of the invariant.
+type Alert struct {
+	MonitorId   int            `json:"monitor_id"`
+	MonitorName string         `json:"monitor_name"`
+	ChainId     int            `json:"chain_id"`
+	BlockNumber uint64         `json:"block_number"`
+	TxHash      string         `json:"tx_hash"`
+	Description string         `json:"description"`
+	Event       *AlertEvent    `json:"event"`
+	Values      map[string]any `json:"values"`
+}
+
+// AlertEvent is the event that triggered a Contract Event monitor.
+type AlertEvent struct {
+	Name    string         `json:"name"`
+	Address string         `json:"address"`
+	Args    map[string]any `json:"args"`
+}
+
+// ParseAlert decodes a webhook notification. Numbers are decoded as json.Number so that they keep their
+// precision.
+func ParseAlert(data []byte) (*Alert, error) {
+	var alert Alert
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&alert); err != nil {
+		return nil, fmt.Errorf("invalid alert: %v", err)
+	}
+	return &alert, nil
+}
+
+// Value returns the event argument named key for alerts of Contract Event monitors, or the source value
+// named key for alerts of invariant monitors.
+func (a *Alert) Value(key string) (any, bool) {
+	if a.Event != nil {
+		value, ok := a.Event.Args[key]
+		return value, ok
+	}
+	value, ok := a.Values[key]
+	return value, ok
+}
+
+// Address returns the address stored under key in the alert in its EIP-55 form.
+func (a *Alert) Address(key string) (string, error) {
+	value, ok := a.Value(key)
+	if !ok {
+		return "", fmt.Errorf("alert has no %s", key)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("alert %s %v is not an address", key, value)
+	}
+	return Address(s)
+}
+
+// Game returns the dispute game of the alert: the game address stored under field, the gameType and rootClaim
+// of its DisputeGameCreated event where the alert has them, and the block of the alert. The parent monitor
+// reports the root claim as l2OutputProposal.
+func (a *Alert) Game(field string) (Game, error) {
+	address, err := a.Address(field)
+	if err != nil {
+		return Game{}, err
+	}
+	game := Game{Address: address, CreatedBlock: a.BlockNumber}
+	if value, ok := a.Value("gameType"); ok {
+		n, ok := value.(json.Number)
+		if !ok {
+			return Game{}, fmt.Errorf("alert gameType %v is not an integer", value)
+		}
+		typ, err := strconv.Atoi(n.String())
+		if err != nil {
+			return Game{}, fmt.Errorf("alert gameType %v is not an integer", value)
+		}
+		game.Type = typ
+	}
+	for _, key := range []string{"rootClaim", "l2OutputProposal"} {
+		if value, ok := a.Value(key); ok {
+			game.RootClaim = fmt.Sprint(value)
+			break
+		}
+	}
+	return game, nil
+}
@@ -0,0 +1,42 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+const (
+	// CHILD_MONITOR is the Specific DisputeGame monitor deployed to every game the parent monitor,
+	// fault_proof_detection_parent, detects an incorrect L2 output proposal for.
+	CHILD_MONITOR = "fault_proof_detection_child"
+
+	// PARENT_ALERT is the description of the parent invariant that triggers the deployment of a child.
+	PARENT_ALERT = "Dispute game created with incorrect L2 output proposal"
+)
+
+// DeployChild deploys the child monitor to game, unless it is already deployed there. The cbChallenger param
+// comes from the config.
+func (d *Deployer) DeployChild(ctx context.Context, game Game) (*Deployment, error) {
+	child, err := monitors.Get(CHILD_MONITOR)
+	if err != nil {
+		return nil, err
+	}
+	return d.deploy(ctx, game, []*monitors.Monitor{child})
+}
+
+// ParentAlertHandler returns a handler for the webhook of the parent monitor. Every alert on an incorrect L2
+// output proposal deploys the child monitor to the disputeProxy of the game, once. Alerts of the other
+// invariants of the parent are ignored.
+func (d *Deployer) ParentAlertHandler(opts WebhookOptions) http.Handler {
+	return webhook(opts, d.chainId(), GAME_CREATED_FIELD, ignoreParentAlert, d.DeployChild)
+}
+
+func ignoreParentAlert(alert *Alert) string {
+	if alert.Description != PARENT_ALERT {
+		return fmt.Sprintf("alert %q does not deploy %s", alert.Description, CHILD_MONITOR)
+	}
+	return ""
+}
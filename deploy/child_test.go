@@ -0,0 +1,107 @@
+package deploy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/hexagate"
+	"github.com/base-org/fault-proof-monitors/hexagate/hexagatetest"
+)
+
+const PARENT_ALERT_FILE = "testdata/parent_alert.json"
+
+func newParentService(t *testing.T) (*hexagatetest.Server, *httptest.Server) {
+	t.Helper()
+	fake := hexagatetest.NewServer(nil)
+	t.Cleanup(fake.Close)
+	deployer := NewDeployer(fake.NewClient(), Config{ChainId: hexagate.CHAIN_ID_MAINNET, Params: PARAMS})
+	service := httptest.NewServer(deployer.ParentAlertHandler(WebhookOptions{Logger: quiet}))
+	t.Cleanup(service.Close)
+	return fake, service
+}
+
+func readParentAlert(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(PARENT_ALERT_FILE)
+	if err != nil {
+		t.Fatalf("Error reading file %s: %v", PARENT_ALERT_FILE, err)
+	}
+	return data
+}
+
+func TestParentAlertDeploysChild(t *testing.T) {
+	fake, service := newParentService(t)
+
+	// We expect a parent alert to deploy the child monitor to the game with disputeGame and cbChallenger
+	status, response := post(t, service.URL, "", readParentAlert(t))
+	if status != http.StatusCreated || response.Created != 1 || len(response.Monitors) != 1 {
+		t.Fatalf("Expected the child to be created, got %d: %+v", status, response)
+	}
+	deployed := fake.Monitors()
+	if len(deployed) != 1 {
+		t.Fatalf("Expected 1 monitor on the fake, got %d", len(deployed))
+	}
+	child := deployed[0]
+	if child.Name != CHILD_MONITOR+" "+GAME {
+		t.Errorf("Expected the child to be named after the game, got %s", child.Name)
+	}
+	if len(child.Params) != 2 || child.Params[GAME_PARAM] != GAME || child.Params["cbChallenger"] != HONEST {
+		t.Errorf("Expected disputeGame %s and cbChallenger %s, got %v", GAME, HONEST, child.Params)
+	}
+
+	// We expect repeated parent alerts to answer with the same child
+	status, response = post(t, service.URL, "", readParentAlert(t))
+	if status != http.StatusOK || response.Created != 0 || len(response.Monitors) != 1 || response.Monitors[0].Id != child.Id {
+		t.Errorf("Expected the deployed child %d with status 200, got %d: %+v", child.Id, status, response)
+	}
+	if len(fake.Monitors()) != 1 {
+		t.Errorf("Expected 1 monitor on the fake, got %d", len(fake.Monitors()))
+	}
+}
+
+func TestParentAlertConcurrent(t *testing.T) {
+	fake, service := newParentService(t)
+	payload := readParentAlert(t)
+
+	// We expect alerts delivered at the same time to deploy a single child
+	var wg sync.WaitGroup
+	statuses := make([]int, 5)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i], _ = post(t, service.URL, "", payload)
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, status := range statuses {
+		if status == http.StatusCreated {
+			created++
+		} else if status != http.StatusOK {
+			t.Errorf("Unexpected status %d", status)
+		}
+	}
+	if created != 1 || len(fake.Monitors()) != 1 {
+		t.Errorf("Expected 1 child to be created, got %d responses and %d monitors", created, len(fake.Monitors()))
+	}
+}
+
+func TestParentAlertIgnored(t *testing.T) {
+	fake, service := newParentService(t)
+
+	// We expect alerts of the other parent invariant to be acknowledged without deploying a child
+	payload := strings.Replace(string(readParentAlert(t)), PARENT_ALERT, "Only one DisputeGameCreated event should appear in the same block", 1)
+	status, response := post(t, service.URL, "", []byte(payload))
+	if status != http.StatusOK || response.Ignored == "" || response.Deployment != nil {
+		t.Errorf("Expected the alert to be ignored, got %d: %+v", status, response)
+	}
+	if len(fake.Monitors()) != 0 {
+		t.Errorf("Expected no monitors to be deployed, got %d", len(fake.Monitors()))
+	}
+}
@@ -0,0 +1,345 @@
+// Package deploy automates the Per DisputeGame and Specific DisputeGame deployment workflows: it turns the
+// alerts of the monitors that watch for new dispute games into monitors deployed to those games through the
+// Hexagate monitor management API.
+package deploy
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/base-org/fault-proof-monitors/deploy/state"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+	"github.com/base-org/fault-proof-monitors/hexagate/preflight"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+const (
+	// GAME_PARAM is the param every monitor deployed to a dispute game takes the address of the game in.
+	GAME_PARAM = "disputeGame"
+
+	// CHECK_GAME is the placeholder game Check builds the requests of the monitors for.
+	CHECK_GAME = "0x0000000000000000000000000000000000000000"
+)
+
+// Config holds what the monitors deployed to every game have in common.
+type Config struct {
+	// ChainId is the chain the monitors run on, or 0 for the chain ID of the client.
+	ChainId int
+
+	// NotificationChannelIds are the channels the alerts of the deployed monitors are sent to.
+	NotificationChannelIds []int
+
+	// Params holds the values of the params that are the same for every game, e.g. honestChallenger or
+	// multicall3. Every monitor is deployed with the values of the params it declares.
+	Params map[string]any
+
+	// Store records the monitors deployed to every game, or nil to find them by name in Hexagate only.
+	Store state.Store
+
+	// Now returns the current time, or nil for time.Now.
+	Now func() time.Time
+}
+
+// ParamsError is returned when the params of a monitor do not match its declarations, e.g. because the config
+// misses a param a monitor declares. Deploying to any game fails the same way until the config is fixed.
+type ParamsError struct {
+	Monitor string
+	Err     error
+}
+
+func (e *ParamsError) Error() string {
+	return fmt.Sprintf("invalid params for %s: %v", e.Monitor, e.Err)
+}
+
+func (e *ParamsError) Unwrap() error {
+	return e.Err
+}
+
+// Game is a dispute game monitors are deployed to, as described by its DisputeGameCreated event.
+type Game struct {
+	Address      string
+	Type         int
+	RootClaim    string
+	CreatedBlock uint64
+}
+
+// Deployment is the outcome of deploying monitors to a dispute game: every monitor of the game, whether it was
+// created now or found already deployed.
+type Deployment struct {
+	Game     string              `json:"game"`
+	Monitors []*hexagate.Monitor `json:"monitors"`
+	// Created is the number of monitors that were created rather than already deployed.
+	Created int `json:"created"`
+}
+
+// Deployer deploys monitors to dispute games. Deploying is idempotent: a monitor already deployed to a game
+// is kept rather than created again, so alerts that are delivered twice or retried after a failure do not
+// create duplicates. Deployed monitors are found in the state store of the config, and otherwise by the name
+// MonitorName gives them. It is safe for concurrent use.
+type Deployer struct {
+	client *hexagate.Client
+	config Config
+
+	// mu serializes deployments, so that concurrent alerts for the same game do not both create its monitors
+	mu sync.Mutex
+}
+
+// NewDeployer creates a deployer that creates monitors with client.
+func NewDeployer(client *hexagate.Client, config Config) *Deployer {
+	return &Deployer{client: client, config: config}
+}
+
+// chainId returns the chain the monitors are deployed on.
+func (d *Deployer) chainId() int {
+	if d.config.ChainId != 0 {
+		return d.config.ChainId
+	}
+	return d.client.ChainID()
+}
+
+// DeployGame deploys every Per DisputeGame monitor to game, in the order of the registry. The requests of all
+// monitors are checked before any is created, so a missing or invalid param does not leave the game partially
+// monitored. If creating a monitor fails, the deployment so far is returned with the error.
+func (d *Deployer) DeployGame(ctx context.Context, game Game) (*Deployment, error) {
+	perGame, err := monitors.ByWorkflow(monitors.PER_DISPUTE_GAME)
+	if err != nil {
+		return nil, err
+	}
+	return d.deploy(ctx, game, perGame)
+}
+
+// Check builds the request of every monitor the deployer deploys, the Per DisputeGame monitors and the child
+// monitor, for a placeholder game, and returns the errors of those whose params do not match their
+// declarations. The params only differ between games in the game address, so a deployer that passes the
+// check at startup does not fail on invalid params later.
+func (d *Deployer) Check() error {
+	ms, err := monitors.ByWorkflow(monitors.PER_DISPUTE_GAME)
+	if err != nil {
+		return err
+	}
+	child, err := monitors.Get(CHILD_MONITOR)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, m := range append(ms, child) {
+		if _, err := d.request(m, CHECK_GAME, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deploy deploys the monitors ms to game, skipping those already deployed, and records every monitor of the
+// game in the store.
+func (d *Deployer) deploy(ctx context.Context, game Game, ms []*monitors.Monitor) (*Deployment, error) {
+	address, err := Address(game.Address)
+	if err != nil {
+		return nil, err
+	}
+	game.Address = address
+	deployment := &Deployment{Game: address, Monitors: []*hexagate.Monitor{}}
+
+	var requests []hexagate.MonitorRequest
+	for _, m := range ms {
+		request, err := d.request(m, address, nil)
+		if err != nil {
+			return deployment, err
+		}
+		requests = append(requests, request)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var deployed map[string]*hexagate.Monitor
+	for i, request := range requests {
+		monitor, err := d.recorded(ctx, address, ms[i].Name)
+		if err != nil {
+			return deployment, err
+		}
+		if monitor == nil {
+			// list the deployed monitors once, for monitors created without being recorded
+			if deployed == nil {
+				if deployed, err = d.deployed(ctx); err != nil {
+					return deployment, err
+				}
+			}
+			monitor = deployed[request.Name]
+		}
+		if monitor == nil {
+			monitor, err = d.client.CreateMonitor(ctx, request)
+			if errors.Is(err, hexagate.ErrConflict) {
+				// deployed by someone else since the monitors were listed
+				monitor, err = d.find(ctx, request.Name)
+			} else if err == nil {
+				deployment.Created++
+			}
+			if err != nil {
+				return deployment, fmt.Errorf("creating %s: %w", request.Name, err)
+			}
+		}
+		deployment.Monitors = append(deployment.Monitors, monitor)
+		if err := d.record(ctx, game, ms[i], monitor); err != nil {
+			return deployment, err
+		}
+	}
+	return deployment, nil
+}
+
+// recorded returns the monitor the store records for monitor on game, or nil if there is none or it no
+// longer exists in Hexagate.
+func (d *Deployer) recorded(ctx context.Context, game, monitor string) (*hexagate.Monitor, error) {
+	if d.config.Store == nil {
+		return nil, nil
+	}
+	record, err := d.config.Store.Get(ctx, game, monitor)
+	if errors.Is(err, state.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	deployed, err := d.client.GetMonitor(ctx, record.MonitorId)
+	if errors.Is(err, hexagate.ErrNotFound) {
+		// deleted outside of the deployer
+		return nil, d.config.Store.Delete(ctx, game, monitor)
+	}
+	return deployed, err
+}
+
+// record stores the deployment of m to game, unless the store already records this monitor.
+func (d *Deployer) record(ctx context.Context, game Game, m *monitors.Monitor, monitor *hexagate.Monitor) error {
+	if d.config.Store == nil {
+		return nil
+	}
+	existing, err := d.config.Store.Get(ctx, game.Address, m.Name)
+	if err == nil && existing.MonitorId == monitor.Id {
+		return nil
+	}
+	if err != nil && !errors.Is(err, state.ErrNotFound) {
+		return err
+	}
+	return d.config.Store.Put(ctx, state.Record{
+		Game:         game.Address,
+		GameType:     game.Type,
+		RootClaim:    game.RootClaim,
+		CreatedBlock: game.CreatedBlock,
+		Monitor:      m.Name,
+		GateHash:     monitors.Hash(monitor.Gate),
+		Params:       monitor.Params,
+		MonitorId:    monitor.Id,
+		DeployedAt:   d.now(),
+	})
+}
+
+func (d *Deployer) now() time.Time {
+	if d.config.Now != nil {
+		return d.config.Now()
+	}
+	return time.Now().UTC()
+}
+
+// deployed returns every deployed monitor by name.
+func (d *Deployer) deployed(ctx context.Context) (map[string]*hexagate.Monitor, error) {
+	all, err := d.client.AllMonitors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*hexagate.Monitor, len(all))
+	for i := range all {
+		byName[all[i].Name] = &all[i]
+	}
+	return byName, nil
+}
+
+// find returns the deployed monitor with the given name.
+func (d *Deployer) find(ctx context.Context, name string) (*hexagate.Monitor, error) {
+	deployed, err := d.deployed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if monitor, ok := deployed[name]; ok {
+		return monitor, nil
+	}
+	return nil, fmt.Errorf("monitor %s conflicts with a deployed monitor but is not deployed", name)
+}
+
+// request builds the request deploying m to game. The params m declares are taken from params, then from the
+// config, and the request is rejected if they do not match the declarations.
+func (d *Deployer) request(m *monitors.Monitor, game string, params map[string]any) (hexagate.MonitorRequest, error) {
+	values := make(map[string]any, len(m.Params))
+	for _, p := range m.Params {
+		if value, ok := params[p.Name]; ok {
+			values[p.Name] = value
+		} else if value, ok := d.config.Params[p.Name]; ok {
+			values[p.Name] = value
+		}
+	}
+	values[GAME_PARAM] = game
+
+	file, err := m.Parse()
+	if err != nil {
+		return hexagate.MonitorRequest{}, err
+	}
+	if err := preflight.Check(m.File, file, hexagate.ValidateRequest{Params: values}).Err(); err != nil {
+		return hexagate.MonitorRequest{}, &ParamsError{Monitor: m.Name, Err: err}
+	}
+	return hexagate.MonitorRequest{
+		Name:                   MonitorName(m, game),
+		Description:            fmt.Sprintf("%s deployed to dispute game %s", m.Name, game),
+		Gate:                   m.Source,
+		ChainId:                d.config.ChainId,
+		Params:                 values,
+		NotificationChannelIds: d.config.NotificationChannelIds,
+	}, nil
+}
+
+// MonitorName returns the name of m deployed to game, e.g. eth_deficit 0x3f2F...
+func MonitorName(m *monitors.Monitor, game string) string {
+	return m.Name + " " + game
+}
+
+// ParamValue converts the text of a param to the value sent to Hexagate. Params any monitor declares as
+// integer are sent as numbers, everything else as strings.
+func ParamValue(name, text string) (any, error) {
+	registered, err := monitors.All()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range registered {
+		if param, ok := m.Param(name); ok && param.Type == "integer" {
+			if _, ok := new(big.Int).SetString(text, 10); !ok {
+				return nil, fmt.Errorf("param %s is declared as integer by %s, got %s", name, m.Name, text)
+			}
+			return json.Number(text), nil
+		}
+	}
+	return text, nil
+}
+
+// Address returns the EIP-55 form of a 0x-prefixed 20-byte address. A 32-byte word holding an address, such
+// as an indexed event topic, is accepted as well.
+func Address(s string) (string, error) {
+	digits, ok := strings.CutPrefix(strings.TrimSpace(s), "0x")
+	if !ok {
+		return "", fmt.Errorf("address %s must start with 0x", s)
+	}
+	if len(digits) == 64 && strings.Trim(digits[:24], "0") == "" {
+		digits = digits[24:]
+	}
+	if len(digits) != 40 {
+		return "", fmt.Errorf("address %s must be 20 bytes (40 hex digits), got %d hex digits", s, len(digits))
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return "", fmt.Errorf("address %s is not valid hex", s)
+	}
+	return preflight.Checksum("0x" + digits), nil
+}
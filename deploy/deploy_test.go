@@ -0,0 +1,386 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/base-org/fault-proof-monitors/deploy/state"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+	"github.com/base-org/fault-proof-monitors/hexagate/hexagatetest"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+const (
+	GAME             = "0x8D8f2bD3e2B4BC0ae1D4C6B1C2c1E6A5c3f7D9e1"
+	HONEST           = "0x49277EE36A024120Ee218127354c4a3591dc90A9"
+	MULTICALL3       = "0xcA11bde05977b3631167028862bE2a173976CA11"
+	SECRET           = "hunter2"
+	GAME_CREATED     = "testdata/game_created.json"
+	CHANNEL          = 12
+	PER_GAME_MONITOR = 7
+)
+
+var PARAMS = map[string]any{
+	"honestChallenger":   HONEST,
+	"honestProposer":     HONEST,
+	"multicall3":         MULTICALL3,
+	"extraTimeInSeconds": 3600,
+	"cbChallenger":       HONEST,
+}
+
+var quiet = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newService starts a fake Hexagate server and a webhook server in front of a deployer using it.
+func newService(t *testing.T, params map[string]any) (*hexagatetest.Server, *httptest.Server) {
+	t.Helper()
+	fake := hexagatetest.NewServer(nil)
+	t.Cleanup(fake.Close)
+	deployer := NewDeployer(fake.NewClient(), Config{ChainId: hexagate.CHAIN_ID_MAINNET, NotificationChannelIds: []int{CHANNEL}, Params: params})
+	service := httptest.NewServer(deployer.GameCreatedHandler(WebhookOptions{Secret: SECRET, Logger: quiet}))
+	t.Cleanup(service.Close)
+	return fake, service
+}
+
+func post(t *testing.T, url, secret string, body []byte) (int, Response) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Error creating request: %v", err)
+	}
+	req.Header.Set(SECRET_HEADER, secret)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Error calling webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return resp.StatusCode, response
+}
+
+func readPayload(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(GAME_CREATED)
+	if err != nil {
+		t.Fatalf("Error reading file %s: %v", GAME_CREATED, err)
+	}
+	return data
+}
+
+func perGame(t *testing.T) []*monitors.Monitor {
+	t.Helper()
+	perGame, err := monitors.ByWorkflow(monitors.PER_DISPUTE_GAME)
+	if err != nil {
+		t.Fatalf("Error loading monitors: %v", err)
+	}
+	return perGame
+}
+
+func TestGameCreatedWebhook(t *testing.T) {
+	fake, service := newService(t, PARAMS)
+
+	// We expect a DisputeGameCreated alert to deploy the seven Per DisputeGame monitors to its disputeProxy
+	status, response := post(t, service.URL, SECRET, readPayload(t))
+	if status != http.StatusCreated || response.Error != "" {
+		t.Fatalf("Expected the monitors to be created, got %d: %s", status, response.Error)
+	}
+	if response.Game != GAME || len(response.Monitors) != PER_GAME_MONITOR || response.Created != PER_GAME_MONITOR {
+		t.Errorf("Expected %d monitors created for %s, got %+v", PER_GAME_MONITOR, GAME, response.Deployment)
+	}
+
+	deployed := fake.Monitors()
+	if len(deployed) != PER_GAME_MONITOR {
+		t.Fatalf("Expected %d monitors on the fake, got %d", PER_GAME_MONITOR, len(deployed))
+	}
+	for i, m := range perGame(t) {
+		got := deployed[i]
+		if got.Name != MonitorName(m, GAME) || got.Gate != m.Source || got.ChainId != hexagate.CHAIN_ID_MAINNET {
+			t.Errorf("Expected %s deployed on chain %d, got %s on chain %d", MonitorName(m, GAME), hexagate.CHAIN_ID_MAINNET, got.Name, got.ChainId)
+		}
+		if len(got.NotificationChannelIds) != 1 || got.NotificationChannelIds[0] != CHANNEL {
+			t.Errorf("Expected %s to notify channel %d, got %v", got.Name, CHANNEL, got.NotificationChannelIds)
+		}
+
+		// We expect exactly the declared params, with the game in disputeGame
+		if len(got.Params) != len(m.Params) || got.Params[GAME_PARAM] != GAME {
+			t.Errorf("Expected %s to be deployed with its %d params and disputeGame %s, got %v", got.Name, len(m.Params), GAME, got.Params)
+		}
+		for _, p := range m.Params {
+			if _, ok := got.Params[p.Name]; !ok {
+				t.Errorf("Expected %s to be deployed with param %s", got.Name, p.Name)
+			}
+		}
+	}
+}
+
+func TestGameCreatedWebhookRepeated(t *testing.T) {
+	fake, service := newService(t, PARAMS)
+	payload := readPayload(t)
+	_, first := post(t, service.URL, SECRET, payload)
+
+	// We expect a repeated alert to answer with the monitors already deployed without creating new ones
+	status, response := post(t, service.URL, SECRET, payload)
+	if status != http.StatusOK || response.Created != 0 || len(response.Monitors) != PER_GAME_MONITOR {
+		t.Fatalf("Expected the %d deployed monitors with status 200, got %d: %+v", PER_GAME_MONITOR, status, response)
+	}
+	for i, m := range response.Monitors {
+		if m.Id != first.Monitors[i].Id {
+			t.Errorf("Expected monitor %d to be %d, got %d", i, first.Monitors[i].Id, m.Id)
+		}
+	}
+	if len(fake.Monitors()) != PER_GAME_MONITOR {
+		t.Errorf("Expected %d monitors on the fake, got %d", PER_GAME_MONITOR, len(fake.Monitors()))
+	}
+}
+
+func TestGameCreatedWebhookRejects(t *testing.T) {
+	fake, service := newService(t, PARAMS)
+	payload := readPayload(t)
+
+	// We expect requests that cannot be deployed from to be rejected without deploying anything
+	for _, tc := range []struct {
+		name   string
+		secret string
+		body   string
+		status int
+	}{
+		{"wrong secret", "hunter3", string(payload), http.StatusUnauthorized},
+		{"invalid json", SECRET, `{"event":`, http.StatusBadRequest},
+		{"missing disputeProxy", SECRET, `{"chain_id": 1, "event": {"name": "DisputeGameCreated", "args": {"gameType": 0}}}`, http.StatusUnprocessableEntity},
+		{"invalid disputeProxy", SECRET, `{"chain_id": 1, "event": {"name": "DisputeGameCreated", "args": {"disputeProxy": "0x8d8f"}}}`, http.StatusUnprocessableEntity},
+		{"disputeProxy outside the event", SECRET, `{"chain_id": 1, "event": {"name": "DisputeGameCreated", "args": {}}, "values": {"disputeProxy": "` + GAME + `"}}`, http.StatusUnprocessableEntity},
+		{"other chain", SECRET, strings.Replace(string(payload), `"chain_id": 1`, `"chain_id": 8453`, 1), http.StatusUnprocessableEntity},
+		{"missing chain", SECRET, strings.Replace(string(payload), `"chain_id": 1,`, "", 1), http.StatusUnprocessableEntity},
+	} {
+		status, response := post(t, service.URL, tc.secret, []byte(tc.body))
+		if status != tc.status || response.Error == "" {
+			t.Errorf("%s: expected status %d with an error, got %d: %q", tc.name, tc.status, status, response.Error)
+		}
+	}
+	if len(fake.Monitors()) != 0 {
+		t.Errorf("Expected no monitors to be deployed, got %d", len(fake.Monitors()))
+	}
+}
+
+func TestGameCreatedWebhookIgnored(t *testing.T) {
+	fake, service := newService(t, PARAMS)
+	payload := readPayload(t)
+
+	// We expect alerts of other factory events, or without an event, to be acknowledged without deploying
+	for _, body := range []string{
+		strings.Replace(string(payload), `"name": "DisputeGameCreated"`, `"name": "ImplementationSet"`, 1),
+		`{"chain_id": 1, "description": "invariant alert", "values": {"disputeProxy": "` + GAME + `"}}`,
+	} {
+		status, response := post(t, service.URL, SECRET, []byte(body))
+		if status != http.StatusOK || response.Ignored == "" || response.Deployment != nil {
+			t.Errorf("Expected the alert to be ignored, got %d: %+v", status, response)
+		}
+	}
+	if len(fake.Monitors()) != 0 {
+		t.Errorf("Expected no monitors to be deployed, got %d", len(fake.Monitors()))
+	}
+}
+
+func TestGameCreatedWebhookMissingParam(t *testing.T) {
+	params := make(map[string]any)
+	for name, value := range PARAMS {
+		if name != "multicall3" {
+			params[name] = value
+		}
+	}
+	fake, service := newService(t, params)
+
+	// We expect a missing param to fail the alert before any monitor is created
+	status, response := post(t, service.URL, SECRET, readPayload(t))
+	if status != http.StatusInternalServerError || !strings.Contains(response.Error, "param multicall3 is declared as address but not set") {
+		t.Errorf("Expected a params error for multicall3, got %d: %s", status, response.Error)
+	}
+	if len(fake.Monitors()) != 0 {
+		t.Errorf("Expected no monitors to be deployed, got %d", len(fake.Monitors()))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	fake := hexagatetest.NewServer(nil)
+	defer fake.Close()
+
+	// We expect the check to pass with every param set, without creating any monitor
+	if err := NewDeployer(fake.NewClient(), Config{Params: PARAMS}).Check(); err != nil {
+		t.Errorf("Expected the params to pass the check, got %v", err)
+	}
+	if len(fake.Monitors()) != 0 {
+		t.Errorf("Expected no monitors to be deployed, got %d", len(fake.Monitors()))
+	}
+
+	// We expect a missing param to be reported for every monitor declaring it, including the child
+	params := make(map[string]any)
+	for name, value := range PARAMS {
+		if name != "cbChallenger" {
+			params[name] = value
+		}
+	}
+	err := NewDeployer(fake.NewClient(), Config{Params: params}).Check()
+	var paramsErr *ParamsError
+	if !errors.As(err, &paramsErr) || paramsErr.Monitor != CHILD_MONITOR || !strings.Contains(err.Error(), "param cbChallenger is declared as address but not set") {
+		t.Errorf("Expected a params error for %s, got %v", CHILD_MONITOR, err)
+	}
+}
+
+func TestDeployGameFailure(t *testing.T) {
+	// We expect a failure of the management API to return the monitors created before it
+	fake := hexagatetest.NewServer(nil)
+	defer fake.Close()
+	creates := 0
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if creates++; creates == 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		proxy, _ := http.NewRequest(r.Method, fake.URL+r.URL.String(), r.Body)
+		resp, err := http.DefaultClient.Do(proxy)
+		if err != nil {
+			t.Errorf("Error proxying request: %v", err)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}))
+	defer failing.Close()
+
+	client := hexagate.NewClient(hexagate.WithBaseURL(failing.URL), hexagate.WithAPIKey(hexagate.StaticKey("")))
+	deployer := NewDeployer(client, Config{Params: PARAMS})
+	deployment, err := deployer.DeployGame(context.Background(), Game{Address: GAME})
+	var apiErr *hexagate.APIError
+	if !errors.As(err, &apiErr) || !apiErr.Temporary() {
+		t.Errorf("Expected a temporary *APIError, got %v", err)
+	}
+	if deployment.Created != 2 || len(fake.Monitors()) != 2 {
+		t.Errorf("Expected the 2 monitors created before the failure, got %d", deployment.Created)
+	}
+
+	// We expect a retry to create only the monitors that are missing
+	deployment, err = deployer.DeployGame(context.Background(), Game{Address: GAME})
+	if err != nil {
+		t.Fatalf("Error retrying deployment: %v", err)
+	}
+	if deployment.Created != PER_GAME_MONITOR-2 || len(deployment.Monitors) != PER_GAME_MONITOR || len(fake.Monitors()) != PER_GAME_MONITOR {
+		t.Errorf("Expected the retry to create %d monitors, got %d of %d", PER_GAME_MONITOR-2, deployment.Created, len(fake.Monitors()))
+	}
+}
+
+func TestAddress(t *testing.T) {
+	// We expect addresses and address topics to be returned checksummed, and anything else to be rejected
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{strings.ToLower(GAME), GAME},
+		{"0x0000000000000000000000008d8f2bd3e2b4bc0ae1d4c6b1c2c1e6a5c3f7d9e1", GAME},
+		{"8d8f2bd3e2b4bc0ae1d4c6b1c2c1e6a5c3f7d9e1", ""},
+		{"0x8d8f2bd3e2b4bc0ae1d4c6b1c2c1e6a5c3f7d9", ""},
+		{"0x1000000000000000000000008d8f2bd3e2b4bc0ae1d4c6b1c2c1e6a5c3f7d9e1", ""},
+		{"0x8d8f2bd3e2b4bc0ae1d4c6b1c2c1e6a5c3f7d9zz", ""},
+	} {
+		got, err := Address(tc.in)
+		if got != tc.want || (err == nil) != (tc.want != "") {
+			t.Errorf("Address(%s): expected %q, got %q, %v", tc.in, tc.want, got, err)
+		}
+	}
+}
+
+func TestAlertValue(t *testing.T) {
+	// We expect values to be read from the event arguments of Contract Event alerts
+	alert, err := ParseAlert(readPayload(t))
+	if err != nil {
+		t.Fatalf("Error parsing alert: %v", err)
+	}
+	if value, ok := alert.Value("gameType"); !ok || value != json.Number("0") {
+		t.Errorf("Expected gameType 0, got %v", value)
+	}
+	if alert.Event.Name != "DisputeGameCreated" || alert.ChainId != hexagate.CHAIN_ID_MAINNET {
+		t.Errorf("Expected a DisputeGameCreated event on Base, got %+v", alert)
+	}
+
+	// We expect values to be read from the invariant values of invariant alerts, and only under their own name
+	alert, err = ParseAlert([]byte(`{"monitor_name": "parent", "values": {"l2BlockNumber": 7, "entities": [{"disputeProxy": "0x01"}]}}`))
+	if err != nil {
+		t.Fatalf("Error parsing alert: %v", err)
+	}
+	if value, ok := alert.Value("l2BlockNumber"); !ok || value != json.Number("7") {
+		t.Errorf("Expected l2BlockNumber 7, got %v", value)
+	}
+	for _, key := range []string{"disputeProxy", "l2_block_number", "monitor_name"} {
+		if value, ok := alert.Value(key); ok {
+			t.Errorf("Expected no %s, got %v", key, value)
+		}
+	}
+}
+
+func TestDeployRecordsState(t *testing.T) {
+	fake := hexagatetest.NewServer(nil)
+	defer fake.Close()
+	store := state.NewMemory()
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	deployer := NewDeployer(fake.NewClient(), Config{ChainId: hexagate.CHAIN_ID_MAINNET, Params: PARAMS, Store: store, Now: func() time.Time { return now }})
+	service := httptest.NewServer(deployer.GameCreatedHandler(WebhookOptions{Logger: quiet}))
+	defer service.Close()
+
+	// We expect every monitor deployed from the alert to be recorded with the game of the event
+	if status, response := post(t, service.URL, "", readPayload(t)); status != http.StatusCreated {
+		t.Fatalf("Expected the monitors to be created, got %d: %s", status, response.Error)
+	}
+	records, err := store.Game(context.Background(), GAME)
+	if err != nil {
+		t.Fatalf("Error querying game: %v", err)
+	}
+	if len(records) != PER_GAME_MONITOR {
+		t.Fatalf("Expected %d records, got %d", PER_GAME_MONITOR, len(records))
+	}
+	for i, m := range perGame(t) {
+		r := records[i]
+		if r.Monitor != m.Name || r.GateHash != m.Hash() || r.MonitorId == 0 || !r.DeployedAt.Equal(now) {
+			t.Errorf("Unexpected record for %s: %+v", m.Name, r)
+		}
+		if r.Game != GAME || r.GameType != 0 || r.CreatedBlock != 21874230 || !strings.HasPrefix(r.RootClaim, "0x6f2a4d5c") {
+			t.Errorf("Expected the game of the event in the record for %s, got %+v", m.Name, r)
+		}
+		if r.Params[GAME_PARAM] != GAME {
+			t.Errorf("Expected the deployed params in the record for %s, got %v", m.Name, r.Params)
+		}
+	}
+	outdated, _ := store.Outdated(context.Background(), map[string]string{"eth_deficit": monitors.Hash("changed")})
+	if games := state.Games(outdated); len(games) != 1 || games[0] != GAME {
+		t.Errorf("Expected the game to run an outdated eth_deficit, got %v", games)
+	}
+
+	// We expect a recorded monitor deleted outside of the deployer to be deployed again and re-recorded
+	deleted := records[3]
+	if err := fake.NewClient().DeleteMonitor(context.Background(), deleted.MonitorId); err != nil {
+		t.Fatalf("Error deleting monitor: %v", err)
+	}
+	deployment, err := deployer.DeployGame(context.Background(), Game{Address: GAME})
+	if err != nil {
+		t.Fatalf("Error redeploying: %v", err)
+	}
+	if deployment.Created != 1 || len(fake.Monitors()) != PER_GAME_MONITOR {
+		t.Errorf("Expected only the deleted monitor to be created, got %d created", deployment.Created)
+	}
+	record, err := store.Get(context.Background(), GAME, deleted.Monitor)
+	if err != nil || record.MonitorId == deleted.MonitorId {
+		t.Errorf("Expected %s to be recorded with its new ID, got %+v, %v", deleted.Monitor, record, err)
+	}
+}
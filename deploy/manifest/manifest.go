@@ -0,0 +1,210 @@
+// Package manifest deploys the Single Instance monitors of every network listed in a YAML manifest. A plan
+// compares the monitors the manifest describes to the monitors deployed to Hexagate, and applying it creates,
+// updates and deletes monitors until they match.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/base-org/fault-proof-monitors/deploy"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+	"github.com/base-org/fault-proof-monitors/hexagate/preflight"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+// Manifest lists the networks to monitor.
+//
+//	networks:
+//	  - name: base-mainnet
+//	    chain_id: 1
+//	    l2_chain_id: 8453
+//	    contracts:
+//	      optimism_portal_proxy: 0x49048044D57e1C92A77f79988d21Fa8fAF74E97e
+//	      dispute_game_factory_proxy: 0x43edB88C4B80fDD2AdFF2412A7BebF9dF42cB40e
+//	      multicall3: 0xcA11bde05977b3631167028862bE2a173976CA11
+//	    honest_proposer: 0x642229f238fb9dE03374Be34B0eD8D9De80752c5
+//	    honest_challenger: 0x6F8C5bA3F59ea3E76300E3BEcDC231D656017824
+//	    channels: [12]
+//	    monitors:
+//	      - duplicate_dispute_game
+//	      - fault_proof_detection_parent
+type Manifest struct {
+	Networks []Network `yaml:"networks"`
+}
+
+// Network is a chain whose dispute games are monitored, and the monitors to run on it.
+type Network struct {
+	// Name identifies the network, and is appended to the name of every monitor deployed for it.
+	Name string `yaml:"name"`
+	// ChainId is the chain the monitors run on, where the contracts are deployed, and L2ChainId the chain
+	// whose outputs are proposed.
+	ChainId   int `yaml:"chain_id"`
+	L2ChainId int `yaml:"l2_chain_id"`
+
+	Contracts        Contracts `yaml:"contracts"`
+	HonestProposer   string    `yaml:"honest_proposer"`
+	HonestChallenger string    `yaml:"honest_challenger"`
+
+	// Channels are the notification channels every monitor alerts.
+	Channels []int `yaml:"channels"`
+	// Params sets any other param the monitors declare, such as extraTimeInSeconds.
+	Params map[string]string `yaml:"params"`
+	// Monitors names the Single Instance monitors to run. Monitors deployed for the network but not listed are
+	// deleted.
+	Monitors []string `yaml:"monitors"`
+}
+
+// Contracts are the addresses of the contracts the monitors read.
+type Contracts struct {
+	OptimismPortalProxy     string `yaml:"optimism_portal_proxy"`
+	DisputeGameFactoryProxy string `yaml:"dispute_game_factory_proxy"`
+	Multicall3              string `yaml:"multicall3"`
+}
+
+// Load reads and validates the manifest at path.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a manifest. Unknown fields are rejected, so that a misspelled field is not
+// silently left out of the deployment.
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks that the networks are named uniquely and that every monitor they list can be deployed with
+// the params of the network.
+func (m *Manifest) Validate() error {
+	if len(m.Networks) == 0 {
+		return fmt.Errorf("manifest lists no networks")
+	}
+	seen := make(map[string]bool)
+	for i := range m.Networks {
+		n := &m.Networks[i]
+		if n.Name == "" || strings.ContainsAny(n.Name, " \t\n") {
+			return fmt.Errorf("network %d: name %q must be set and contain no spaces", i+1, n.Name)
+		}
+		if seen[n.Name] {
+			return fmt.Errorf("network %s is listed twice", n.Name)
+		}
+		seen[n.Name] = true
+		if _, err := n.Requests(); err != nil {
+			return fmt.Errorf("network %s: %w", n.Name, err)
+		}
+	}
+	return nil
+}
+
+// Values returns the value of every param the network sets, by the name the monitors declare it with.
+func (n *Network) Values() (map[string]any, error) {
+	params := make(map[string]any)
+	for name, text := range n.Params {
+		value, err := deploy.ParamValue(name, text)
+		if err != nil {
+			return nil, err
+		}
+		params[name] = value
+	}
+
+	for _, field := range []struct{ param, value string }{
+		{"optimismPortalProxy", n.Contracts.OptimismPortalProxy},
+		{"disputeGameFactoryProxy", n.Contracts.DisputeGameFactoryProxy},
+		{"multicall3", n.Contracts.Multicall3},
+		{"honestProposer", n.HonestProposer},
+		{"honestChallenger", n.HonestChallenger},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if _, ok := params[field.param]; ok {
+			return nil, fmt.Errorf("param %s is set by both params and a field of the network", field.param)
+		}
+		address, err := deploy.Address(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field.param, err)
+		}
+		params[field.param] = address
+	}
+	if n.L2ChainId != 0 {
+		if _, ok := params["l2ChainId"]; ok {
+			return nil, fmt.Errorf("param l2ChainId is set by both params and l2_chain_id")
+		}
+		params["l2ChainId"] = json.Number(strconv.Itoa(n.L2ChainId))
+	}
+	return params, nil
+}
+
+// description is the description of monitor m deployed to a network, which marks the monitors the manifest
+// manages.
+func description(m *monitors.Monitor, network string) string {
+	return fmt.Sprintf("%s on %s", m.Name, network)
+}
+
+// Requests builds the request deploying each monitor of the network, in the order they are listed.
+func (n *Network) Requests() ([]hexagate.MonitorRequest, error) {
+	if n.ChainId <= 0 {
+		return nil, fmt.Errorf("chain_id must be set")
+	}
+	params, err := n.Values()
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []hexagate.MonitorRequest
+	seen := make(map[string]bool)
+	for _, name := range n.Monitors {
+		m, err := monitors.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[m.Name] {
+			return nil, fmt.Errorf("monitor %s is listed twice", m.Name)
+		}
+		seen[m.Name] = true
+		if m.Workflow != monitors.SINGLE_INSTANCE {
+			return nil, fmt.Errorf("monitor %s is a %s monitor, deployed to each game by fpmon serve", m.Name, m.Workflow)
+		}
+
+		values := make(map[string]any, len(m.Params))
+		for _, p := range m.Params {
+			if value, ok := params[p.Name]; ok {
+				values[p.Name] = value
+			}
+		}
+		file, err := m.Parse()
+		if err != nil {
+			return nil, err
+		}
+		if err := preflight.Check(m.File, file, hexagate.ValidateRequest{Params: values}).Err(); err != nil {
+			return nil, &deploy.ParamsError{Monitor: m.Name, Err: err}
+		}
+		requests = append(requests, hexagate.MonitorRequest{
+			Name:                   deploy.MonitorName(m, n.Name),
+			Description:            description(m, n.Name),
+			Gate:                   m.Source,
+			ChainId:                n.ChainId,
+			Params:                 values,
+			NotificationChannelIds: n.Channels,
+		})
+	}
+	return requests, nil
+}
@@ -0,0 +1,304 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/deploy"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+	"github.com/base-org/fault-proof-monitors/hexagate/hexagatetest"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+const MANIFEST = "testdata/manifest.yaml"
+
+func load(t *testing.T) *Manifest {
+	t.Helper()
+	m, err := Load(MANIFEST)
+	if err != nil {
+		t.Fatalf("Error loading manifest %s: %v", MANIFEST, err)
+	}
+	return m
+}
+
+func TestLoad(t *testing.T) {
+	m := load(t)
+	if len(m.Networks) != 2 {
+		t.Fatalf("Expected 2 networks, got %d", len(m.Networks))
+	}
+
+	// We expect a request per monitor, with the params the monitor declares taken from the fields of the network
+	requests, err := m.Networks[0].Requests()
+	if err != nil {
+		t.Fatalf("Error building requests: %v", err)
+	}
+	if len(requests) != 2 || requests[0].Name != "duplicate_dispute_game base-mainnet" || requests[1].Name != "fault_proof_detection_parent base-mainnet" {
+		t.Fatalf("Expected the 2 monitors of base-mainnet, got %v", requests)
+	}
+	parent := requests[1]
+	factory, _ := deploy.Address("0x43edB88C4B80fDD2AdFF2412A7BebF9dF42cB40e")
+	if len(parent.Params) != 2 || parent.Params["disputeGameFactoryProxy"] != factory || parent.Params["l2ChainId"] != json.Number("8453") {
+		t.Errorf("Expected disputeGameFactoryProxy and l2ChainId, got %v", parent.Params)
+	}
+	if parent.ChainId != 1 || len(parent.NotificationChannelIds) != 1 || parent.NotificationChannelIds[0] != 12 {
+		t.Errorf("Expected chain 1 and channel 12, got chain %d and channels %v", parent.ChainId, parent.NotificationChannelIds)
+	}
+	m2, _ := monitors.Get("fault_proof_detection_parent")
+	if parent.Gate != m2.Source {
+		t.Errorf("Expected the gate file of %s", m2.Name)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	network := func(fields string) string {
+		return "networks:\n  - name: base-mainnet\n    chain_id: 1\n" + fields
+	}
+	for _, tc := range []struct {
+		name     string
+		manifest string
+		want     string
+	}{
+		{"no networks", "networks: []\n", "lists no networks"},
+		{"unknown field", network("    chanels: [12]\n"), "field chanels not found"},
+		{"missing chain ID", "networks:\n  - name: base-mainnet\n", "chain_id must be set"},
+		{"space in name", "networks:\n  - name: base mainnet\n    chain_id: 1\n", "contain no spaces"},
+		{"duplicate network", network("") + "  - name: base-mainnet\n    chain_id: 1\n", "listed twice"},
+		{"unknown monitor", network("    monitors: [duplicate_dispute]\n"), "unknown monitor"},
+		{"per game monitor", network("    monitors: [eth_deficit]\n"), "deployed to each game by fpmon serve"},
+		{"missing param", network("    monitors: [fault_proof_detection_parent]\n"), "disputeGameFactoryProxy"},
+		{"invalid address", network("    contracts:\n      multicall3: 0xcA11\n"), "multicall3"},
+		{"param set twice", network("    l2_chain_id: 8453\n    params:\n      l2ChainId: 10\n"), "set by both"},
+		{"invalid integer", network("    params:\n      extraTimeInSeconds: soon\n"), "declared as integer"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// We expect an invalid manifest to be rejected before anything is planned
+			_, err := Parse([]byte(tc.manifest))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func plan(t *testing.T, client *hexagate.Client, m *Manifest) *Plan {
+	t.Helper()
+	p, err := MakePlan(context.Background(), client, m)
+	if err != nil {
+		t.Fatalf("Error planning: %v", err)
+	}
+	return p
+}
+
+func apply(t *testing.T, client *hexagate.Client, p *Plan) {
+	t.Helper()
+	n, err := p.Apply(context.Background(), client)
+	if err != nil || n != len(p.Changes) {
+		t.Fatalf("Error applying %d changes after %d: %v", len(p.Changes), n, err)
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	fake := hexagatetest.NewServer(nil)
+	defer fake.Close()
+	client := fake.NewClient()
+	ctx := context.Background()
+	m := load(t)
+
+	// We expect a monitor deployed by other means to be left alone
+	dup, _ := monitors.Get("duplicate_dispute_game")
+	unmanaged, err := client.CreateMonitor(ctx, hexagate.MonitorRequest{Name: dup.Name, Gate: dup.Source, ChainId: 1})
+	if err != nil {
+		t.Fatalf("Error creating monitor: %v", err)
+	}
+
+	// We expect a monitor named after a network of the manifest but deployed by hand to be left alone too
+	deficit, _ := monitors.Get("eth_deficit")
+	handmade, err := client.CreateMonitor(ctx, hexagate.MonitorRequest{Name: "eth_deficit base-mainnet", Description: "deployed by hand", Gate: deficit.Source, ChainId: 1})
+	if err != nil {
+		t.Fatalf("Error creating monitor: %v", err)
+	}
+
+	// We expect every monitor of the manifest to be created, and nothing left to do after applying
+	p := plan(t, client, m)
+	if len(p.Changes) != 3 || len(p.Unchanged) != 0 {
+		t.Fatalf("Expected 3 monitors to create, got %+v", p)
+	}
+	for _, c := range p.Changes {
+		if c.Action != CREATE {
+			t.Errorf("Expected %s to be created, got %s", c.Name, c.Action)
+		}
+	}
+	apply(t, client, p)
+	if got := len(fake.Monitors()); got != 5 {
+		t.Errorf("Expected 5 monitors on the fake, got %d", got)
+	}
+	p = plan(t, client, m)
+	if !p.Empty() || len(p.Unchanged) != 3 {
+		t.Errorf("Expected no changes and 3 unchanged monitors, got %+v", p)
+	}
+
+	// We expect monitors changed on Hexagate or in the manifest to be updated, and those removed from the
+	// manifest to be deleted
+	var dupId int
+	for _, monitor := range fake.Monitors() {
+		if monitor.Name == "duplicate_dispute_game base-sepolia" {
+			dupId = monitor.Id
+			stale := hexagate.MonitorRequest{Name: monitor.Name, Description: monitor.Description, Gate: monitor.Gate + "\n", ChainId: monitor.ChainId, Params: monitor.Params, NotificationChannelIds: monitor.NotificationChannelIds}
+			if _, err := client.UpdateMonitor(ctx, monitor.Id, stale); err != nil {
+				t.Fatalf("Error updating monitor: %v", err)
+			}
+		}
+	}
+	m.Networks[0].L2ChainId = 10
+	m.Networks[0].Monitors = []string{"fault_proof_detection_parent"}
+	m.Networks[1].Channels = []int{13, 14}
+
+	p = plan(t, client, m)
+	var text bytes.Buffer
+	if err := WriteText(&text, p); err != nil {
+		t.Fatalf("Error writing plan: %v", err)
+	}
+	for _, want := range []string{
+		"delete duplicate_dispute_game base-mainnet (#",
+		"update fault_proof_detection_parent base-mainnet (#",
+		"    params.l2ChainId: 8453 -> 10\n",
+		"update duplicate_dispute_game base-sepolia (#",
+		"    notification_channel_ids: [13] -> [13 14]\n",
+		"Plan: 0 to create, 2 to update, 1 to delete, 0 unchanged.\n",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Expected the plan to contain %q, got\n%s", want, text.String())
+		}
+	}
+	for _, c := range p.Changes {
+		if c.Id == dupId && (len(c.Diff) != 2 || c.Diff[0].Name != "gate") {
+			t.Errorf("Expected the gate and channels of %s to differ, got %v", c.Name, c.Diff)
+		}
+	}
+
+	apply(t, client, p)
+	if p = plan(t, client, m); !p.Empty() || len(p.Unchanged) != 2 {
+		t.Errorf("Expected no changes after applying, got %+v", p)
+	}
+	if _, err := client.GetMonitor(ctx, unmanaged.Id); err != nil {
+		t.Errorf("Expected the unmanaged monitor to be kept, got %v", err)
+	}
+	if _, err := client.GetMonitor(ctx, handmade.Id); err != nil {
+		t.Errorf("Expected the monitor deployed by hand to be kept, got %v", err)
+	}
+	if got := len(fake.Monitors()); got != 4 {
+		t.Errorf("Expected 4 monitors on the fake, got %d", got)
+	}
+}
+
+func TestPlanRemovedNetwork(t *testing.T) {
+	fake := hexagatetest.NewServer(nil)
+	defer fake.Close()
+	client := fake.NewClient()
+	ctx := context.Background()
+	m := load(t)
+	apply(t, client, plan(t, client, m))
+
+	// We expect a monitor named like a removed network but deployed by other means to be left alone
+	dup, _ := monitors.Get("duplicate_dispute_game")
+	unmanaged, err := client.CreateMonitor(ctx, hexagate.MonitorRequest{Name: "duplicate_dispute_game op-mainnet", Gate: dup.Source, ChainId: 1})
+	if err != nil {
+		t.Fatalf("Error creating monitor: %v", err)
+	}
+
+	// We expect the monitors of a network removed from the manifest to be deleted
+	m.Networks = m.Networks[:1]
+	p := plan(t, client, m)
+	if len(p.Changes) != 1 || p.Changes[0].Action != DELETE || p.Changes[0].Network != "base-sepolia" || p.Changes[0].Name != "duplicate_dispute_game base-sepolia" {
+		t.Fatalf("Expected the monitor of base-sepolia to be deleted, got %+v", p.Changes)
+	}
+	apply(t, client, p)
+	if p = plan(t, client, m); !p.Empty() {
+		t.Errorf("Expected no changes after applying, got %+v", p.Changes)
+	}
+	if _, err := client.GetMonitor(ctx, unmanaged.Id); err != nil {
+		t.Errorf("Expected the unmanaged monitor to be kept, got %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	parent, _ := monitors.Get("fault_proof_detection_parent")
+	request := &hexagate.MonitorRequest{
+		Name:        "fault_proof_detection_parent base-mainnet",
+		Description: "fault_proof_detection_parent on base-mainnet",
+		Gate:        parent.Source,
+		ChainId:     1,
+		Params:      map[string]any{"disputeGameFactoryProxy": "0x43edB88C4B80fDD2AdFF2412A7BebF9dF42cB40e", "l2ChainId": json.Number("8453")},
+	}
+	monitor := &hexagate.Monitor{
+		Name:        request.Name,
+		Description: "fault_proof_detection_parent on Base-Mainnet",
+		Gate:        request.Gate,
+		ChainId:     1,
+		Params:      map[string]any{"disputeGameFactoryProxy": "0x43edb88c4b80fdd2adff2412a7bebf9df42cb40e", "l2ChainId": json.Number("8453")},
+	}
+
+	// We expect addresses to compare regardless of case, and every other field exactly
+	diff := Diff(monitor, request, parent)
+	if len(diff) != 1 || diff[0].Name != "description" {
+		t.Errorf("Expected only the description to differ, got %v", diff)
+	}
+}
+
+func TestApplyStale(t *testing.T) {
+	fake := hexagatetest.NewServer(nil)
+	defer fake.Close()
+	client := fake.NewClient()
+	ctx := context.Background()
+	m := load(t)
+	apply(t, client, plan(t, client, m))
+
+	m.Networks[0].Monitors = []string{"fault_proof_detection_parent"}
+	m.Networks[0].L2ChainId = 10
+	p := plan(t, client, m)
+	for _, monitor := range fake.Monitors() {
+		if err := client.DeleteMonitor(ctx, monitor.Id); err != nil {
+			t.Fatalf("Error deleting monitor: %v", err)
+		}
+	}
+
+	// We expect monitors deleted since planning to stay deleted, and an update to one of them to stop applying
+	n, err := p.Apply(ctx, client)
+	if !errors.Is(err, hexagate.ErrNotFound) || !strings.Contains(err.Error(), "update fault_proof_detection_parent base-mainnet") {
+		t.Errorf("Expected the update to fail with ErrNotFound, got %v", err)
+	}
+	if n != 1 || p.Changes[0].Action != DELETE {
+		t.Errorf("Expected the delete to be applied before the update, got %d changes applied of %+v", n, p.Changes)
+	}
+
+	var out bytes.Buffer
+	if err := WriteJSON(&out, p); err != nil {
+		t.Fatalf("Error writing plan: %v", err)
+	}
+	var decoded Plan
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded.Changes) != len(p.Changes) {
+		t.Errorf("Expected the JSON plan to decode, got %v", err)
+	}
+}
+
+func TestReadmeManifest(t *testing.T) {
+	data, err := os.ReadFile("../../README.md")
+	if err != nil {
+		t.Fatalf("Error reading file %s: %v", "README.md", err)
+	}
+
+	// We expect the manifest in the README to be valid
+	_, rest, ok := strings.Cut(string(data), "```yaml\nnetworks:")
+	example, _, _ := strings.Cut(rest, "```")
+	if !ok {
+		t.Fatalf("Expected a manifest in the README")
+	}
+	if _, err := Parse([]byte("networks:" + example)); err != nil {
+		t.Errorf("Error parsing the README manifest: %v", err)
+	}
+}
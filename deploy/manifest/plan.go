@@ -0,0 +1,269 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/deploy"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+// Action is what applying a change does to a monitor.
+type Action string
+
+const (
+	CREATE Action = "create"
+	UPDATE Action = "update"
+	DELETE Action = "delete"
+)
+
+// ABSENT is shown in a diff for a field or param that is not set.
+const ABSENT = "(none)"
+
+// Field is a field of a monitor that differs between Hexagate and the manifest. Params are compared one by one,
+// as params.<name>, and the gate file by the hash of its source.
+type Field struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Change is a monitor to create, update or delete.
+type Change struct {
+	Action  Action `json:"action"`
+	Network string `json:"network"`
+	Name    string `json:"name"`
+	// Id is the ID of the monitor to update or delete.
+	Id int `json:"id,omitempty"`
+	// Request is the monitor to create, or what to update the monitor to.
+	Request *hexagate.MonitorRequest `json:"request,omitempty"`
+	Diff    []Field                  `json:"diff,omitempty"`
+}
+
+// Plan is the changes that make the monitors deployed to Hexagate match a manifest, sorted by network and
+// monitor name.
+type Plan struct {
+	Changes []Change `json:"changes"`
+	// Unchanged lists the monitors that already match the manifest.
+	Unchanged []string `json:"unchanged"`
+}
+
+// Empty reports whether the deployed monitors already match the manifest.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// MakePlan compares the monitors of every network of the manifest to the monitors deployed to Hexagate. A
+// deployed monitor belongs to a network if it is named after a monitor of the registry and the network, as
+// deploy.MonitorName names it and has the description a manifest deploys it with; those not listed in the
+// manifest are planned for deletion. So are the monitors of networks removed from the manifest, recognized the
+// same way. Monitors deployed by other means are left alone.
+func MakePlan(ctx context.Context, client *hexagate.Client, m *Manifest) (*Plan, error) {
+	all, err := client.AllMonitors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := monitors.All()
+	if err != nil {
+		return nil, err
+	}
+	deployed := make(map[string]*hexagate.Monitor, len(all))
+	for i := range all {
+		deployed[all[i].Name] = &all[i]
+	}
+
+	plan := &Plan{Changes: []Change{}, Unchanged: []string{}}
+	for i := range m.Networks {
+		n := &m.Networks[i]
+		requests, err := n.Requests()
+		if err != nil {
+			return nil, fmt.Errorf("network %s: %w", n.Name, err)
+		}
+		desired := make(map[string]bool, len(requests))
+		for i := range requests {
+			request := &requests[i]
+			// requests follow the order of the monitors of the network
+			registered, err := monitors.Get(n.Monitors[i])
+			if err != nil {
+				return nil, err
+			}
+			desired[request.Name] = true
+			monitor, ok := deployed[request.Name]
+			if !ok {
+				plan.Changes = append(plan.Changes, Change{Action: CREATE, Network: n.Name, Name: request.Name, Request: request})
+				continue
+			}
+			if diff := Diff(monitor, request, registered); len(diff) > 0 {
+				plan.Changes = append(plan.Changes, Change{Action: UPDATE, Network: n.Name, Name: request.Name, Id: monitor.Id, Request: request, Diff: diff})
+			} else {
+				plan.Unchanged = append(plan.Unchanged, request.Name)
+			}
+		}
+		for _, registered := range registry {
+			name := deploy.MonitorName(registered, n.Name)
+			// monitors of the same name created by hand rather than by a manifest are left alone
+			if monitor, ok := deployed[name]; ok && !desired[name] && monitor.Description == description(registered, n.Name) {
+				plan.Changes = append(plan.Changes, Change{Action: DELETE, Network: n.Name, Name: name, Id: monitor.Id})
+			}
+		}
+	}
+
+	networks := make(map[string]bool, len(m.Networks))
+	for _, n := range m.Networks {
+		networks[n.Name] = true
+	}
+	for i := range all {
+		monitor := &all[i]
+		for _, registered := range registry {
+			network, ok := strings.CutPrefix(monitor.Name, registered.Name+" ")
+			if ok && registered.Workflow == monitors.SINGLE_INSTANCE && !networks[network] && monitor.Description == description(registered, network) {
+				plan.Changes = append(plan.Changes, Change{Action: DELETE, Network: network, Name: monitor.Name, Id: monitor.Id})
+			}
+		}
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		return a.Name < b.Name
+	})
+	sort.Strings(plan.Unchanged)
+	return plan, nil
+}
+
+// Diff returns the fields of a deployed monitor that differ from the request deploying m. Params m declares
+// as address are equal if they differ only in case, as Hexagate may return them in another case than the
+// EIP-55 form they are sent in.
+func Diff(monitor *hexagate.Monitor, request *hexagate.MonitorRequest, m *monitors.Monitor) []Field {
+	var diff []Field
+	add := func(name, from, to string) {
+		if from != to {
+			diff = append(diff, Field{Name: name, From: from, To: to})
+		}
+	}
+
+	add("description", text(monitor.Description), text(request.Description))
+	add("gate", hash(monitor.Gate), hash(request.Gate))
+	add("chain_id", fmt.Sprint(monitor.ChainId), fmt.Sprint(request.ChainId))
+
+	names := make(map[string]bool)
+	for name := range monitor.Params {
+		names[name] = true
+	}
+	for name := range request.Params {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		from, ok := monitor.Params[name]
+		to, ok2 := request.Params[name]
+		if param, declared := m.Param(name); declared && param.Type == "address" && strings.EqualFold(value(from, ok), value(to, ok2)) {
+			continue
+		}
+		add("params."+name, value(from, ok), value(to, ok2))
+	}
+
+	add("notification_channel_ids", channels(monitor.NotificationChannelIds), channels(request.NotificationChannelIds))
+	return diff
+}
+
+func text(s string) string {
+	if s == "" {
+		return ABSENT
+	}
+	return s
+}
+
+// hash abbreviates the hash of a gate file source.
+func hash(source string) string {
+	return monitors.Hash(source)[:12]
+}
+
+// value renders a param as JSON, so that numbers decoded from a response compare equal to the numbers in the
+// manifest.
+func value(v any, ok bool) string {
+	if !ok {
+		return ABSENT
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func channels(ids []int) string {
+	sorted := append([]int{}, ids...)
+	sort.Ints(sorted)
+	return fmt.Sprint(sorted)
+}
+
+// Apply makes the changes of the plan in order, and returns how many were made. It stops at the first change
+// that fails; planning again picks up the changes left.
+func (p *Plan) Apply(ctx context.Context, client *hexagate.Client) (int, error) {
+	for i, change := range p.Changes {
+		var err error
+		switch change.Action {
+		case CREATE:
+			_, err = client.CreateMonitor(ctx, *change.Request)
+		case UPDATE:
+			_, err = client.UpdateMonitor(ctx, change.Id, *change.Request)
+		case DELETE:
+			// deleted since the plan was made
+			if err = client.DeleteMonitor(ctx, change.Id); errors.Is(err, hexagate.ErrNotFound) {
+				err = nil
+			}
+		default:
+			err = fmt.Errorf("unknown action %q", change.Action)
+		}
+		if err != nil {
+			return i, fmt.Errorf("%s %s: %w", change.Action, change.Name, err)
+		}
+	}
+	return len(p.Changes), nil
+}
+
+// WriteText writes a line per change, followed by the fields an update changes, and a summary.
+//
+//	create duplicate_dispute_game base-mainnet
+//	update fault_proof_detection_parent base-mainnet (#12)
+//	    params.l2ChainId: 10 -> 8453
+//	Plan: 1 to create, 1 to update, 0 to delete, 0 unchanged.
+func WriteText(w io.Writer, plan *Plan) error {
+	var b strings.Builder
+	counts := make(map[Action]int)
+	for _, c := range plan.Changes {
+		counts[c.Action]++
+		fmt.Fprintf(&b, "%s %s", c.Action, c.Name)
+		if c.Id != 0 {
+			fmt.Fprintf(&b, " (#%d)", c.Id)
+		}
+		b.WriteString("\n")
+		for _, f := range c.Diff {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", f.Name, f.From, f.To)
+		}
+	}
+	fmt.Fprintf(&b, "Plan: %d to create, %d to update, %d to delete, %d unchanged.\n",
+		counts[CREATE], counts[UPDATE], counts[DELETE], len(plan.Unchanged))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the plan as a JSON object.
+func WriteJSON(w io.Writer, plan *Plan) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(plan)
+}
@@ -0,0 +1,239 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/base-org/fault-proof-monitors/deploy/state"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+// Chain reads the state of dispute games and their DelayedWETH contracts.
+type Chain interface {
+	// Timestamp returns the timestamp of the latest block.
+	Timestamp(ctx context.Context) (uint64, error)
+
+	// ResolvedAt returns resolvedAt() of the game, 0 while it is in progress.
+	ResolvedAt(ctx context.Context, game string) (uint64, error)
+
+	// Claimants returns the claimants of every claim of the game, the addresses that can be owed a credit.
+	Claimants(ctx context.Context, game string) ([]string, error)
+
+	// Credit returns credit(recipient) of the game, the bonds recipient has not claimed yet.
+	Credit(ctx context.Context, game, recipient string) (*big.Int, error)
+
+	// WETH returns weth() of the game, the DelayedWETH contract holding its bonds.
+	WETH(ctx context.Context, game string) (string, error)
+
+	// Delay returns delay() of a DelayedWETH contract in seconds.
+	Delay(ctx context.Context, weth string) (uint64, error)
+}
+
+// ReaperConfig configures when a Reaper tears down the monitors of a game.
+type ReaperConfig struct {
+	// Grace is how long after the DelayedWETH delay has passed the monitors of a resolved game are kept.
+	Grace time.Duration
+
+	// DryRun decides which games would be torn down without disabling or deleting anything.
+	DryRun bool
+}
+
+// Reaper tears down the monitors deployed to dispute games whose lifecycle has ended. A game has ended once it
+// is resolved, every bond has been claimed, leaving no credit in the game, and the DelayedWETH delay plus the
+// grace period has passed since it was resolved, so no withdrawal the monitors check can still happen.
+type Reaper struct {
+	client *hexagate.Client
+	store  state.Store
+	chain  Chain
+	config ReaperConfig
+}
+
+// NewReaper creates a reaper for the games recorded in store.
+func NewReaper(client *hexagate.Client, store state.Store, chain Chain, config ReaperConfig) *Reaper {
+	return &Reaper{client: client, store: store, chain: chain, config: config}
+}
+
+// Decision is whether the monitors of a game are torn down, and why.
+type Decision struct {
+	Game     string   `json:"game"`
+	Monitors []string `json:"monitors"`
+	Reap     bool     `json:"reap"`
+	Reason   string   `json:"reason"`
+
+	// ResolvedAt, Delay and ReapAt are the timestamps and delay the decision was based on, 0 if unknown
+	ResolvedAt uint64 `json:"resolved_at"`
+	Delay      uint64 `json:"delay"`
+	ReapAt     uint64 `json:"reap_at"`
+	// Credit is the total credit left in the game, in wei.
+	Credit string `json:"credit,omitempty"`
+
+	// Reaped lists the monitors that were disabled and deleted, and Error why tearing down the rest failed.
+	Reaped []string `json:"reaped"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// ReapReport holds the decisions for every recorded game, sorted by game.
+type ReapReport struct {
+	Timestamp uint64     `json:"timestamp"`
+	DryRun    bool       `json:"dry_run"`
+	Games     []Decision `json:"games"`
+}
+
+// Reap decides for every recorded game whether its lifecycle has ended and, unless this is a dry run, disables
+// and deletes the monitors of those that have and removes them from the store. A failure to read the chain
+// for a game, or to tear down one of its monitors, is reported in the decision of the game rather than
+// failing the other games.
+func (r *Reaper) Reap(ctx context.Context) (*ReapReport, error) {
+	now, err := r.chain.Timestamp(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading the latest block: %w", err)
+	}
+	records, err := r.store.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byGame := make(map[string][]state.Record)
+	for _, record := range records {
+		key := strings.ToLower(record.Game)
+		byGame[key] = append(byGame[key], record)
+	}
+
+	report := &ReapReport{Timestamp: now, DryRun: r.config.DryRun, Games: []Decision{}}
+	for _, game := range state.Games(records) {
+		records := byGame[strings.ToLower(game)]
+		decision := r.decide(ctx, game, now)
+		for _, record := range records {
+			decision.Monitors = append(decision.Monitors, record.Monitor)
+		}
+		if decision.Reap && !r.config.DryRun {
+			r.teardown(ctx, &decision, records)
+		}
+		report.Games = append(report.Games, decision)
+	}
+	return report, nil
+}
+
+// decide reads the state of game and decides whether its monitors can be torn down at timestamp now.
+func (r *Reaper) decide(ctx context.Context, game string, now uint64) Decision {
+	decision := Decision{Game: game, Reaped: []string{}}
+	fail := func(err error) Decision {
+		decision.Reason = "error reading the game"
+		decision.Error = err.Error()
+		return decision
+	}
+
+	resolvedAt, err := r.chain.ResolvedAt(ctx, game)
+	if err != nil {
+		return fail(err)
+	}
+	decision.ResolvedAt = resolvedAt
+	if resolvedAt == 0 {
+		decision.Reason = "in progress"
+		return decision
+	}
+
+	claimants, err := r.chain.Claimants(ctx, game)
+	if err != nil {
+		return fail(err)
+	}
+	credit := new(big.Int)
+	seen := make(map[string]bool)
+	for _, claimant := range claimants {
+		if seen[strings.ToLower(claimant)] {
+			continue
+		}
+		seen[strings.ToLower(claimant)] = true
+		c, err := r.chain.Credit(ctx, game, claimant)
+		if err != nil {
+			return fail(err)
+		}
+		credit.Add(credit, c)
+	}
+	decision.Credit = credit.String()
+
+	weth, err := r.chain.WETH(ctx, game)
+	if err != nil {
+		return fail(err)
+	}
+	delay, err := r.chain.Delay(ctx, weth)
+	if err != nil {
+		return fail(err)
+	}
+	decision.Delay = delay
+	decision.ReapAt = resolvedAt + delay + uint64(r.config.Grace/time.Second)
+
+	switch {
+	case credit.Sign() > 0:
+		decision.Reason = fmt.Sprintf("%s wei of credit not claimed", credit)
+	case now < decision.ReapAt:
+		decision.Reason = fmt.Sprintf("delay and grace period end in %s", time.Duration(decision.ReapAt-now)*time.Second)
+	default:
+		decision.Reap = true
+		decision.Reason = "resolved and all bonds claimed"
+	}
+	return decision
+}
+
+// teardown disables and deletes the monitors of a game, forgetting each once it is gone from Hexagate.
+func (r *Reaper) teardown(ctx context.Context, decision *Decision, records []state.Record) {
+	for _, record := range records {
+		if err := r.remove(ctx, record); err != nil {
+			decision.Error = fmt.Sprintf("tearing down %s: %v", record.Monitor, err)
+			return
+		}
+		decision.Reaped = append(decision.Reaped, record.Monitor)
+	}
+}
+
+func (r *Reaper) remove(ctx context.Context, record state.Record) error {
+	// disabling first stops the alerts even if the delete fails
+	if _, err := r.client.DisableMonitor(ctx, record.MonitorId); err != nil && !errors.Is(err, hexagate.ErrNotFound) {
+		return err
+	}
+	if err := r.client.DeleteMonitor(ctx, record.MonitorId); err != nil && !errors.Is(err, hexagate.ErrNotFound) {
+		return err
+	}
+	return r.store.Delete(ctx, record.Game, record.Monitor)
+}
+
+// WriteText writes a line per game with the decision and, for reaped games, the monitors torn down.
+//
+//	0x8D8f2bD3e2B4BC0ae1D4C6B1C2c1E6A5c3f7D9e1: reap: resolved and all bonds claimed (7 of 7 monitors)
+//	0x49277EE36A024120Ee218127354c4a3591dc90A9: keep: 2000000000000000 wei of credit not claimed
+func WriteText(w io.Writer, report *ReapReport) error {
+	var b strings.Builder
+	for _, d := range report.Games {
+		action := "keep"
+		if d.Reap {
+			action = "reap"
+			if report.DryRun {
+				action = "would reap"
+			}
+		}
+		fmt.Fprintf(&b, "%s: %s: %s", d.Game, action, d.Reason)
+		if d.Reap && !report.DryRun {
+			fmt.Fprintf(&b, " (%d of %d monitors)", len(d.Reaped), len(d.Monitors))
+		} else if d.Reap {
+			fmt.Fprintf(&b, " (%d monitors)", len(d.Monitors))
+		}
+		if d.Error != "" {
+			fmt.Fprintf(&b, ": %s", d.Error)
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the report as a JSON object.
+func WriteJSON(w io.Writer, report *ReapReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,333 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/base-org/fault-proof-monitors/deploy/state"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+	"github.com/base-org/fault-proof-monitors/hexagate/hexagatetest"
+)
+
+const (
+	WETH       = "0x82024Cb3a6F6e8b1f6d2b9D3D0F0E3f6A1B7C4D2"
+	RESOLVED   = 1727784000
+	WETH_DELAY = 302400
+)
+
+// fakeGame is the state of a game as read by fakeChain.
+type fakeGame struct {
+	resolvedAt uint64
+	credit     map[string]int64
+	err        error
+}
+
+// fakeChain is a Chain answering from memory, with every game using the DelayedWETH at WETH.
+type fakeChain struct {
+	now   uint64
+	games map[string]*fakeGame
+}
+
+func (c *fakeChain) game(game string) (*fakeGame, error) {
+	g, ok := c.games[strings.ToLower(game)]
+	if !ok {
+		return nil, errors.New("execution reverted")
+	}
+	return g, g.err
+}
+
+func (c *fakeChain) Timestamp(ctx context.Context) (uint64, error) {
+	return c.now, nil
+}
+
+func (c *fakeChain) ResolvedAt(ctx context.Context, game string) (uint64, error) {
+	g, err := c.game(game)
+	if err != nil {
+		return 0, err
+	}
+	return g.resolvedAt, nil
+}
+
+func (c *fakeChain) Claimants(ctx context.Context, game string) ([]string, error) {
+	g, err := c.game(game)
+	if err != nil {
+		return nil, err
+	}
+	// the same claimant may hold several claims
+	claimants := []string{HONEST, HONEST}
+	for claimant := range g.credit {
+		claimants = append(claimants, claimant)
+	}
+	return claimants, nil
+}
+
+func (c *fakeChain) Credit(ctx context.Context, game, recipient string) (*big.Int, error) {
+	g, err := c.game(game)
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(g.credit[recipient]), nil
+}
+
+func (c *fakeChain) WETH(ctx context.Context, game string) (string, error) {
+	return WETH, nil
+}
+
+func (c *fakeChain) Delay(ctx context.Context, weth string) (uint64, error) {
+	if weth != WETH {
+		return 0, errors.New("execution reverted")
+	}
+	return WETH_DELAY, nil
+}
+
+// deployGames deploys the Per DisputeGame monitors to every game, recording them in a memory store.
+func deployGames(t *testing.T, games ...string) (*hexagatetest.Server, state.Store) {
+	t.Helper()
+	fake := hexagatetest.NewServer(nil)
+	t.Cleanup(fake.Close)
+	store := state.NewMemory()
+	deployer := NewDeployer(fake.NewClient(), Config{ChainId: hexagate.CHAIN_ID_MAINNET, Params: PARAMS, Store: store})
+	for _, game := range games {
+		if _, err := deployer.DeployGame(context.Background(), Game{Address: game}); err != nil {
+			t.Fatalf("Error deploying %s: %v", game, err)
+		}
+	}
+	return fake, store
+}
+
+func TestReap(t *testing.T) {
+	const (
+		IN_PROGRESS = "0x1111111111111111111111111111111111111111"
+		CREDIT      = "0x2222222222222222222222222222222222222222"
+		DELAYED     = "0x3333333333333333333333333333333333333333"
+	)
+	fake, store := deployGames(t, GAME, IN_PROGRESS, CREDIT, DELAYED)
+	grace := time.Hour
+	chain := &fakeChain{
+		now: RESOLVED + WETH_DELAY + 3600,
+		games: map[string]*fakeGame{
+			strings.ToLower(GAME):        {resolvedAt: RESOLVED, credit: map[string]int64{HONEST: 0}},
+			strings.ToLower(IN_PROGRESS): {},
+			strings.ToLower(CREDIT):      {resolvedAt: RESOLVED, credit: map[string]int64{MULTICALL3: 2e15}},
+			strings.ToLower(DELAYED):     {resolvedAt: RESOLVED + 1},
+		},
+	}
+
+	// We expect a dry run to decide without touching the monitors or the store
+	dry, err := NewReaper(fake.NewClient(), store, chain, ReaperConfig{Grace: grace, DryRun: true}).Reap(context.Background())
+	if err != nil {
+		t.Fatalf("Error reaping: %v", err)
+	}
+	if len(fake.Monitors()) != 4*PER_GAME_MONITOR {
+		t.Errorf("Expected a dry run to keep every monitor, got %d", len(fake.Monitors()))
+	}
+	var text bytes.Buffer
+	if err := WriteText(&text, dry); err != nil {
+		t.Fatalf("Error writing report: %v", err)
+	}
+	for _, want := range []string{
+		GAME + ": would reap: resolved and all bonds claimed (7 monitors)",
+		"0x1111111111111111111111111111111111111111: keep: in progress",
+		"0x2222222222222222222222222222222222222222: keep: 2000000000000000 wei of credit not claimed",
+		"0x3333333333333333333333333333333333333333: keep: delay and grace period end in 1s",
+	} {
+		if !strings.Contains(text.String(), want+"\n") {
+			t.Errorf("Expected the report to contain %q, got\n%s", want, text.String())
+		}
+	}
+
+	// We expect only the monitors of the ended game to be disabled, deleted and forgotten
+	report, err := NewReaper(fake.NewClient(), store, chain, ReaperConfig{Grace: grace}).Reap(context.Background())
+	if err != nil {
+		t.Fatalf("Error reaping: %v", err)
+	}
+	for _, d := range report.Games {
+		if d.Reap != (d.Game == GAME) {
+			t.Errorf("Expected only %s to be reaped, got %+v", GAME, d)
+		}
+		if d.Reap && (len(d.Reaped) != PER_GAME_MONITOR || d.Error != "") {
+			t.Errorf("Expected %d monitors reaped, got %v: %s", PER_GAME_MONITOR, d.Reaped, d.Error)
+		}
+		if d.Game == GAME && d.ReapAt != RESOLVED+WETH_DELAY+3600 {
+			t.Errorf("Expected %s to be reapable at %d, got %d", GAME, RESOLVED+WETH_DELAY+3600, d.ReapAt)
+		}
+	}
+	for _, m := range fake.Monitors() {
+		if strings.HasSuffix(m.Name, GAME) {
+			t.Errorf("Expected %s to be deleted", m.Name)
+		}
+	}
+	if len(fake.Monitors()) != 3*PER_GAME_MONITOR {
+		t.Errorf("Expected the monitors of 3 games to be kept, got %d", len(fake.Monitors()))
+	}
+	if records, _ := store.Game(context.Background(), GAME); len(records) != 0 {
+		t.Errorf("Expected the records of %s to be deleted, got %d", GAME, len(records))
+	}
+
+	// We expect monitors already deleted from Hexagate to be forgotten without failing
+	chain.now++
+	for _, m := range fake.Monitors() {
+		if strings.HasSuffix(m.Name, DELAYED) {
+			if err := fake.NewClient().DeleteMonitor(context.Background(), m.Id); err != nil {
+				t.Fatalf("Error deleting monitor: %v", err)
+			}
+		}
+	}
+	report, err = NewReaper(fake.NewClient(), store, chain, ReaperConfig{Grace: grace}).Reap(context.Background())
+	if err != nil {
+		t.Fatalf("Error reaping: %v", err)
+	}
+	if len(report.Games) != 3 || report.Games[2].Game != DELAYED || len(report.Games[2].Reaped) != PER_GAME_MONITOR {
+		t.Errorf("Expected the monitors of %s to be reaped, got %+v", DELAYED, report.Games)
+	}
+	if all, _ := store.All(context.Background()); len(all) != 2*PER_GAME_MONITOR {
+		t.Errorf("Expected the records of 2 games to be kept, got %d", len(all))
+	}
+}
+
+func TestReapChainError(t *testing.T) {
+	const BROKEN = "0x1111111111111111111111111111111111111111"
+	fake, store := deployGames(t, GAME, BROKEN)
+	chain := &fakeChain{
+		now: RESOLVED + WETH_DELAY,
+		games: map[string]*fakeGame{
+			strings.ToLower(GAME):   {resolvedAt: RESOLVED},
+			strings.ToLower(BROKEN): {err: errors.New("connection refused")},
+		},
+	}
+
+	// We expect a game that cannot be read to be kept and reported, without stopping the other games
+	report, err := NewReaper(fake.NewClient(), store, chain, ReaperConfig{}).Reap(context.Background())
+	if err != nil {
+		t.Fatalf("Error reaping: %v", err)
+	}
+	if len(report.Games) != 2 {
+		t.Fatalf("Expected 2 decisions, got %d", len(report.Games))
+	}
+	for _, d := range report.Games {
+		switch d.Game {
+		case BROKEN:
+			if d.Reap || d.Error != "connection refused" {
+				t.Errorf("Expected %s to be kept with its error, got %+v", BROKEN, d)
+			}
+		case GAME:
+			if !d.Reap || len(d.Reaped) != PER_GAME_MONITOR {
+				t.Errorf("Expected %s to be reaped, got %+v", GAME, d)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	if err := WriteJSON(&out, report); err != nil {
+		t.Fatalf("Error writing report: %v", err)
+	}
+	var decoded ReapReport
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded.Games) != 2 {
+		t.Errorf("Expected the JSON report to decode, got %v", err)
+	}
+}
+
+// rpcServer answers eth_call from a map of selector and arguments to the hex result.
+func rpcServer(t *testing.T, timestamp string, results map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id     int               `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response := map[string]any{"jsonrpc": "2.0", "id": req.Id}
+		switch req.Method {
+		case "eth_getBlockByNumber":
+			response["result"] = map[string]string{"timestamp": timestamp}
+		case "eth_call":
+			var call struct {
+				To   string `json:"to"`
+				Data string `json:"data"`
+			}
+			json.Unmarshal(req.Params[0], &call)
+			if result, ok := results[call.Data]; ok {
+				response["result"] = result
+			} else {
+				response["error"] = map[string]any{"code": 3, "message": "execution reverted"}
+			}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func word(n int64) string {
+	return hex.EncodeToString(uint256Word(big.NewInt(n)))
+}
+
+func addressHex(address string) string {
+	raw, _ := addressWord(address)
+	return hex.EncodeToString(raw)
+}
+
+func TestRPCChain(t *testing.T) {
+	call := func(signature string, args ...string) string {
+		return "0x" + hex.EncodeToString(selector(signature)) + strings.Join(args, "")
+	}
+	claim := func(claimant string) string {
+		return "0x" + word(0) + addressHex(MULTICALL3) + addressHex(claimant) + word(1e17) + word(0) + word(1) + word(0)
+	}
+	server := rpcServer(t, "0x66fbe540", map[string]string{
+		call("resolvedAt()"):                        "0x" + word(RESOLVED),
+		call("claimDataLen()"):                      "0x" + word(2),
+		call("claimData(uint256)", word(0)):         claim(HONEST),
+		call("claimData(uint256)", word(1)):         claim(MULTICALL3),
+		call("credit(address)", addressHex(HONEST)): "0x" + word(2e15),
+		call("weth()"):                              "0x" + addressHex(WETH),
+		call("delay()"):                             "0x" + word(WETH_DELAY),
+	})
+	chain := NewRPCChain(server.URL, nil)
+	ctx := context.Background()
+
+	// We expect the selectors to match the ABI of the dispute game
+	if got := hex.EncodeToString(selector("credit(address)")); got != "d5d44d80" {
+		t.Errorf("Expected the credit(address) selector d5d44d80, got %s", got)
+	}
+
+	// We expect every read to decode the words returned by eth_call
+	if now, err := chain.Timestamp(ctx); err != nil || now != 0x66fbe540 {
+		t.Errorf("Expected timestamp %d, got %d, %v", 0x66fbe540, now, err)
+	}
+	if resolvedAt, err := chain.ResolvedAt(ctx, GAME); err != nil || resolvedAt != RESOLVED {
+		t.Errorf("Expected resolvedAt %d, got %d, %v", RESOLVED, resolvedAt, err)
+	}
+	claimants, err := chain.Claimants(ctx, GAME)
+	if err != nil || len(claimants) != 2 || claimants[0] != HONEST || claimants[1] != MULTICALL3 {
+		t.Errorf("Expected claimants %s and %s, got %v, %v", HONEST, MULTICALL3, claimants, err)
+	}
+	if credit, err := chain.Credit(ctx, GAME, HONEST); err != nil || credit.Int64() != 2e15 {
+		t.Errorf("Expected credit 2000000000000000, got %v, %v", credit, err)
+	}
+	if weth, err := chain.WETH(ctx, GAME); err != nil || weth != WETH {
+		t.Errorf("Expected weth %s, got %s, %v", WETH, weth, err)
+	}
+	if delay, err := chain.Delay(ctx, WETH); err != nil || delay != WETH_DELAY {
+		t.Errorf("Expected delay %d, got %d, %v", uint64(WETH_DELAY), delay, err)
+	}
+
+	// We expect a reverted call to return the RPC error
+	var rpcErr *RPCError
+	if _, err := chain.Credit(ctx, GAME, MULTICALL3); !errors.As(err, &rpcErr) || rpcErr.Message != "execution reverted" {
+		t.Errorf("Expected a reverted call, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// RPCChain reads dispute games with eth_call from an Ethereum JSON-RPC endpoint.
+type RPCChain struct {
+	url        string
+	httpClient *http.Client
+	id         atomic.Int64
+}
+
+// NewRPCChain creates a chain reading from the JSON-RPC endpoint at url.
+func NewRPCChain(url string, httpClient *http.Client) *RPCChain {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &RPCChain{url: url, httpClient: httpClient}
+}
+
+func (c *RPCChain) Timestamp(ctx context.Context) (uint64, error) {
+	var block struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := c.call(ctx, "eth_getBlockByNumber", []any{"latest", false}, &block); err != nil {
+		return 0, err
+	}
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(block.Timestamp, "0x"), 16)
+	if !ok || !n.IsUint64() {
+		return 0, fmt.Errorf("invalid block timestamp %q", block.Timestamp)
+	}
+	return n.Uint64(), nil
+}
+
+func (c *RPCChain) ResolvedAt(ctx context.Context, game string) (uint64, error) {
+	return c.callUint64(ctx, game, "resolvedAt()")
+}
+
+func (c *RPCChain) Claimants(ctx context.Context, game string) ([]string, error) {
+	count, err := c.callUint64(ctx, game, "claimDataLen()")
+	if err != nil {
+		return nil, err
+	}
+	claimants := make([]string, 0, count)
+	for i := uint64(0); i < count; i++ {
+		// claimData(i) returns (parentIndex, counteredBy, claimant, bond, claim, position, clock)
+		words, err := c.ethCall(ctx, game, "claimData(uint256)", uint256Word(new(big.Int).SetUint64(i)))
+		if err != nil {
+			return nil, err
+		}
+		if len(words) < 3 {
+			return nil, fmt.Errorf("claimData(%d) of %s returned %d words, expected 7", i, game, len(words))
+		}
+		claimants = append(claimants, wordAddress(words[2]))
+	}
+	return claimants, nil
+}
+
+func (c *RPCChain) Credit(ctx context.Context, game, recipient string) (*big.Int, error) {
+	arg, err := addressWord(recipient)
+	if err != nil {
+		return nil, err
+	}
+	words, err := c.ethCall(ctx, game, "credit(address)", arg)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(words[0]), nil
+}
+
+func (c *RPCChain) WETH(ctx context.Context, game string) (string, error) {
+	words, err := c.ethCall(ctx, game, "weth()")
+	if err != nil {
+		return "", err
+	}
+	return wordAddress(words[0]), nil
+}
+
+func (c *RPCChain) Delay(ctx context.Context, weth string) (uint64, error) {
+	return c.callUint64(ctx, weth, "delay()")
+}
+
+func (c *RPCChain) callUint64(ctx context.Context, to, signature string) (uint64, error) {
+	words, err := c.ethCall(ctx, to, signature)
+	if err != nil {
+		return 0, err
+	}
+	n := new(big.Int).SetBytes(words[0])
+	if !n.IsUint64() {
+		return 0, fmt.Errorf("%s of %s returned %s, which does not fit in 64 bits", signature, to, n)
+	}
+	return n.Uint64(), nil
+}
+
+// ethCall calls the function with the given signature on contract to at the latest block and returns the
+// 32-byte words of the result. Results without any word are an error.
+func (c *RPCChain) ethCall(ctx context.Context, to, signature string, args ...[]byte) ([][]byte, error) {
+	data := selector(signature)
+	for _, arg := range args {
+		data = append(data, arg...)
+	}
+	var result string
+	call := map[string]string{"to": to, "data": "0x" + hex.EncodeToString(data)}
+	if err := c.call(ctx, "eth_call", []any{call, "latest"}, &result); err != nil {
+		return nil, fmt.Errorf("%s of %s: %w", signature, to, err)
+	}
+	out, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil || len(out) == 0 || len(out)%32 != 0 {
+		return nil, fmt.Errorf("%s of %s returned %q, expected 32-byte words", signature, to, result)
+	}
+	words := make([][]byte, len(out)/32)
+	for i := range words {
+		words[i] = out[i*32 : (i+1)*32]
+	}
+	return words, nil
+}
+
+// RPCError is an error returned by the JSON-RPC endpoint, e.g. for a reverted call.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+func (c *RPCChain) call(ctx context.Context, method string, params []any, result any) error {
+	body, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": c.id.Add(1), "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d %s", method, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("invalid %s response: %v", method, err)
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if err := json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("invalid %s result: %v", method, err)
+	}
+	return nil
+}
+
+// selector returns the first 4 bytes of the Keccak-256 hash of a function signature such as credit(address).
+func selector(signature string) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(signature))
+	return hash.Sum(nil)[:4]
+}
+
+func uint256Word(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, 32))
+}
+
+func addressWord(address string) ([]byte, error) {
+	address, err := Address(address)
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := hex.DecodeString(address[2:])
+	return append(make([]byte, 12), raw...), nil
+}
+
+func wordAddress(word []byte) string {
+	address, _ := Address("0x" + hex.EncodeToString(word))
+	return address
+}
@@ -0,0 +1,325 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	PUT    = "put"
+	DELETE = "delete"
+)
+
+// entry is a line of the log of a File store.
+type entry struct {
+	Op      string  `json:"op"`
+	Record  *Record `json:"record,omitempty"`
+	Game    string  `json:"game,omitempty"`
+	Monitor string  `json:"monitor,omitempty"`
+}
+
+// File is a Store backed by a log of JSON lines, one per put or delete, that is replayed into memory. Every
+// change is synced to disk before it returns. Opening the store compacts the log to a put per record, and a last
+// line left incomplete by a crash is dropped.
+//
+// Several stores, in one or more processes, can share a log, e.g. fpmon serve recording deployments while fpmon
+// reap tears games down. Every operation takes a lock on <path>.lock for its own duration only: exclusive to
+// append or compact, shared to read. Under the lock the store first reads the entries other stores appended since
+// its last operation, or replays the whole log if another store compacted it. On platforms without file locks a
+// single store must write the log.
+type File struct {
+	path   string
+	memory *Memory
+
+	mu     sync.Mutex
+	closed bool
+	// log is the log file replayed into memory, read up to offset, which is the end of line
+	log    os.FileInfo
+	offset int64
+	line   int
+}
+
+// OpenFile opens the store logged at path, creating it if it does not exist.
+func OpenFile(path string) (*File, error) {
+	f := &File{path: path, memory: NewMemory()}
+	lock, err := lockFile(path+".lock", true)
+	if err != nil {
+		return nil, err
+	}
+	// closing the lock file releases the lock
+	defer lock.Close()
+
+	if err := f.refresh(); err != nil {
+		return nil, err
+	}
+	if err := f.compact(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// refresh applies the entries appended to the log since it was last read, or replays it from the start if it
+// was replaced by a compaction since. It must be called with the lock held.
+func (f *File) refresh() error {
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		f.reset(nil)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if f.log == nil || !os.SameFile(f.log, info) || info.Size() < f.offset {
+		f.reset(info)
+	}
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			// the rest is the last write, interrupted before its newline
+			return nil
+		}
+		line := data[:i]
+		data = data[i+1:]
+		f.offset += int64(i + 1)
+		f.line++
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		// decode numbers in params as json.Number, so that they are written back unchanged
+		var e entry
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		if err := dec.Decode(&e); err != nil {
+			return fmt.Errorf("%s:%d: invalid entry: %v", f.path, f.line, err)
+		}
+		switch {
+		case e.Op == PUT && e.Record != nil:
+			_ = f.memory.Put(ctx, *e.Record)
+		case e.Op == DELETE:
+			_ = f.memory.Delete(ctx, e.Game, e.Monitor)
+		default:
+			return fmt.Errorf("%s:%d: invalid entry %q", f.path, f.line, e.Op)
+		}
+	}
+}
+
+// reset forgets the records read from the log, to replay log from the start.
+func (f *File) reset(log os.FileInfo) {
+	f.memory = NewMemory()
+	f.log = log
+	f.offset = 0
+	f.line = 0
+}
+
+// compact rewrites the log with a put for every record, replacing the old log atomically with a file of the same
+// mode. The directory is synced after the rename, so that a crash cannot bring the old log back. It must be
+// called with the exclusive lock held.
+func (f *File) compact() error {
+	records, _ := f.memory.All(context.Background())
+	var buf bytes.Buffer
+	for i := range records {
+		if err := writeEntry(&buf, entry{Op: PUT, Record: &records[i]}); err != nil {
+			return err
+		}
+	}
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(f.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	// CreateTemp creates the file readable by its owner only
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return err
+	}
+	f.log, f.offset, f.line = info, int64(buf.Len()), len(records)
+	return syncDir(filepath.Dir(f.path))
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+func writeEntry(w io.Writer, e entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// append writes an entry to the log and syncs it, after applying the entries other stores appended. A line
+// left incomplete by a crash is cut off first, so that the entry starts on a line of its own.
+func (f *File) append(e entry) error {
+	if f.closed {
+		return os.ErrClosed
+	}
+	lock, err := lockFile(f.path+".lock", true)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := f.refresh(); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := file.Truncate(f.offset); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeEntry(&buf, e); err != nil {
+		return err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	f.log, f.offset, f.line = info, f.offset+int64(buf.Len()), f.line+1
+	return nil
+}
+
+// read returns the memory store once the entries other stores appended have been applied to it. A closed store
+// is not refreshed.
+func (f *File) read() (*Memory, error) {
+	if f.closed {
+		return f.memory, nil
+	}
+	lock, err := lockFile(f.path+".lock", false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Close()
+	if err := f.refresh(); err != nil {
+		return nil, err
+	}
+	return f.memory, nil
+}
+
+func (f *File) Put(ctx context.Context, record Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.append(entry{Op: PUT, Record: &record}); err != nil {
+		return err
+	}
+	return f.memory.Put(ctx, record)
+}
+
+func (f *File) Delete(ctx context.Context, game, monitor string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.append(entry{Op: DELETE, Game: game, Monitor: monitor}); err != nil {
+		return err
+	}
+	return f.memory.Delete(ctx, game, monitor)
+}
+
+func (f *File) Get(ctx context.Context, game, monitor string) (Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	memory, err := f.read()
+	if err != nil {
+		return Record{}, err
+	}
+	return memory.Get(ctx, game, monitor)
+}
+
+func (f *File) Game(ctx context.Context, game string) ([]Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	memory, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	return memory.Game(ctx, game)
+}
+
+func (f *File) All(ctx context.Context) ([]Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	memory, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	return memory.All(ctx)
+}
+
+func (f *File) Outdated(ctx context.Context, hashes map[string]string) ([]Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	memory, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	return memory.Outdated(ctx, hashes)
+}
+
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package state
+
+import "os"
+
+// lockFile opens the lock file at path. Files are not locked on this platform, so the caller must make sure
+// a single store writes the log.
+func lockFile(path string, exclusive bool) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+}
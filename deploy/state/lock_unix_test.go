@@ -0,0 +1,84 @@
+//go:build unix
+
+package state
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileShared(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "deployments.jsonl")
+	serve, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("Error opening store: %v", err)
+	}
+	defer serve.Close()
+
+	// We expect a second store on the same log to open while the first has it open, and to see its writes
+	reap, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("Error opening a second store: %v", err)
+	}
+	defer reap.Close()
+	if err := serve.Put(ctx, record(GAME_A, "eth_deficit", "new", 1)); err != nil {
+		t.Fatalf("Error writing record: %v", err)
+	}
+	if err := serve.Put(ctx, record(GAME_B, "eth_deficit", "new", 2)); err != nil {
+		t.Fatalf("Error writing record: %v", err)
+	}
+	if records, _ := reap.All(ctx); len(records) != 2 {
+		t.Errorf("Expected the second store to see 2 records, got %d", len(records))
+	}
+
+	// We expect deletes of the second store to be seen by the first
+	if err := reap.Delete(ctx, GAME_A, "eth_deficit"); err != nil {
+		t.Fatalf("Error deleting record: %v", err)
+	}
+	if _, err := serve.Get(ctx, GAME_A, "eth_deficit"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the deleted record to be gone from the first store, got %v", err)
+	}
+
+	// We expect a store opened later to compact the log under the others, and writes to continue on the
+	// compacted log
+	compacting, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("Error opening a third store: %v", err)
+	}
+	compacting.Close()
+	if err := serve.Put(ctx, record(GAME_A, "challenger_loses", "new", 3)); err != nil {
+		t.Fatalf("Error writing record after compaction: %v", err)
+	}
+	records, _ := reap.All(ctx)
+	if len(records) != 2 || records[0].MonitorId != 2 || records[1].MonitorId != 3 {
+		t.Errorf("Expected records 2 and 3 after compaction, got %v", records)
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployments.jsonl")
+	if err := os.WriteFile(path, nil, 0o640); err != nil {
+		t.Fatalf("Error writing log: %v", err)
+	}
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatalf("Error changing mode: %v", err)
+	}
+
+	// We expect compacting the log to keep its mode
+	s, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("Error opening store: %v", err)
+	}
+	s.Close()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Error reading file %s: %v", path, err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("Expected mode 0640 after compaction, got %v", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,156 @@
+// Package state records which Hexagate monitors have been deployed to which dispute games, so that deployers
+// can find the monitors of a game again to update or tear them down, and find the deployments running an
+// outdated version of a monitor.
+package state
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned for deployments that are not recorded.
+var ErrNotFound = errors.New("deployment not found")
+
+// Record is a monitor deployed to a dispute game. A game has at most one record per monitor.
+type Record struct {
+	Game         string `json:"game"`
+	GameType     int    `json:"game_type"`
+	RootClaim    string `json:"root_claim,omitempty"`
+	CreatedBlock uint64 `json:"created_block,omitempty"`
+
+	// Monitor is the name of the monitor in the registry, e.g. eth_deficit, and GateHash the hash of its source
+	// when it was deployed.
+	Monitor  string         `json:"monitor"`
+	GateHash string         `json:"gate_hash"`
+	Params   map[string]any `json:"params"`
+
+	// MonitorId is the ID of the deployed monitor in Hexagate.
+	MonitorId  int       `json:"monitor_id"`
+	DeployedAt time.Time `json:"deployed_at"`
+}
+
+// Store records deployments. Implementations must be safe for concurrent use. Games are compared regardless of
+// the case of their address.
+type Store interface {
+	// Put records a deployment, replacing the record of the same game and monitor.
+	Put(ctx context.Context, record Record) error
+
+	// Get returns the record of monitor on game, or ErrNotFound.
+	Get(ctx context.Context, game, monitor string) (Record, error)
+
+	// Delete removes the record of monitor on game. Deleting a record that does not exist is not an error.
+	Delete(ctx context.Context, game, monitor string) error
+
+	// Game returns the records of a game sorted by monitor.
+	Game(ctx context.Context, game string) ([]Record, error)
+
+	// All returns every record sorted by game and monitor.
+	All(ctx context.Context) ([]Record, error)
+
+	// Outdated returns the records whose gate hash differs from the current hash of their monitor, sorted by
+	// game and monitor. hashes maps monitor names to their current hash; monitors missing from it are skipped.
+	Outdated(ctx context.Context, hashes map[string]string) ([]Record, error)
+
+	Close() error
+}
+
+// Games returns the distinct games of records in sorted order, e.g. the games with an outdated monitor.
+func Games(records []Record) []string {
+	var games []string
+	seen := make(map[string]bool)
+	for _, r := range records {
+		if key := strings.ToLower(r.Game); !seen[key] {
+			seen[key] = true
+			games = append(games, r.Game)
+		}
+	}
+	sort.Slice(games, func(i, j int) bool { return strings.ToLower(games[i]) < strings.ToLower(games[j]) })
+	return games
+}
+
+type key struct {
+	game, monitor string
+}
+
+func keyOf(game, monitor string) key {
+	return key{strings.ToLower(game), monitor}
+}
+
+// Memory is a Store that keeps records in memory only.
+type Memory struct {
+	mu      sync.RWMutex
+	records map[key]Record
+}
+
+// NewMemory creates an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{records: make(map[key]Record)}
+}
+
+func (m *Memory) Put(ctx context.Context, record Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[keyOf(record.Game, record.Monitor)] = record
+	return nil
+}
+
+func (m *Memory) Get(ctx context.Context, game, monitor string) (Record, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	record, ok := m.records[keyOf(game, monitor)]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return record, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, game, monitor string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, keyOf(game, monitor))
+	return nil
+}
+
+func (m *Memory) Game(ctx context.Context, game string) ([]Record, error) {
+	game = strings.ToLower(game)
+	return m.filter(func(r Record) bool { return strings.ToLower(r.Game) == game }), nil
+}
+
+func (m *Memory) All(ctx context.Context) ([]Record, error) {
+	return m.filter(func(Record) bool { return true }), nil
+}
+
+func (m *Memory) Outdated(ctx context.Context, hashes map[string]string) ([]Record, error) {
+	return m.filter(func(r Record) bool {
+		hash, ok := hashes[r.Monitor]
+		return ok && hash != r.GateHash
+	}), nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+// filter returns the records matching keep, sorted by game and monitor.
+func (m *Memory) filter(keep func(Record) bool) []Record {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	records := []Record{}
+	for _, r := range m.records {
+		if keep(r) {
+			records = append(records, r)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := keyOf(records[i].Game, records[i].Monitor), keyOf(records[j].Game, records[j].Monitor)
+		if a.game != b.game {
+			return a.game < b.game
+		}
+		return a.monitor < b.monitor
+	})
+	return records
+}
@@ -0,0 +1,176 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	GAME_A = "0x8D8f2bD3e2B4BC0ae1D4C6B1C2c1E6A5c3f7D9e1"
+	GAME_B = "0x49277EE36A024120Ee218127354c4a3591dc90A9"
+)
+
+func record(game, monitor, hash string, id int) Record {
+	return Record{
+		Game:         game,
+		GameType:     0,
+		RootClaim:    "0x6f2a4d5c3b1e0f9a8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e0d9c8b7a6f5e",
+		CreatedBlock: 21874230,
+		Monitor:      monitor,
+		GateHash:     hash,
+		Params:       map[string]any{"disputeGame": game, "extraTimeInSeconds": json.Number("3600")},
+		MonitorId:    id,
+		DeployedAt:   time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// testStore runs the queries every Store must answer the same way.
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+	ctx := context.Background()
+	for _, r := range []Record{
+		record(GAME_A, "eth_deficit", "old", 1),
+		record(GAME_A, "challenger_loses", "new", 2),
+		record(GAME_B, "eth_deficit", "new", 3),
+		record(GAME_B, "fault_proof_detection_child", "old", 4),
+	} {
+		if err := s.Put(ctx, r); err != nil {
+			t.Fatalf("Error putting record: %v", err)
+		}
+	}
+
+	// We expect a game's records sorted by monitor, regardless of the case of the address
+	records, err := s.Game(ctx, strings.ToLower(GAME_A))
+	if err != nil {
+		t.Fatalf("Error querying game: %v", err)
+	}
+	if len(records) != 2 || records[0].Monitor != "challenger_loses" || records[1].Monitor != "eth_deficit" {
+		t.Errorf("Expected the 2 monitors of game A sorted, got %v", records)
+	}
+
+	// We expect only records with a different hash for a known monitor to be outdated
+	outdated, err := s.Outdated(ctx, map[string]string{"eth_deficit": "new", "challenger_loses": "new"})
+	if err != nil {
+		t.Fatalf("Error querying outdated records: %v", err)
+	}
+	if len(outdated) != 1 || outdated[0].Game != GAME_A || outdated[0].MonitorId != 1 {
+		t.Errorf("Expected eth_deficit on game A to be outdated, got %v", outdated)
+	}
+	outdated, _ = s.Outdated(ctx, map[string]string{"eth_deficit": "newer", "fault_proof_detection_child": "new"})
+	if games := Games(outdated); len(games) != 2 || games[0] != GAME_B || games[1] != GAME_A {
+		t.Errorf("Expected both games to have outdated monitors, got %v", games)
+	}
+
+	// We expect a put to replace the record of the same game and monitor
+	if err := s.Put(ctx, record(strings.ToLower(GAME_A), "eth_deficit", "new", 5)); err != nil {
+		t.Fatalf("Error putting record: %v", err)
+	}
+	got, err := s.Get(ctx, GAME_A, "eth_deficit")
+	if err != nil || got.MonitorId != 5 || got.GateHash != "new" {
+		t.Errorf("Expected the replaced record, got %v, %v", got, err)
+	}
+
+	// We expect deleted records to be gone, and deleting twice not to fail
+	if err := s.Delete(ctx, GAME_B, "eth_deficit"); err != nil {
+		t.Fatalf("Error deleting record: %v", err)
+	}
+	if err := s.Delete(ctx, GAME_B, "eth_deficit"); err != nil {
+		t.Errorf("Expected deleting a missing record not to fail, got %v", err)
+	}
+	if _, err := s.Get(ctx, GAME_B, "eth_deficit"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	all, _ := s.All(ctx)
+	if len(all) != 3 {
+		t.Errorf("Expected 3 records, got %d", len(all))
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testStore(t, NewMemory())
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployments.jsonl")
+	s, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("Error opening store: %v", err)
+	}
+	testStore(t, s)
+	want, _ := s.All(context.Background())
+	if err := s.Close(); err != nil {
+		t.Fatalf("Error closing store: %v", err)
+	}
+	if err := s.Put(context.Background(), record(GAME_A, "eth_deficit", "new", 6)); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected a closed store to reject writes, got %v", err)
+	}
+
+	// We expect the store to be replayed from the log when it is reopened, and the log to be compacted
+	s, err = OpenFile(path)
+	if err != nil {
+		t.Fatalf("Error reopening store: %v", err)
+	}
+	defer s.Close()
+	got, _ := s.All(context.Background())
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d records after reopening, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Game != want[i].Game || got[i].MonitorId != want[i].MonitorId || !got[i].DeployedAt.Equal(want[i].DeployedAt) {
+			t.Errorf("Expected record %v, got %v", want[i], got[i])
+		}
+		if got[i].Params["extraTimeInSeconds"] != json.Number("3600") {
+			t.Errorf("Expected integer params to be kept as numbers, got %v", got[i].Params)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading file %s: %v", path, err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != len(want) {
+		t.Errorf("Expected the log to be compacted to %d lines, got %d", len(want), lines)
+	}
+}
+
+func TestFileRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployments.jsonl")
+	put := `{"op":"put","record":{"game":"` + GAME_A + `","monitor":"eth_deficit","gate_hash":"new","monitor_id":1}}`
+
+	// We expect a last line cut short by a crash to be dropped
+	if err := os.WriteFile(path, []byte(put+"\n"+`{"op":"put","rec`), 0o644); err != nil {
+		t.Fatalf("Error writing log: %v", err)
+	}
+	s, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("Error opening store: %v", err)
+	}
+	records, _ := s.All(context.Background())
+	s.Close()
+	if len(records) != 1 || records[0].MonitorId != 1 {
+		t.Errorf("Expected the complete record only, got %v", records)
+	}
+
+	// We expect a corrupt line in the middle of the log to fail opening the store with its line number
+	if err := os.WriteFile(path, []byte(put+"\n"+"not json\n"+put+"\n"), 0o644); err != nil {
+		t.Fatalf("Error writing log: %v", err)
+	}
+	if _, err := OpenFile(path); err == nil || !strings.Contains(err.Error(), ":2: invalid entry") {
+		t.Errorf("Expected an invalid entry error on line 2, got %v", err)
+	}
+
+	// We expect a store that failed to open to release the lock
+	if err := os.WriteFile(path, []byte(put+"\n"), 0o644); err != nil {
+		t.Fatalf("Error writing log: %v", err)
+	}
+	s, err = OpenFile(path)
+	if err != nil {
+		t.Fatalf("Error opening store after a failed open: %v", err)
+	}
+	s.Close()
+}
@@ -0,0 +1,139 @@
+package deploy
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	// SECRET_HEADER carries the shared secret webhook requests are authenticated with.
+	SECRET_HEADER = "X-Webhook-Secret"
+
+	// GAME_CREATED_EVENT is the DisputeGameFactory event emitted for every new game.
+	GAME_CREATED_EVENT = "DisputeGameCreated"
+
+	// GAME_CREATED_FIELD is the DisputeGameCreated event argument holding the address of the new game.
+	GAME_CREATED_FIELD = "disputeProxy"
+
+	// MAX_ALERT_BODY is the largest webhook body that is read.
+	MAX_ALERT_BODY = 1 << 20
+)
+
+// WebhookOptions configures the webhook handlers.
+type WebhookOptions struct {
+	// Secret, if set, must be sent in SECRET_HEADER by every request.
+	Secret string
+
+	// Logger logs every alert handled, or slog.Default() if nil.
+	Logger *slog.Logger
+}
+
+// Response is the body the webhook handlers answer with: the deployment to the game of the alert, the reason
+// the alert was ignored, or the error that failed it.
+type Response struct {
+	*Deployment
+	Ignored string `json:"ignored,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// GameCreatedHandler returns a handler for the webhook of a Contract Event monitor on the DisputeGameCreated
+// events of the DisputeGameFactory. It deploys every Per DisputeGame monitor to the disputeProxy of the event.
+// Alerts of other events, or without an event, are ignored.
+func (d *Deployer) GameCreatedHandler(opts WebhookOptions) http.Handler {
+	return webhook(opts, d.chainId(), GAME_CREATED_FIELD, ignoreGameCreated, d.DeployGame)
+}
+
+func ignoreGameCreated(alert *Alert) string {
+	if alert.Event == nil {
+		return fmt.Sprintf("alert %q has no %s event", alert.Description, GAME_CREATED_EVENT)
+	}
+	if alert.Event.Name != GAME_CREATED_EVENT {
+		return fmt.Sprintf("event %s is not %s", alert.Event.Name, GAME_CREATED_EVENT)
+	}
+	return ""
+}
+
+// webhook returns a handler that reads the game under field from every alert on chainId and deploys to it. A
+// non-nil ignore returns why an alert should not be deployed from, or "". Alerts that cannot be handled, or
+// that are for another chain than the monitors are deployed on, are
+// answered with a 4xx status, invalid params with 500 Internal Server Error, and failures to reach the
+// management API with 502 Bad Gateway so that the sender retries them. Deployments that create monitors are
+// answered with 201 Created, and repeated ones with 200 OK.
+func webhook(opts WebhookOptions, chainId int, field string, ignore func(alert *Alert) string, deploy func(ctx context.Context, game Game) (*Deployment, error)) http.Handler {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeResponse(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+			return
+		}
+		if opts.Secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(SECRET_HEADER)), []byte(opts.Secret)) != 1 {
+			writeResponse(w, http.StatusUnauthorized, Response{Error: "invalid webhook secret"})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, MAX_ALERT_BODY))
+		if err != nil {
+			writeResponse(w, http.StatusBadRequest, Response{Error: err.Error()})
+			return
+		}
+		alert, err := ParseAlert(body)
+		if err != nil {
+			writeResponse(w, http.StatusBadRequest, Response{Error: err.Error()})
+			return
+		}
+		if alert.ChainId != chainId {
+			err := fmt.Errorf("alert is for chain %d, monitors are deployed on chain %d", alert.ChainId, chainId)
+			logger.Warn("rejecting alert", "monitor", alert.MonitorName, "tx", alert.TxHash, "error", err)
+			writeResponse(w, http.StatusUnprocessableEntity, Response{Error: err.Error()})
+			return
+		}
+		if ignore != nil {
+			if reason := ignore(alert); reason != "" {
+				logger.Info("ignoring alert", "monitor", alert.MonitorName, "tx", alert.TxHash, "reason", reason)
+				writeResponse(w, http.StatusOK, Response{Ignored: reason})
+				return
+			}
+		}
+		game, err := alert.Game(field)
+		if err != nil {
+			logger.Warn("rejecting alert", "monitor", alert.MonitorName, "tx", alert.TxHash, "error", err)
+			writeResponse(w, http.StatusUnprocessableEntity, Response{Error: err.Error()})
+			return
+		}
+
+		deployment, err := deploy(r.Context(), game)
+		if err != nil {
+			logger.Error("deployment failed", "game", game.Address, "error", err)
+			var paramsErr *ParamsError
+			if errors.As(err, &paramsErr) {
+				writeResponse(w, http.StatusInternalServerError, Response{Deployment: deployment, Error: err.Error()})
+			} else {
+				writeResponse(w, http.StatusBadGateway, Response{Deployment: deployment, Error: err.Error()})
+			}
+			return
+		}
+		logger.Info("deployed monitors", "game", game.Address, "monitors", len(deployment.Monitors), "created", deployment.Created, "tx", alert.TxHash)
+		if deployment.Created > 0 {
+			writeResponse(w, http.StatusCreated, Response{Deployment: deployment})
+		} else {
+			writeResponse(w, http.StatusOK, Response{Deployment: deployment})
+		}
+	})
+}
+
+func writeResponse(w http.ResponseWriter, status int, response Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(response)
+}
@@ -0,0 +1,181 @@
+// Package disputegame builds mocks of fault dispute game data for validate requests. Each type encodes to the
+// exact tuple shape a monitor source reads from the chain, so tests do not have to remember the field order
+// of claimData or Move events.
+package disputegame
+
+import (
+	"math/big"
+)
+
+const (
+	// ROOT_PARENT_INDEX is the parent index of the root claim, type(uint32).max
+	ROOT_PARENT_INDEX = 4294967295
+
+	// ZERO_ADDRESS is the counteredBy address of a claim that has not been countered
+	ZERO_ADDRESS = "0x0000000000000000000000000000000000000000"
+
+	// ZERO_HASH is the default claim hash
+	ZERO_HASH = "0x0000000000000000000000000000000000000000000000000000000000000000"
+)
+
+// GameStatus is the status of a dispute game, as emitted in the Resolved event.
+type GameStatus uint8
+
+const (
+	IN_PROGRESS GameStatus = iota
+	CHALLENGER_WINS
+	DEFENDER_WINS
+)
+
+// Mock is implemented by every mocked value, and returns it as the tuple a source reads.
+type Mock interface {
+	Mock() []any
+}
+
+// List encodes values as the list of tuples a list source reads.
+func List[T Mock](values ...T) [][]any {
+	list := make([][]any, len(values))
+	for i, value := range values {
+		list[i] = value.Mock()
+	}
+	return list
+}
+
+// ClaimData is a claim as returned by FaultDisputeGame.claimData:
+// (uint32 parentIndex, address counteredBy, address claimant, uint128 bond, bytes32 claim, uint128 position, uint128 clock)
+type ClaimData struct {
+	ParentIndex uint32
+	CounteredBy string
+	Claimant    string
+	Bond        *big.Int
+	Claim       string
+	Position    *big.Int
+	Clock       uint64
+}
+
+func (c ClaimData) Mock() []any {
+	return []any{c.ParentIndex, address(c.CounteredBy), address(c.Claimant), integer(c.Bond), hash(c.Claim), integer(c.Position), c.Clock}
+}
+
+// MoveEvent is a Move(uint256 indexed parentIndex, bytes32 indexed claim, address indexed claimant) event.
+type MoveEvent struct {
+	ParentIndex uint64
+	Claim       string
+	Claimant    string
+}
+
+func (e MoveEvent) Mock() []any {
+	return []any{e.ParentIndex, hash(e.Claim), address(e.Claimant)}
+}
+
+// ResolvedEvent is a Resolved(uint8 indexed status) event.
+type ResolvedEvent struct {
+	Status GameStatus
+}
+
+func (e ResolvedEvent) Mock() []any {
+	return []any{uint8(e.Status)}
+}
+
+// DisputeGameCreatedEvent is a DisputeGameCreated(address indexed disputeProxy, uint32 indexed gameType,
+// bytes32 indexed rootClaim) event. Sources that read it with HistoricalEvents and withBlocks also read the
+// block it was emitted in.
+type DisputeGameCreatedEvent struct {
+	Block        uint64
+	DisputeProxy string
+	GameType     uint32
+	RootClaim    string
+}
+
+func (e DisputeGameCreatedEvent) Mock() []any {
+	return []any{address(e.DisputeProxy), e.GameType, hash(e.RootClaim)}
+}
+
+// WithBlock returns the event as read with withBlocks: (block, (disputeProxy, gameType, rootClaim)).
+func (e DisputeGameCreatedEvent) WithBlock() []any {
+	return []any{e.Block, e.Mock()}
+}
+
+// UnlockCall is a DelayedWETH unlock(address _guy, uint256 _wad) call. Sources that read it with
+// HistoricalCalls and withSender also read the caller, the dispute game, and with withBlocks the block.
+type UnlockCall struct {
+	Block  uint64
+	Sender string
+	Guy    string
+	Wad    *big.Int
+}
+
+func (c UnlockCall) Mock() []any {
+	return []any{address(c.Guy), integer(c.Wad)}
+}
+
+// WithSender returns the call as read with withSender: (sender, (guy, wad)).
+func (c UnlockCall) WithSender() []any {
+	return []any{address(c.Sender), c.Mock()}
+}
+
+// WithBlockAndSender returns the call as read with withBlocks and withSender: (block, sender, (guy, wad)).
+func (c UnlockCall) WithBlockAndSender() []any {
+	return []any{c.Block, address(c.Sender), c.Mock()}
+}
+
+// WithdrawalCall is a DelayedWETH withdraw(address _guy, uint256 _wad) call.
+type WithdrawalCall struct {
+	Guy string
+	Wad *big.Int
+}
+
+func (c WithdrawalCall) Mock() []any {
+	return []any{address(c.Guy), integer(c.Wad)}
+}
+
+// WithSenders encodes unlock calls as read with withSender.
+func WithSenders(calls ...UnlockCall) [][]any {
+	list := make([][]any, len(calls))
+	for i, call := range calls {
+		list[i] = call.WithSender()
+	}
+	return list
+}
+
+// WithBlocksAndSenders encodes unlock calls as read with withBlocks and withSender.
+func WithBlocksAndSenders(calls ...UnlockCall) [][]any {
+	list := make([][]any, len(calls))
+	for i, call := range calls {
+		list[i] = call.WithBlockAndSender()
+	}
+	return list
+}
+
+// WithBlocks encodes DisputeGameCreated events as read with withBlocks.
+func WithBlocks(events ...DisputeGameCreatedEvent) [][]any {
+	list := make([][]any, len(events))
+	for i, event := range events {
+		list[i] = event.WithBlock()
+	}
+	return list
+}
+
+// address defaults an unset address to the zero address.
+func address(a string) string {
+	if a == "" {
+		return ZERO_ADDRESS
+	}
+	return a
+}
+
+// hash defaults an unset bytes32 value to the zero hash.
+func hash(h string) string {
+	if h == "" {
+		return ZERO_HASH
+	}
+	return h
+}
+
+// integer defaults an unset integer to zero.
+func integer(n *big.Int) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	return n
+}
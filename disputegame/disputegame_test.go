@@ -0,0 +1,121 @@
+package disputegame
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+const (
+	PROPOSER   = "0x49277EE36A024120Ee218127354c4a3591dc90A9"
+	CHALLENGER = "0xc96775081bcA132B0E7cbECDd0B58d9Ec07Fdaa4"
+	GAME       = "0x00000000000000000000000000000000000000AA"
+)
+
+func encode(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Error encoding %v: %v", v, err)
+	}
+	return string(data)
+}
+
+func TestMockShapes(t *testing.T) {
+	// We expect every mock to encode to the tuple shape of the source that reads it
+	for _, test := range []struct {
+		mock     any
+		expected string
+	}{
+		{
+			ClaimData{ParentIndex: ROOT_PARENT_INDEX, Claimant: PROPOSER, Bond: big.NewInt(1), Claim: "0x01", Position: big.NewInt(1), Clock: 123456}.Mock(),
+			`[4294967295,"0x0000000000000000000000000000000000000000","` + PROPOSER + `",1,"0x01",1,123456]`,
+		},
+		{
+			MoveEvent{ParentIndex: 0, Claimant: CHALLENGER}.Mock(),
+			`[0,"` + ZERO_HASH + `","` + CHALLENGER + `"]`,
+		},
+		{
+			ResolvedEvent{Status: CHALLENGER_WINS}.Mock(),
+			`[1]`,
+		},
+		{
+			DisputeGameCreatedEvent{Block: 10, DisputeProxy: GAME, GameType: 0, RootClaim: "0x01"}.WithBlock(),
+			`[10,["` + GAME + `",0,"0x01"]]`,
+		},
+		{
+			UnlockCall{Block: 50, Sender: GAME, Guy: CHALLENGER, Wad: big.NewInt(50)}.WithBlockAndSender(),
+			`[50,"` + GAME + `",["` + CHALLENGER + `",50]]`,
+		},
+		{
+			WithSenders(UnlockCall{Sender: GAME, Guy: CHALLENGER, Wad: big.NewInt(50)}),
+			`[["` + GAME + `",["` + CHALLENGER + `",50]]]`,
+		},
+		{
+			List(WithdrawalCall{Guy: CHALLENGER, Wad: big.NewInt(100)}),
+			`[["` + CHALLENGER + `",100]]`,
+		},
+	} {
+		if got := encode(t, test.mock); got != test.expected {
+			t.Errorf("Unexpected mock:\n got %s\nwant %s", got, test.expected)
+		}
+	}
+}
+
+func TestGame(t *testing.T) {
+	// We expect moves to get the parent index and position the FaultDisputeGame would give them
+	game := NewGame().
+		Bond(big.NewInt(1)).
+		Root(PROPOSER).
+		Attack(0, CHALLENGER).
+		Defend(1, PROPOSER).
+		Attack(2, CHALLENGER).
+		Counter(0, CHALLENGER)
+
+	claims := game.Claims()
+	if game.ClaimCount() != 4 {
+		t.Fatalf("Expected 4 claims, got %d", game.ClaimCount())
+	}
+	for i, expected := range []struct {
+		parent   uint32
+		position int64
+		claimant string
+	}{
+		{ROOT_PARENT_INDEX, 1, PROPOSER},
+		{0, 2, CHALLENGER},
+		{1, 5, PROPOSER},
+		{2, 10, CHALLENGER},
+	} {
+		claim := claims[i]
+		if claim.ParentIndex != expected.parent || claim.Position.Int64() != expected.position || claim.Claimant != expected.claimant {
+			t.Errorf("Unexpected claim %d: %+v", i, claim)
+		}
+	}
+	if claims[0].CounteredBy != CHALLENGER {
+		t.Errorf("Expected the root claim to be countered by %s, got %s", CHALLENGER, claims[0].CounteredBy)
+	}
+
+	// the root claim is made when the game is created and does not emit a Move event
+	expected := `[[0,"` + ZERO_HASH + `","` + CHALLENGER + `"],[1,"` + ZERO_HASH + `","` + PROPOSER + `"],[2,"` + ZERO_HASH + `","` + CHALLENGER + `"]]`
+	if got := encode(t, game.MoveEvents()); got != expected {
+		t.Errorf("Unexpected move events:\n got %s\nwant %s", got, expected)
+	}
+}
+
+func TestGameRejectsInvalidMoves(t *testing.T) {
+	// We expect moves the FaultDisputeGame would reject to panic
+	for name, build := range map[string]func(){
+		"no root":        func() { NewGame().Attack(0, CHALLENGER) },
+		"defend root":    func() { NewGame().Root(PROPOSER).Defend(0, CHALLENGER) },
+		"missing parent": func() { NewGame().Root(PROPOSER).Attack(1, CHALLENGER) },
+		"second root":    func() { NewGame().Root(PROPOSER).Root(PROPOSER) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected %s to panic", name)
+				}
+			}()
+			build()
+		}()
+	}
+}
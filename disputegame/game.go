@@ -0,0 +1,115 @@
+package disputegame
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Game builds the claims of a dispute game move by move, so that parent indices, positions and Move events
+// stay consistent with each other:
+//
+//	game := disputegame.NewGame().
+//		Root(proposer).
+//		Attack(0, challenger).
+//		Defend(1, proposer).
+//		Counter(0, challenger)
+//
+// The builder panics on moves the FaultDisputeGame would reject, as those are mistakes in the test itself.
+type Game struct {
+	claims []ClaimData
+	bond   *big.Int
+}
+
+// NewGame returns an empty game. Root must be called before any move is made.
+func NewGame() *Game {
+	return &Game{}
+}
+
+// Bond sets the bond of the claims made after it.
+func (g *Game) Bond(bond *big.Int) *Game {
+	g.bond = bond
+	return g
+}
+
+// Root makes the root claim of the game, proposed by proposer.
+func (g *Game) Root(proposer string) *Game {
+	if len(g.claims) > 0 {
+		panic("disputegame: root claim already made")
+	}
+	g.claims = append(g.claims, ClaimData{
+		ParentIndex: ROOT_PARENT_INDEX,
+		Claimant:    proposer,
+		Bond:        g.bond,
+		Position:    big.NewInt(1),
+	})
+	return g
+}
+
+// Attack makes a claim by claimant that attacks the claim at index parent.
+func (g *Game) Attack(parent int, claimant string) *Game {
+	return g.move(parent, claimant, true)
+}
+
+// Defend makes a claim by claimant that defends the claim at index parent.
+func (g *Game) Defend(parent int, claimant string) *Game {
+	if parent == 0 {
+		panic("disputegame: the root claim cannot be defended")
+	}
+	return g.move(parent, claimant, false)
+}
+
+// Counter marks the claim at index as countered by the given address, as done when its subgame is resolved.
+func (g *Game) Counter(index int, by string) *Game {
+	g.claim(index).CounteredBy = by
+	return g
+}
+
+func (g *Game) move(parent int, claimant string, attack bool) *Game {
+	// an attack moves to the left child of the parent position and a defense to the right one
+	position := new(big.Int).Lsh(g.claim(parent).Position, 1)
+	if !attack {
+		position.Add(position, big.NewInt(1))
+	}
+	g.claims = append(g.claims, ClaimData{
+		ParentIndex: uint32(parent),
+		Claimant:    claimant,
+		Bond:        g.bond,
+		Position:    position,
+	})
+	return g
+}
+
+func (g *Game) claim(index int) *ClaimData {
+	if len(g.claims) == 0 {
+		panic("disputegame: no root claim, call Root first")
+	}
+	if index < 0 || index >= len(g.claims) {
+		panic(fmt.Sprintf("disputegame: no claim at index %d, the game has %d claims", index, len(g.claims)))
+	}
+	return &g.claims[index]
+}
+
+// Claims returns the claims of the game in the order they were made.
+func (g *Game) Claims() []ClaimData {
+	return append([]ClaimData(nil), g.claims...)
+}
+
+// ClaimCount returns the number of claims, including the root claim, as returned by claimDataLen.
+func (g *Game) ClaimCount() int {
+	return len(g.claims)
+}
+
+// ClaimData returns the claims as read by a claimData source.
+func (g *Game) ClaimData() [][]any {
+	return List(g.claims...)
+}
+
+// MoveEvents returns the Move events emitted by the moves of the game. The root claim is made when the game
+// is created and does not emit one.
+func (g *Game) MoveEvents() [][]any {
+	var events []MoveEvent
+	for _, claim := range g.claims[min(1, len(g.claims)):] {
+		events = append(events, MoveEvent{ParentIndex: uint64(claim.ParentIndex), Claim: claim.Claim, Claimant: claim.Claimant})
+	}
+	return List(events...)
+}
@@ -0,0 +1,192 @@
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Contract holds the functions and events of a contract ABI, such as the FaultDisputeGame ABI from the
+// contracts-bedrock forge artifacts.
+type Contract struct {
+	Name      string
+	Functions map[string][]*Signature
+	Events    map[string][]*Signature
+}
+
+type jsonParam struct {
+	Name       string      `json:"name"`
+	Type       string      `json:"type"`
+	Indexed    bool        `json:"indexed"`
+	Components []jsonParam `json:"components"`
+}
+
+type jsonEntry struct {
+	Type            string      `json:"type"`
+	Name            string      `json:"name"`
+	Inputs          []jsonParam `json:"inputs"`
+	Outputs         []jsonParam `json:"outputs"`
+	StateMutability string      `json:"stateMutability"`
+	Anonymous       bool        `json:"anonymous"`
+}
+
+// LoadContract decodes an ABI JSON document. Both a bare ABI array and a forge artifact with an "abi" key
+// are accepted.
+func LoadContract(name string, data []byte) (*Contract, error) {
+	var entries []jsonEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		var artifact struct {
+			ABI []jsonEntry `json:"abi"`
+		}
+		if artifactErr := json.Unmarshal(data, &artifact); artifactErr != nil || artifact.ABI == nil {
+			return nil, fmt.Errorf("error decoding %s ABI: %w", name, err)
+		}
+		entries = artifact.ABI
+	}
+
+	contract := &Contract{
+		Name:      name,
+		Functions: make(map[string][]*Signature),
+		Events:    make(map[string][]*Signature),
+	}
+	for _, entry := range entries {
+		if entry.Type != "function" && entry.Type != "event" {
+			continue
+		}
+		sig := &Signature{Event: entry.Type == "event", Name: entry.Name}
+		var err error
+		if sig.Inputs, err = fromJSON(entry.Inputs); err != nil {
+			return nil, fmt.Errorf("error decoding %s.%s: %w", name, entry.Name, err)
+		}
+		if sig.Outputs, err = fromJSON(entry.Outputs); err != nil {
+			return nil, fmt.Errorf("error decoding %s.%s: %w", name, entry.Name, err)
+		}
+
+		if sig.Event {
+			if entry.Anonymous {
+				sig.Modifiers = []string{"anonymous"}
+			}
+			contract.Events[sig.Name] = append(contract.Events[sig.Name], sig)
+		} else {
+			if entry.StateMutability != "" && entry.StateMutability != "nonpayable" {
+				sig.Modifiers = []string{entry.StateMutability}
+			}
+			contract.Functions[sig.Name] = append(contract.Functions[sig.Name], sig)
+		}
+	}
+	return contract, nil
+}
+
+// LoadContracts loads every *.json ABI in dir, naming each contract after its file.
+func LoadContracts(dir string) ([]*Contract, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var contracts []*Contract
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		contract, err := LoadContract(strings.TrimSuffix(filepath.Base(path), ".json"), data)
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, contract)
+	}
+	return contracts, nil
+}
+
+func fromJSON(params []jsonParam) ([]Param, error) {
+	var result []Param
+	for _, p := range params {
+		typ, err := jsonType(p.Type, p.Components)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Param{Name: p.Name, Type: typ, Indexed: p.Indexed})
+	}
+	return result, nil
+}
+
+// jsonType decodes an ABI JSON type, where tuples are written as "tuple" with separate components.
+func jsonType(name string, components []jsonParam) (*Type, error) {
+	if strings.HasSuffix(name, "]") {
+		open := strings.LastIndex(name, "[")
+		if open < 0 {
+			return nil, fmt.Errorf("invalid type %q", name)
+		}
+		elem, err := jsonType(name[:open], components)
+		if err != nil {
+			return nil, err
+		}
+		if length := name[open+1 : len(name)-1]; length != "" {
+			size, err := strconv.Atoi(length)
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("invalid array length in %q", name)
+			}
+			return &Type{Kind: ArrayTy, Size: size, Elem: elem}, nil
+		}
+		return &Type{Kind: SliceTy, Elem: elem}, nil
+	}
+
+	if name == "tuple" {
+		params, err := fromJSON(components)
+		if err != nil {
+			return nil, err
+		}
+		return &Type{Kind: TupleTy, Components: params}, nil
+	}
+	return elementary(name)
+}
+
+// Match compares sig against the functions or events of the same name in contract. It returns false if the
+// contract has none of that name, and an error describing the difference if none of them match.
+func (c *Contract) Match(sig *Signature) (bool, error) {
+	candidates := c.Functions[sig.Name]
+	if sig.Event {
+		candidates = c.Events[sig.Name]
+	}
+	if len(candidates) == 0 {
+		return false, nil
+	}
+
+	var known []string
+	for _, candidate := range candidates {
+		if candidate.Canonical() != sig.Canonical() {
+			known = append(known, candidate.Canonical())
+			continue
+		}
+		return true, compare(c.Name, sig, candidate)
+	}
+	return true, fmt.Errorf("%s does not match %s ABI, which has %s", sig.Canonical(), c.Name, strings.Join(known, ", "))
+}
+
+func compare(contract string, sig, abi *Signature) error {
+	if sig.Event {
+		for i := range sig.Inputs {
+			if sig.Inputs[i].Indexed != abi.Inputs[i].Indexed {
+				return fmt.Errorf("field %d of %s is indexed in %s ABI: %t", i, sig.Name, contract, abi.Inputs[i].Indexed)
+			}
+		}
+		return nil
+	}
+
+	// outputs only need to decode into the same gate types, so uint64 and uint256 are interchangeable
+	if len(sig.Outputs) != len(abi.Outputs) {
+		return fmt.Errorf("%s returns %d values in %s ABI, signature declares %d", sig.Name, len(abi.Outputs), contract, len(sig.Outputs))
+	}
+	for i := range sig.Outputs {
+		if got, want := GateType(sig.Outputs[i].Type), GateType(abi.Outputs[i].Type); got.String() != want.String() {
+			return fmt.Errorf("return value %d of %s is %s in %s ABI, signature declares %s", i, sig.Name, abi.Outputs[i].Type, contract, sig.Outputs[i].Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,380 @@
+// Package abi parses the Solidity signatures embedded in gate chain reads and validates them against the
+// declared gate types of the sources that use them.
+package abi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Kind is the kind of an ABI type.
+type Kind int
+
+const (
+	UintTy Kind = iota
+	IntTy
+	AddressTy
+	BoolTy
+	FixedBytesTy
+	BytesTy
+	StringTy
+	SliceTy
+	ArrayTy
+	TupleTy
+)
+
+// Type is a Solidity ABI type.
+type Type struct {
+	Kind Kind
+	// Size is the bit size of integers, the byte size of fixed bytes and the length of fixed arrays.
+	Size int
+	// Elem is the element type of slices and arrays.
+	Elem *Type
+	// Components are the members of a tuple.
+	Components []Param
+}
+
+// String returns the canonical form of the type used to compute selectors, e.g. uint256 or (address,bytes32)[].
+func (t *Type) String() string {
+	switch t.Kind {
+	case UintTy:
+		return "uint" + strconv.Itoa(t.Size)
+	case IntTy:
+		return "int" + strconv.Itoa(t.Size)
+	case AddressTy:
+		return "address"
+	case BoolTy:
+		return "bool"
+	case FixedBytesTy:
+		return "bytes" + strconv.Itoa(t.Size)
+	case BytesTy:
+		return "bytes"
+	case StringTy:
+		return "string"
+	case SliceTy:
+		return t.Elem.String() + "[]"
+	case ArrayTy:
+		return t.Elem.String() + "[" + strconv.Itoa(t.Size) + "]"
+	case TupleTy:
+		return "(" + joinTypes(t.Components) + ")"
+	}
+	return "invalid"
+}
+
+// Param is a function parameter, return value or event field.
+type Param struct {
+	Name    string
+	Type    *Type
+	Indexed bool
+}
+
+// Signature is a parsed function or event signature.
+type Signature struct {
+	// Event is set for event signatures and unset for function signatures.
+	Event   bool
+	Name    string
+	Inputs  []Param
+	Outputs []Param
+	// Modifiers lists the visibility, mutability and anonymous keywords in the order they appear.
+	Modifiers []string
+}
+
+// Canonical returns the signature in the form used to compute selectors and topics, e.g. claimData(uint256).
+func (s *Signature) Canonical() string {
+	return s.Name + "(" + joinTypes(s.Inputs) + ")"
+}
+
+// ID returns the keccak256 hash of the canonical signature. The first 4 bytes are the selector of a
+// function and the full hash is the first topic of an event.
+func (s *Signature) ID() []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(s.Canonical()))
+	return hash.Sum(nil)
+}
+
+// Selector returns the 4 byte function selector.
+func (s *Signature) Selector() [4]byte {
+	var selector [4]byte
+	copy(selector[:], s.ID())
+	return selector
+}
+
+func joinTypes(params []Param) string {
+	types := make([]string, len(params))
+	for i, p := range params {
+		types[i] = p.Type.String()
+	}
+	return strings.Join(types, ",")
+}
+
+// Parse parses a Solidity function or event signature such as
+// "function claimData(uint256 idx) view returns (uint32,address)" or
+// "event Move(uint256 indexed parentIndex, bytes32 indexed claim, address indexed claimant)". Hexagate also
+// accepts function signatures without the function keyword, such as "delay() returns (uint256)".
+func Parse(sig string) (*Signature, error) {
+	p := &parser{tokens: tokenize(sig)}
+	s, err := p.signature()
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature %q: %w", sig, err)
+	}
+	return s, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+// tokenize splits a signature into identifiers, numbers and punctuation.
+func tokenize(sig string) []string {
+	var tokens []string
+	for i := 0; i < len(sig); {
+		c := sig[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentChar(c):
+			start := i
+			for i < len(sig) && isIdentChar(sig[i]) {
+				i++
+			}
+			tokens = append(tokens, sig[start:i])
+		default:
+			tokens = append(tokens, sig[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func isIdent(tok string) bool {
+	return tok != "" && isIdentChar(tok[0]) && !('0' <= tok[0] && tok[0] <= '9')
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		return fmt.Errorf("expected %q, found %s", tok, describe(got))
+	}
+	return nil
+}
+
+func describe(tok string) string {
+	if tok == "" {
+		return "end of signature"
+	}
+	return strconv.Quote(tok)
+}
+
+var (
+	functionModifiers = map[string]bool{"public": true, "external": true, "view": true, "pure": true, "payable": true, "virtual": true, "override": true}
+	storageLocations  = map[string]bool{"memory": true, "calldata": true, "storage": true}
+)
+
+func (p *parser) signature() (*Signature, error) {
+	s := &Signature{}
+	switch kw := p.peek(); {
+	case kw == "function":
+		p.next()
+	case kw == "event":
+		p.next()
+		s.Event = true
+	case isIdent(kw) && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1] == "(":
+		// a function signature without the keyword
+	default:
+		return nil, fmt.Errorf("expected \"function\" or \"event\", found %s", describe(kw))
+	}
+
+	s.Name = p.next()
+	if !isIdent(s.Name) {
+		return nil, fmt.Errorf("expected name, found %s", describe(s.Name))
+	}
+
+	var err error
+	if s.Inputs, err = p.params(s.Event); err != nil {
+		return nil, err
+	}
+
+	for p.peek() != "" && p.peek() != "returns" {
+		modifier := p.next()
+		switch {
+		case s.Event && modifier == "anonymous":
+		case !s.Event && functionModifiers[modifier]:
+		case !s.Event && (modifier == "internal" || modifier == "private"):
+			return nil, fmt.Errorf("%s function cannot be called externally", modifier)
+		default:
+			return nil, fmt.Errorf("unexpected %s", describe(modifier))
+		}
+		for _, seen := range s.Modifiers {
+			if seen == modifier {
+				return nil, fmt.Errorf("duplicate modifier %q", modifier)
+			}
+		}
+		s.Modifiers = append(s.Modifiers, modifier)
+	}
+
+	if p.peek() == "returns" {
+		if s.Event {
+			return nil, fmt.Errorf("events cannot return values")
+		}
+		p.next()
+		if s.Outputs, err = p.params(false); err != nil {
+			return nil, err
+		}
+		if len(s.Outputs) == 0 {
+			return nil, fmt.Errorf("empty returns list")
+		}
+	}
+
+	if tok := p.next(); tok != "" {
+		return nil, fmt.Errorf("unexpected %s after signature", describe(tok))
+	}
+	return s, nil
+}
+
+// params parses a parenthesized parameter list. Event fields may be indexed and function parameters may
+// have a data location.
+func (p *parser) params(event bool) ([]Param, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var params []Param
+	if p.peek() == ")" {
+		p.next()
+		return params, nil
+	}
+
+	for {
+		typ, err := p.typ()
+		if err != nil {
+			return nil, err
+		}
+		param := Param{Type: typ}
+
+		if event && p.peek() == "indexed" {
+			p.next()
+			param.Indexed = true
+		}
+		if !event && storageLocations[p.peek()] {
+			p.next()
+		}
+		if tok := p.peek(); tok != "," && tok != ")" {
+			if !isIdent(tok) {
+				return nil, fmt.Errorf("expected parameter name, found %s", describe(tok))
+			}
+			param.Name = p.next()
+		}
+		params = append(params, param)
+
+		switch tok := p.next(); tok {
+		case ",":
+		case ")":
+			return params, nil
+		default:
+			return nil, fmt.Errorf("expected \",\" or \")\", found %s", describe(tok))
+		}
+	}
+}
+
+func (p *parser) typ() (*Type, error) {
+	var typ *Type
+	if p.peek() == "(" || p.peek() == "tuple" {
+		if p.peek() == "tuple" {
+			p.next()
+		}
+		components, err := p.params(false)
+		if err != nil {
+			return nil, err
+		}
+		if len(components) == 0 {
+			return nil, fmt.Errorf("empty tuple")
+		}
+		typ = &Type{Kind: TupleTy, Components: components}
+	} else {
+		var err error
+		if typ, err = elementary(p.next()); err != nil {
+			return nil, err
+		}
+	}
+
+	for p.peek() == "[" {
+		p.next()
+		if p.peek() == "]" {
+			p.next()
+			typ = &Type{Kind: SliceTy, Elem: typ}
+			continue
+		}
+		size, err := strconv.Atoi(p.next())
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid array length")
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		typ = &Type{Kind: ArrayTy, Size: size, Elem: typ}
+	}
+	return typ, nil
+}
+
+// elementary parses an elementary type name, resolving the uint, int and byte aliases.
+func elementary(name string) (*Type, error) {
+	switch name {
+	case "address":
+		return &Type{Kind: AddressTy}, nil
+	case "bool":
+		return &Type{Kind: BoolTy}, nil
+	case "bytes":
+		return &Type{Kind: BytesTy}, nil
+	case "string":
+		return &Type{Kind: StringTy}, nil
+	case "uint":
+		return &Type{Kind: UintTy, Size: 256}, nil
+	case "int":
+		return &Type{Kind: IntTy, Size: 256}, nil
+	case "byte":
+		return &Type{Kind: FixedBytesTy, Size: 1}, nil
+	}
+
+	for _, prefix := range []struct {
+		name     string
+		kind     Kind
+		min, max int
+		step     int
+	}{
+		{"uint", UintTy, 8, 256, 8},
+		{"int", IntTy, 8, 256, 8},
+		{"bytes", FixedBytesTy, 1, 32, 1},
+	} {
+		if !strings.HasPrefix(name, prefix.name) {
+			continue
+		}
+		size, err := strconv.Atoi(name[len(prefix.name):])
+		if err != nil || size < prefix.min || size > prefix.max || size%prefix.step != 0 || strconv.Itoa(size) != name[len(prefix.name):] {
+			return nil, fmt.Errorf("invalid type %q", name)
+		}
+		return &Type{Kind: prefix.kind, Size: size}, nil
+	}
+
+	return nil, fmt.Errorf("unknown type %s", describe(name))
+}
@@ -0,0 +1,78 @@
+package abi
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestParseSignatures(t *testing.T) {
+	tests := []struct {
+		sig       string
+		canonical string
+		outputs   string
+	}{
+		{"function claimDataLen() view returns (uint256)", "claimDataLen()", "(uint256)"},
+		{"function weth() returns(address)", "weth()", "(address)"},
+		{"getCurrentBlockTimestamp() public view returns (uint256)", "getCurrentBlockTimestamp()", "(uint256)"},
+		{"function claimData(uint256 idx) returns (uint32,address,address,uint128,bytes32,uint128,uint128)", "claimData(uint256)", "(uint32,address,address,uint128,bytes32,uint128,uint128)"},
+		{"function l2BlockNumber() public pure returns (uint256 l2BlockNumber_)", "l2BlockNumber()", "(uint256)"},
+		{"function create(uint32 _gameType, bytes32 _rootClaim, bytes calldata _extraData)", "create(uint32,bytes32,bytes)", "()"},
+		{"function f(uint a, (address, bool)[] memory b, bytes4[2] c) external", "f(uint256,(address,bool)[],bytes4[2])", "()"},
+		{"event Move(uint256 indexed parentIndex, bytes32 indexed claim, address indexed claimant)", "Move(uint256,bytes32,address)", "()"},
+	}
+
+	for _, test := range tests {
+		sig, err := Parse(test.sig)
+		if err != nil {
+			t.Errorf("Error parsing %q: %v", test.sig, err)
+			continue
+		}
+		if got := sig.Canonical(); got != test.canonical {
+			t.Errorf("Parsing %q: expected %s, got %s", test.sig, test.canonical, got)
+		}
+		if got := "(" + joinTypes(sig.Outputs) + ")"; got != test.outputs {
+			t.Errorf("Parsing %q: expected outputs %s, got %s", test.sig, test.outputs, got)
+		}
+	}
+}
+
+func TestParseInvalidSignatures(t *testing.T) {
+	tests := map[string]string{
+		"1f() returns (uint256)":                    `expected "function" or "event", found "1f"`,
+		"getCurrentBlockTimestamp public view":      `expected "function" or "event", found "getCurrentBlockTimestamp"`,
+		"function f(uint7)":                         `invalid type "uint7"`,
+		"function f(bytes33)":                       `invalid type "bytes33"`,
+		"function f(uint256 a b)":                   `expected "," or ")", found "b"`,
+		"function f() internal view":                "internal function cannot be called externally",
+		"function f() view view":                    `duplicate modifier "view"`,
+		"function f() returns ()":                   "empty returns list",
+		"event E(uint256 indexed a) returns (bool)": "events cannot return values",
+		"function f(uint256":                        "found end of signature",
+	}
+
+	for sig, expected := range tests {
+		_, err := Parse(sig)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Parsing %q: expected error containing %q, got %v", sig, expected, err)
+		}
+	}
+}
+
+func TestSignatureID(t *testing.T) {
+	sig, err := Parse("function balanceOf(address) returns (uint256)")
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	if selector := sig.Selector(); hex.EncodeToString(selector[:]) != "70a08231" {
+		t.Errorf("Unexpected selector %x", selector)
+	}
+
+	event, err := Parse("event Transfer(address indexed src, address indexed dst, uint256 wad)")
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	if topic := hex.EncodeToString(event.ID()); topic != "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef" {
+		t.Errorf("Unexpected topic %s", topic)
+	}
+}
@@ -0,0 +1,84 @@
+package abi
+
+import (
+	"fmt"
+
+	"github.com/base-org/fault-proof-monitors/gate/check"
+)
+
+// GateType maps an ABI type onto the gate type Hexagate decodes it into. Integers of every size become
+// integer, fixed and dynamic bytes become bytes, arrays become lists and tuples stay tuples.
+func GateType(t *Type) check.Type {
+	switch t.Kind {
+	case UintTy, IntTy:
+		return check.Integer
+	case AddressTy:
+		return check.Address
+	case BoolTy:
+		return check.Boolean
+	case FixedBytesTy, BytesTy:
+		return check.Bytes
+	case StringTy:
+		return check.String
+	case SliceTy, ArrayTy:
+		return &check.List{Elem: GateType(t.Elem)}
+	case TupleTy:
+		return tupleOf(t.Components)
+	}
+	return check.Unknown
+}
+
+func tupleOf(params []Param) *check.Tuple {
+	tuple := &check.Tuple{Elems: make([]check.Type, len(params))}
+	for i, p := range params {
+		tuple.Elems[i] = GateType(p.Type)
+	}
+	return tuple
+}
+
+// ResultType returns the gate type of the value a chain read builtin produces for a signature.
+//
+//   - Call returns its single return value, or a tuple of them if the function has several.
+//   - Calls and HistoricalCalls return a list with a tuple of the call arguments per call.
+//   - Events and HistoricalEvents return a list with a tuple of the event fields per event.
+//
+// withBlocks and withSender wrap every list element in a tuple that starts with the block number and the
+// sender address, followed by the arguments or fields.
+func ResultType(builtin string, sig *Signature, withBlocks, withSender bool) (check.Type, error) {
+	switch builtin {
+	case "Call":
+		if sig.Event {
+			return nil, fmt.Errorf("%s requires a function signature, got event %s", builtin, sig.Name)
+		}
+		switch len(sig.Outputs) {
+		case 0:
+			return nil, fmt.Errorf("function %s does not return a value", sig.Name)
+		case 1:
+			return GateType(sig.Outputs[0].Type), nil
+		}
+		return tupleOf(sig.Outputs), nil
+
+	case "Calls", "HistoricalCalls", "Events", "HistoricalEvents":
+		event := builtin == "Events" || builtin == "HistoricalEvents"
+		if event && !sig.Event {
+			return nil, fmt.Errorf("%s requires an event signature, got function %s", builtin, sig.Name)
+		}
+		if !event && sig.Event {
+			return nil, fmt.Errorf("%s requires a function signature, got event %s", builtin, sig.Name)
+		}
+
+		var elem check.Type = tupleOf(sig.Inputs)
+		if withBlocks || withSender {
+			var wrapped []check.Type
+			if withBlocks {
+				wrapped = append(wrapped, check.Integer)
+			}
+			if withSender {
+				wrapped = append(wrapped, check.Address)
+			}
+			elem = &check.Tuple{Elems: append(wrapped, elem)}
+		}
+		return &check.List{Elem: elem}, nil
+	}
+	return nil, fmt.Errorf("%s does not take a signature", builtin)
+}
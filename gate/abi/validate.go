@@ -0,0 +1,131 @@
+package abi
+
+import (
+	"fmt"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/check"
+)
+
+// Validate checks the signature of every Call, Calls, Events, HistoricalCalls and HistoricalEvents block in
+// file. info holds the types inferred by check.Check and is used to compare the value each block returns
+// with the declared type of its source, and its params with the function inputs. If contracts are given,
+// every signature whose name appears in one of them must also match that ABI.
+func Validate(file *gate.File, info *check.Info, contracts ...*Contract) []check.Diagnostic {
+	v := &validator{info: info, contracts: contracts}
+	gate.Inspect(file, func(node gate.Node) bool {
+		if call, ok := node.(*gate.StructCallExpr); ok && check.HasSignature(call.Fun.Name) {
+			v.chainRead(call)
+		}
+		return true
+	})
+	return v.diagnostics
+}
+
+type validator struct {
+	info        *check.Info
+	contracts   []*Contract
+	diagnostics []check.Diagnostic
+}
+
+func (v *validator) errorf(node gate.Node, format string, args ...any) {
+	v.diagnostics = append(v.diagnostics, check.Diagnostic{Pos: node.Pos(), Msg: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) chainRead(call *gate.StructCallExpr) {
+	name := call.Fun.Name
+	field := call.Field("signature")
+	if field == nil {
+		// reported by the type checker
+		return
+	}
+	lit, ok := field.(*gate.BasicLit)
+	if !ok || lit.Kind != gate.STRING {
+		v.errorf(field, "%s signature must be a string literal", name)
+		return
+	}
+
+	sig, err := Parse(lit.Unquote())
+	if err != nil {
+		v.errorf(lit, "%v", err)
+		return
+	}
+
+	result, err := ResultType(name, sig, v.flag(call, "withBlocks"), v.flag(call, "withSender"))
+	if err != nil {
+		v.errorf(lit, "%v", err)
+		return
+	}
+	if declared := v.info.Types[call]; declared != nil && !check.Identical(result, declared) {
+		v.errorf(call, "%s returns %s for %s, but is used as %s", name, result, sig.Canonical(), declared)
+	}
+
+	if name == "Call" {
+		v.params(call, sig)
+	}
+
+	for _, contract := range v.contracts {
+		if found, err := contract.Match(sig); found {
+			if err != nil {
+				v.errorf(lit, "%v", err)
+			}
+			break
+		}
+	}
+}
+
+// flag returns the value of a boolean field such as withBlocks, which must be a literal since it determines
+// the shape of the result.
+func (v *validator) flag(call *gate.StructCallExpr, name string) bool {
+	field := call.Field(name)
+	if field == nil {
+		return false
+	}
+	lit, ok := field.(*gate.BoolLit)
+	if !ok {
+		v.errorf(field, "%s must be true or false", name)
+		return false
+	}
+	return lit.Value
+}
+
+// params checks that the params of a Call match the function inputs in number and type.
+func (v *validator) params(call *gate.StructCallExpr, sig *Signature) {
+	params := call.Field("params")
+	if params == nil {
+		if len(sig.Inputs) > 0 {
+			v.errorf(call, "Call to %s is missing params", sig.Canonical())
+		}
+		return
+	}
+
+	var args []gate.Expr
+	var types []check.Type
+	if tuple, ok := params.(*gate.CallExpr); ok && tuple.Fun.Name == "tuple" {
+		args = tuple.Args
+		for _, arg := range args {
+			types = append(types, v.info.Types[arg])
+		}
+	} else if tuple, ok := v.info.Types[params].(*check.Tuple); ok {
+		types = tuple.Elems
+	} else {
+		v.errorf(params, "params must be a tuple")
+		return
+	}
+
+	if len(types) != len(sig.Inputs) {
+		v.errorf(params, "%s takes %d params, got %d", sig.Canonical(), len(sig.Inputs), len(types))
+		return
+	}
+	for i, typ := range types {
+		want := GateType(sig.Inputs[i].Type)
+		if typ == nil || check.Identical(typ, want) {
+			continue
+		}
+		var node gate.Node = params
+		if args != nil {
+			node = args[i]
+		}
+		v.errorf(node, "param %d of %s must be %s, got %s", i, sig.Canonical(), want, typ)
+	}
+}
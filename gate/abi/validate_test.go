@@ -0,0 +1,134 @@
+package abi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/check"
+)
+
+func validate(t *testing.T, src string, contracts ...*Contract) []string {
+	file, err := gate.ParseFile("test.gate", []byte(src))
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	info := check.Check(file)
+	if len(info.Diagnostics) != 0 {
+		t.Fatalf("Unexpected type errors %v", info.Diagnostics)
+	}
+
+	var diagnostics []string
+	for _, d := range Validate(file, info, contracts...) {
+		diagnostics = append(diagnostics, d.String())
+	}
+	return diagnostics
+}
+
+func TestValidateMonitors(t *testing.T) {
+	// We expect the signatures of every monitor to be well-formed and match their declared source types
+	paths, err := filepath.Glob("../../monitors/*.gate")
+	if err != nil {
+		t.Fatalf("Error listing monitors: %v", err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Error reading %s: %v", path, err)
+			}
+			for _, d := range validate(t, string(src)) {
+				t.Errorf("Unexpected diagnostic %s", d)
+			}
+		})
+	}
+}
+
+func TestValidateDiagnostics(t *testing.T) {
+	header := `use Call, Calls, Events, HistoricalCalls from hexagate;
+param disputeGame: address;
+`
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "missing function keyword",
+			src:      `source x: address = Call { contract: disputeGame, signature: "getCurrentBlockTimestamp() view returns (uint256)" };`,
+			expected: "test.gate:3:21: Call returns integer for getCurrentBlockTimestamp(), but is used as address",
+		},
+		{
+			name:     "return type",
+			src:      `source x: address = Call { contract: disputeGame, signature: "function delay() returns (uint256)" };`,
+			expected: "test.gate:3:21: Call returns integer for delay(), but is used as address",
+		},
+		{
+			name:     "tuple return",
+			src:      `source x: tuple<integer, address> = Call { contract: disputeGame, signature: "function f() returns (uint32, address, bytes32)" };`,
+			expected: "test.gate:3:37: Call returns tuple<integer, address, bytes> for f(), but is used as tuple<integer, address>",
+		},
+		{
+			name:     "params arity",
+			src:      `source x: integer = Call { contract: disputeGame, signature: "function credit(address) returns (uint256)", params: tuple(disputeGame, 1) };`,
+			expected: "test.gate:3:116: credit(address) takes 1 params, got 2",
+		},
+		{
+			name:     "params type",
+			src:      `source x: integer = Call { contract: disputeGame, signature: "function credit(address) returns (uint256)", params: tuple(1) };`,
+			expected: "test.gate:3:122: param 0 of credit(address) must be address, got integer",
+		},
+		{
+			name:     "missing params",
+			src:      `source x: integer = Call { contract: disputeGame, signature: "function credit(address) returns (uint256)" };`,
+			expected: "test.gate:3:21: Call to credit(address) is missing params",
+		},
+		{
+			name:     "calls with blocks and sender",
+			src:      `source x: list<tuple<integer, address>> = HistoricalCalls { contract: disputeGame, signature: "function unlock(address, uint256)", withBlocks: true, withSender: true };`,
+			expected: "test.gate:3:43: HistoricalCalls returns list<tuple<integer, address, tuple<address, integer>>> for unlock(address,uint256), but is used as list<tuple<integer, address>>",
+		},
+		{
+			name:     "event for calls",
+			src:      `source x: list<tuple<integer>> = Calls { contract: disputeGame, signature: "event Resolved(uint8 indexed status)" };`,
+			expected: `test.gate:3:76: Calls requires a function signature, got event Resolved`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validate(t, header+test.src)
+			if len(got) != 1 || got[0] != test.expected {
+				t.Errorf("Expected diagnostic %q, got %q", test.expected, strings.Join(got, "; "))
+			}
+		})
+	}
+}
+
+func TestValidateAgainstABI(t *testing.T) {
+	contracts, err := LoadContracts("testdata")
+	if err != nil {
+		t.Fatalf("Error loading ABIs: %v", err)
+	}
+
+	src := `use Call, Calls from hexagate;
+param delayedWETH: address;
+source delay: integer = Call { contract: delayedWETH, signature: "function delay() returns (uint256)" };
+source withdrawals: list<tuple<address, integer>> = Calls { contract: delayedWETH, signature: "function withdraw(address _guy, uint256 _wad)" };
+source unlocks: list<tuple<integer>> = Calls { contract: delayedWETH, signature: "function unlock(uint256 _wad)" };
+source total: integer = Call { contract: delayedWETH, signature: "function withdrawals(address, address) returns (uint256)", params: tuple(delayedWETH, delayedWETH) };
+source unknown: integer = Call { contract: delayedWETH, signature: "function getCurrentBlockTimestamp() returns (uint256)" };
+`
+	expected := []string{
+		"test.gate:5:82: unlock(uint256) does not match DelayedWETH ABI, which has unlock(address,uint256)",
+		"test.gate:6:66: withdrawals returns 2 values in DelayedWETH ABI, signature declares 1",
+	}
+
+	got := validate(t, src, contracts...)
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected diagnostics:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,463 @@
+// Package gate implements a lexer, parser and syntax tree for Hexagate gate monitor files.
+package gate
+
+import (
+	"strconv"
+)
+
+// Node is implemented by every node of the gate syntax tree.
+type Node interface {
+	Pos() Pos // position of the first character of the node
+	End() Pos // position immediately after the node
+}
+
+// Expr is implemented by every expression node.
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// TypeExpr is implemented by every type node, e.g. list<tuple<integer, address>>.
+type TypeExpr interface {
+	Node
+	typeNode()
+}
+
+// Decl is implemented by the top level declarations of a gate file.
+type Decl interface {
+	Node
+	declNode()
+}
+
+// shift returns the position n bytes after p on the same line.
+func shift(p Pos, n int) Pos {
+	return Pos{Filename: p.Filename, Offset: p.Offset + n, Line: p.Line, Column: p.Column + n}
+}
+
+// ----------------------------------------------------------------------------
+// Types
+
+type (
+	// NamedType is a primitive type such as integer, address, bytes, boolean or string.
+	NamedType struct {
+		NamePos Pos
+		Name    string
+	}
+
+	// ListType is list<Elem>.
+	ListType struct {
+		List   Pos
+		Elem   TypeExpr
+		Rangle Pos
+	}
+
+	// TupleType is tuple<Elems...>.
+	TupleType struct {
+		Tuple  Pos
+		Elems  []TypeExpr
+		Rangle Pos
+	}
+
+	// MapType is map<Key, Value>.
+	MapType struct {
+		Map    Pos
+		Key    TypeExpr
+		Value  TypeExpr
+		Rangle Pos
+	}
+)
+
+func (t *NamedType) Pos() Pos { return t.NamePos }
+func (t *ListType) Pos() Pos  { return t.List }
+func (t *TupleType) Pos() Pos { return t.Tuple }
+func (t *MapType) Pos() Pos   { return t.Map }
+
+func (t *NamedType) End() Pos { return shift(t.NamePos, len(t.Name)) }
+func (t *ListType) End() Pos  { return shift(t.Rangle, 1) }
+func (t *TupleType) End() Pos { return shift(t.Rangle, 1) }
+func (t *MapType) End() Pos   { return shift(t.Rangle, 1) }
+
+func (*NamedType) typeNode() {}
+func (*ListType) typeNode()  {}
+func (*TupleType) typeNode() {}
+func (*MapType) typeNode()   {}
+
+// ----------------------------------------------------------------------------
+// Expressions
+
+type (
+	// Ident is a reference to a param, source, builtin or comprehension variable.
+	Ident struct {
+		NamePos Pos
+		Name    string
+	}
+
+	// BasicLit is an integer, hexadecimal or string literal.
+	BasicLit struct {
+		ValuePos Pos
+		Kind     Token // INT, HEX or STRING
+		Value    string
+	}
+
+	// BoolLit is true or false.
+	BoolLit struct {
+		ValuePos Pos
+		Value    bool
+	}
+
+	// ParenExpr is a parenthesized expression.
+	ParenExpr struct {
+		Lparen Pos
+		X      Expr
+		Rparen Pos
+	}
+
+	// UnaryExpr is !X or -X.
+	UnaryExpr struct {
+		OpPos Pos
+		Op    Token
+		X     Expr
+	}
+
+	// BinaryExpr is X Op Y.
+	BinaryExpr struct {
+		X     Expr
+		OpPos Pos
+		Op    Token
+		Y     Expr
+	}
+
+	// TernaryExpr is Cond ? Then : Else.
+	TernaryExpr struct {
+		Cond     Expr
+		Question Pos
+		Then     Expr
+		Colon    Pos
+		Else     Expr
+	}
+
+	// IndexExpr is X[Index].
+	IndexExpr struct {
+		X      Expr
+		Lbrack Pos
+		Index  Expr
+		Rbrack Pos
+	}
+
+	// CallExpr is a function style call such as tuple(a, b), list(x) or bytes(0x00).
+	CallExpr struct {
+		Fun    *Ident
+		Lparen Pos
+		Args   []Expr
+		Rparen Pos
+	}
+
+	// StructCallExpr is a struct style builtin call such as Call { contract: x, signature: "..." }.
+	StructCallExpr struct {
+		Fun    *Ident
+		Lbrace Pos
+		Fields []*Field
+		Rbrace Pos
+	}
+
+	// ListLit is a list literal [a, b, c].
+	ListLit struct {
+		Lbrack Pos
+		Elems  []Expr
+		Rbrack Pos
+	}
+
+	// ListComp is a list comprehension [Elem for Var in Seq if Cond]. Cond is nil when there is no filter.
+	ListComp struct {
+		Lbrack Pos
+		Elem   Expr
+		For    Pos
+		Var    *Ident
+		Seq    Expr
+		If     Pos
+		Cond   Expr
+		Rbrack Pos
+	}
+
+	// MapComp is a map comprehension {Key: Value for Var in Seq if Cond}. Cond is nil when there is no filter.
+	MapComp struct {
+		Lbrace Pos
+		Key    Expr
+		Colon  Pos
+		Value  Expr
+		For    Pos
+		Var    *Ident
+		Seq    Expr
+		If     Pos
+		Cond   Expr
+		Rbrace Pos
+	}
+)
+
+// Field is a name: value pair inside a struct style call or an invariant block.
+type Field struct {
+	Name  *Ident
+	Colon Pos
+	Value Expr
+}
+
+func (f *Field) Pos() Pos { return f.Name.Pos() }
+func (f *Field) End() Pos { return f.Value.End() }
+
+func (x *Ident) Pos() Pos          { return x.NamePos }
+func (x *BasicLit) Pos() Pos       { return x.ValuePos }
+func (x *BoolLit) Pos() Pos        { return x.ValuePos }
+func (x *ParenExpr) Pos() Pos      { return x.Lparen }
+func (x *UnaryExpr) Pos() Pos      { return x.OpPos }
+func (x *BinaryExpr) Pos() Pos     { return x.X.Pos() }
+func (x *TernaryExpr) Pos() Pos    { return x.Cond.Pos() }
+func (x *IndexExpr) Pos() Pos      { return x.X.Pos() }
+func (x *CallExpr) Pos() Pos       { return x.Fun.Pos() }
+func (x *StructCallExpr) Pos() Pos { return x.Fun.Pos() }
+func (x *ListLit) Pos() Pos        { return x.Lbrack }
+func (x *ListComp) Pos() Pos       { return x.Lbrack }
+func (x *MapComp) Pos() Pos        { return x.Lbrace }
+
+func (x *Ident) End() Pos    { return shift(x.NamePos, len(x.Name)) }
+func (x *BasicLit) End() Pos { return shift(x.ValuePos, len(x.Value)) }
+func (x *BoolLit) End() Pos {
+	if x.Value {
+		return shift(x.ValuePos, len("true"))
+	}
+	return shift(x.ValuePos, len("false"))
+}
+func (x *ParenExpr) End() Pos      { return shift(x.Rparen, 1) }
+func (x *UnaryExpr) End() Pos      { return x.X.End() }
+func (x *BinaryExpr) End() Pos     { return x.Y.End() }
+func (x *TernaryExpr) End() Pos    { return x.Else.End() }
+func (x *IndexExpr) End() Pos      { return shift(x.Rbrack, 1) }
+func (x *CallExpr) End() Pos       { return shift(x.Rparen, 1) }
+func (x *StructCallExpr) End() Pos { return shift(x.Rbrace, 1) }
+func (x *ListLit) End() Pos        { return shift(x.Rbrack, 1) }
+func (x *ListComp) End() Pos       { return shift(x.Rbrack, 1) }
+func (x *MapComp) End() Pos        { return shift(x.Rbrace, 1) }
+
+func (*Ident) exprNode()          {}
+func (*BasicLit) exprNode()       {}
+func (*BoolLit) exprNode()        {}
+func (*ParenExpr) exprNode()      {}
+func (*UnaryExpr) exprNode()      {}
+func (*BinaryExpr) exprNode()     {}
+func (*TernaryExpr) exprNode()    {}
+func (*IndexExpr) exprNode()      {}
+func (*CallExpr) exprNode()       {}
+func (*StructCallExpr) exprNode() {}
+func (*ListLit) exprNode()        {}
+func (*ListComp) exprNode()       {}
+func (*MapComp) exprNode()        {}
+
+// Field returns the value of the named field, or nil if the call has no such field.
+func (x *StructCallExpr) Field(name string) Expr {
+	for _, field := range x.Fields {
+		if field.Name.Name == name {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+// Unquote returns the value of a STRING literal without its quotes and escapes.
+func (x *BasicLit) Unquote() string {
+	if x.Kind != STRING {
+		return x.Value
+	}
+	value, err := strconv.Unquote(x.Value)
+	if err != nil {
+		return x.Value[1 : len(x.Value)-1]
+	}
+	return value
+}
+
+// ----------------------------------------------------------------------------
+// Declarations
+
+type (
+	// UseDecl is use A, B, C from hexagate;
+	UseDecl struct {
+		Use       Pos
+		Names     []*Ident
+		From      Pos
+		Module    *Ident
+		Semicolon Pos
+	}
+
+	// ParamDecl is param name: type;
+	ParamDecl struct {
+		Param     Pos
+		Name      *Ident
+		Type      TypeExpr
+		Semicolon Pos
+	}
+
+	// SourceDecl is source name: type = value;
+	SourceDecl struct {
+		Source    Pos
+		Name      *Ident
+		Type      TypeExpr
+		Assign    Pos
+		Value     Expr
+		Semicolon Pos
+	}
+
+	// InvariantDecl is invariant { description: "...", condition: expr };
+	InvariantDecl struct {
+		Invariant Pos
+		Lbrace    Pos
+		Fields    []*Field
+		Rbrace    Pos
+		Semicolon Pos
+	}
+)
+
+func (d *UseDecl) Pos() Pos       { return d.Use }
+func (d *ParamDecl) Pos() Pos     { return d.Param }
+func (d *SourceDecl) Pos() Pos    { return d.Source }
+func (d *InvariantDecl) Pos() Pos { return d.Invariant }
+
+func (d *UseDecl) End() Pos       { return shift(d.Semicolon, 1) }
+func (d *ParamDecl) End() Pos     { return shift(d.Semicolon, 1) }
+func (d *SourceDecl) End() Pos    { return shift(d.Semicolon, 1) }
+func (d *InvariantDecl) End() Pos { return shift(d.Semicolon, 1) }
+
+func (*UseDecl) declNode()       {}
+func (*ParamDecl) declNode()     {}
+func (*SourceDecl) declNode()    {}
+func (*InvariantDecl) declNode() {}
+
+// Field returns the value of the named invariant field, or nil if it is missing.
+func (d *InvariantDecl) Field(name string) Expr {
+	for _, field := range d.Fields {
+		if field.Name.Name == name {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+// Description returns the unquoted invariant description, or an empty string if it is missing.
+func (d *InvariantDecl) Description() string {
+	if lit, ok := d.Field("description").(*BasicLit); ok && lit.Kind == STRING {
+		return lit.Unquote()
+	}
+	return ""
+}
+
+// Condition returns the invariant condition, or nil if it is missing.
+func (d *InvariantDecl) Condition() Expr {
+	return d.Field("condition")
+}
+
+// Comment is a single // line comment or /* block */ comment.
+type Comment struct {
+	Slash Pos
+	Text  string // comment text including the comment markers
+}
+
+func (c *Comment) Pos() Pos { return c.Slash }
+
+// End returns the position after the comment. Block comments may span lines, so the end is computed from the text.
+func (c *Comment) End() Pos {
+	end := c.Slash
+	for _, ch := range c.Text {
+		end.Offset += len(string(ch))
+		if ch == '\n' {
+			end.Line++
+			end.Column = 1
+			continue
+		}
+		end.Column++
+	}
+	return end
+}
+
+// File is a parsed gate file.
+type File struct {
+	Name     string     // file name used in positions, may be empty
+	Decls    []Decl     // top level declarations in source order
+	Comments []*Comment // every comment in the file in source order
+}
+
+// Pos returns the position of the first declaration, or a position holding only the file name for an empty file.
+func (f *File) Pos() Pos {
+	if len(f.Decls) > 0 {
+		return f.Decls[0].Pos()
+	}
+	return Pos{Filename: f.Name}
+}
+
+// End returns the position after the last declaration.
+func (f *File) End() Pos {
+	if len(f.Decls) > 0 {
+		return f.Decls[len(f.Decls)-1].End()
+	}
+	return Pos{Filename: f.Name}
+}
+
+// Uses returns every use declaration in the file.
+func (f *File) Uses() []*UseDecl {
+	var uses []*UseDecl
+	for _, decl := range f.Decls {
+		if use, ok := decl.(*UseDecl); ok {
+			uses = append(uses, use)
+		}
+	}
+	return uses
+}
+
+// Params returns every param declaration in the file.
+func (f *File) Params() []*ParamDecl {
+	var params []*ParamDecl
+	for _, decl := range f.Decls {
+		if param, ok := decl.(*ParamDecl); ok {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// Sources returns every source declaration in the file.
+func (f *File) Sources() []*SourceDecl {
+	var sources []*SourceDecl
+	for _, decl := range f.Decls {
+		if source, ok := decl.(*SourceDecl); ok {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// Invariants returns every invariant declaration in the file.
+func (f *File) Invariants() []*InvariantDecl {
+	var invariants []*InvariantDecl
+	for _, decl := range f.Decls {
+		if invariant, ok := decl.(*InvariantDecl); ok {
+			invariants = append(invariants, invariant)
+		}
+	}
+	return invariants
+}
+
+// Source returns the source declaration with the given name, or nil.
+func (f *File) Source(name string) *SourceDecl {
+	for _, source := range f.Sources() {
+		if source.Name.Name == name {
+			return source
+		}
+	}
+	return nil
+}
+
+// Param returns the param declaration with the given name, or nil.
+func (f *File) Param(name string) *ParamDecl {
+	for _, param := range f.Params() {
+		if param.Name.Name == name {
+			return param
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package check
+
+import (
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// field describes a struct style builtin field.
+type field struct {
+	required bool
+	typ      Type // nil accepts any type, the builtin checks it itself
+}
+
+// builtin describes the fields and result type of a hexagate builtin.
+type builtin struct {
+	fields map[string]field
+	// result computes the type of a call from its field types and the type expected by the context.
+	result func(c *checker, call *gate.StructCallExpr, fields map[string]Type, expected Type) Type
+}
+
+func required(typ Type) field { return field{required: true, typ: typ} }
+func optional(typ Type) field { return field{typ: typ} }
+
+func fixed(typ Type) func(*checker, *gate.StructCallExpr, map[string]Type, Type) Type {
+	return func(*checker, *gate.StructCallExpr, map[string]Type, Type) Type { return typ }
+}
+
+// contextual is the result of chain reads whose shape depends on the ABI signature. The declared source
+// type is trusted here and verified against the signature by the abi package.
+func contextual(_ *checker, _ *gate.StructCallExpr, _ map[string]Type, expected Type) Type {
+	if expected == nil {
+		return Unknown
+	}
+	return expected
+}
+
+var chainRead = map[string]field{
+	"contract":  required(Address),
+	"signature": required(String),
+	"params":    optional(nil),
+	"block":     optional(Integer),
+}
+
+var chainHistory = map[string]field{
+	"contract":   required(Address),
+	"signature":  required(String),
+	"withBlocks": optional(Boolean),
+	"withSender": optional(Boolean),
+}
+
+var builtins = map[string]builtin{
+	"Call":             {fields: chainRead, result: contextual},
+	"Calls":            {fields: chainHistory, result: contextual},
+	"Events":           {fields: chainHistory, result: contextual},
+	"HistoricalCalls":  {fields: chainHistory, result: contextual},
+	"HistoricalEvents": {fields: chainHistory, result: contextual},
+
+	"BlockNumber":    {fields: map[string]field{}, result: fixed(Integer)},
+	"BlockTimestamp": {fields: map[string]field{}, result: fixed(Integer)},
+	"BlockHash": {
+		fields: map[string]field{"block": required(Integer), "chainId": optional(Integer)},
+		result: fixed(Bytes),
+	},
+	"StateRoot": {
+		fields: map[string]field{"block": required(Integer), "chainId": optional(Integer)},
+		result: fixed(Bytes),
+	},
+	"StorageHash": {
+		fields: map[string]field{"address": required(Address), "block": required(Integer), "chainId": optional(Integer)},
+		result: fixed(Bytes),
+	},
+	"FilterAddressesInTrace": {
+		fields: map[string]field{"addresses": required(&List{Elem: Address})},
+		result: fixed(&List{Elem: Address}),
+	},
+	"Keccak256": {
+		fields: map[string]field{"input": required(Bytes)},
+		result: fixed(Bytes),
+	},
+
+	"Len": {
+		fields: map[string]field{"sequence": required(nil)},
+		result: func(c *checker, call *gate.StructCallExpr, fields map[string]Type, _ Type) Type {
+			switch fields["sequence"].(type) {
+			case *List, *Map:
+			default:
+				if fields["sequence"] != Unknown {
+					c.errorf(call.Field("sequence"), "Len sequence must be a list or map, got %s", fields["sequence"])
+				}
+			}
+			return Integer
+		},
+	},
+	"Contains": {
+		fields: map[string]field{"sequence": required(nil), "item": required(nil)},
+		result: func(c *checker, call *gate.StructCallExpr, fields map[string]Type, _ Type) Type {
+			elem := c.sequenceElem(call, "sequence", fields)
+			if !Identical(elem, fields["item"]) {
+				c.errorf(call.Field("item"), "Contains item has type %s, but sequence holds %s", fields["item"], elem)
+			}
+			return Boolean
+		},
+	},
+	"MapContains": {
+		fields: map[string]field{"map": required(nil), "item": required(nil)},
+		result: func(c *checker, call *gate.StructCallExpr, fields map[string]Type, _ Type) Type {
+			switch m := fields["map"].(type) {
+			case *Map:
+				if !Identical(m.Key, fields["item"]) {
+					c.errorf(call.Field("item"), "MapContains item has type %s, but map keys are %s", fields["item"], m.Key)
+				}
+			default:
+				if m != Unknown {
+					c.errorf(call.Field("map"), "MapContains map must be a map, got %s", m)
+				}
+			}
+			return Boolean
+		},
+	},
+	"Range": {
+		fields: map[string]field{"start": required(Integer), "stop": required(Integer), "step": optional(Integer)},
+		result: fixed(&List{Elem: Integer}),
+	},
+	"Sum": {
+		fields: map[string]field{"sequence": required(&List{Elem: Integer})},
+		result: fixed(Integer),
+	},
+	"Min": {
+		fields: map[string]field{"sequence": required(&List{Elem: Integer})},
+		result: fixed(Integer),
+	},
+	"Max": {
+		fields: map[string]field{"sequence": required(&List{Elem: Integer})},
+		result: fixed(Integer),
+	},
+	"Unique": {
+		fields: map[string]field{"sequence": required(nil)},
+		result: func(c *checker, call *gate.StructCallExpr, fields map[string]Type, _ Type) Type {
+			return &List{Elem: c.sequenceElem(call, "sequence", fields)}
+		},
+	},
+	"Zip": {
+		fields: map[string]field{"first": required(nil), "second": required(nil)},
+		result: func(c *checker, call *gate.StructCallExpr, fields map[string]Type, _ Type) Type {
+			first := c.sequenceElem(call, "first", fields)
+			second := c.sequenceElem(call, "second", fields)
+			return &List{Elem: &Tuple{Elems: []Type{first, second}}}
+		},
+	},
+}
+
+// IsChainRead reports whether the named builtin reads the chain: contract calls and events, blocks, state or
+// the trace of the transaction. Tests replace the sources calling them with mocks.
+func IsChainRead(name string) bool {
+	switch name {
+	case "BlockNumber", "BlockTimestamp", "BlockHash", "StateRoot", "StorageHash", "FilterAddressesInTrace":
+		return true
+	}
+	return HasSignature(name)
+}
+
+// HasSignature reports whether the named builtin reads contract calls or events described by an ABI signature.
+func HasSignature(name string) bool {
+	switch name {
+	case "Call", "Calls", "Events", "HistoricalCalls", "HistoricalEvents":
+		return true
+	}
+	return false
+}
+
+func (c *checker) sequenceElem(call *gate.StructCallExpr, name string, fields map[string]Type) Type {
+	elem, ok := elemType(fields[name])
+	if !ok {
+		c.errorf(call.Field(name), "%s %s must be a list, got %s", call.Fun.Name, name, fields[name])
+		return Unknown
+	}
+	return elem
+}
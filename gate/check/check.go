@@ -0,0 +1,450 @@
+// Package check type checks parsed gate files. It infers the type of every expression, verifies values
+// against the declared types of their sources and reports mismatches with their file positions.
+package check
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// Diagnostic is a single type error.
+type Diagnostic struct {
+	Pos gate.Pos
+	Msg string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Msg)
+}
+
+// Info holds the results of type checking a file.
+type Info struct {
+	// Types maps every checked expression to its inferred type.
+	Types map[gate.Expr]Type
+	// Diagnostics lists every type error, sorted by position.
+	Diagnostics []Diagnostic
+}
+
+type checker struct {
+	file    *gate.File
+	info    *Info
+	imports map[string]bool
+	globals map[string]Type
+}
+
+// scope binds comprehension variables.
+type scope struct {
+	name   string
+	typ    Type
+	parent *scope
+}
+
+func (s *scope) lookup(name string) (Type, bool) {
+	for ; s != nil; s = s.parent {
+		if s.name == name {
+			return s.typ, true
+		}
+	}
+	return nil, false
+}
+
+// Check type checks a parsed gate file.
+func Check(file *gate.File) *Info {
+	c := &checker{
+		file:    file,
+		info:    &Info{Types: make(map[gate.Expr]Type)},
+		imports: make(map[string]bool),
+		globals: make(map[string]Type),
+	}
+
+	for _, use := range file.Uses() {
+		for _, name := range use.Names {
+			if c.imports[name.Name] {
+				c.errorf(name, "%s imported more than once", name.Name)
+			}
+			c.imports[name.Name] = true
+		}
+	}
+
+	// params and sources can be referenced before they are declared, so collect their types first
+	for _, decl := range file.Decls {
+		var name *gate.Ident
+		var typeExpr gate.TypeExpr
+		switch d := decl.(type) {
+		case *gate.ParamDecl:
+			name, typeExpr = d.Name, d.Type
+		case *gate.SourceDecl:
+			name, typeExpr = d.Name, d.Type
+		default:
+			continue
+		}
+		typ, err := FromTypeExpr(typeExpr)
+		if err != nil {
+			c.errorf(typeExpr, "%v", err)
+		}
+		if _, ok := c.globals[name.Name]; ok {
+			c.errorf(name, "%s redeclared", name.Name)
+			continue
+		}
+		c.globals[name.Name] = typ
+	}
+
+	for _, source := range file.Sources() {
+		declared := c.globals[source.Name.Name]
+		typ := c.expr(source.Value, nil, declared)
+		if !assignable(typ, declared) {
+			c.errorf(source.Value, "source %s declared as %s but value has type %s", source.Name.Name, declared, typ)
+		}
+	}
+
+	for _, invariant := range file.Invariants() {
+		c.invariant(invariant)
+	}
+
+	sort.SliceStable(c.info.Diagnostics, func(i, j int) bool {
+		return c.info.Diagnostics[i].Pos.Offset < c.info.Diagnostics[j].Pos.Offset
+	})
+	return c.info
+}
+
+func (c *checker) errorf(node gate.Node, format string, args ...any) {
+	c.info.Diagnostics = append(c.info.Diagnostics, Diagnostic{Pos: node.Pos(), Msg: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) invariant(invariant *gate.InvariantDecl) {
+	for _, field := range invariant.Fields {
+		switch field.Name.Name {
+		case "description":
+			if lit, ok := field.Value.(*gate.BasicLit); !ok || lit.Kind != gate.STRING {
+				c.errorf(field.Value, "invariant description must be a string literal")
+			}
+		case "condition":
+			if typ := c.expr(field.Value, nil, Boolean); !Identical(typ, Boolean) {
+				c.errorf(field.Value, "invariant condition must be boolean, got %s", typ)
+			}
+		default:
+			c.errorf(field.Name, "unknown invariant field %s", field.Name.Name)
+		}
+	}
+	if invariant.Field("description") == nil {
+		c.errorf(invariant, "invariant is missing a description")
+	}
+	if invariant.Condition() == nil {
+		c.errorf(invariant, "invariant is missing a condition")
+	}
+}
+
+// assignable reports whether a value of type typ can be stored in a source declared as declared.
+// Lists without a known element type, such as [] or list(), are assignable to every list.
+func assignable(typ, declared Type) bool {
+	if list, ok := typ.(*List); ok && list.Elem == Unknown {
+		_, ok := declared.(*List)
+		return ok || declared == Unknown
+	}
+	return Identical(typ, declared)
+}
+
+// expr infers the type of x. The expected type comes from the context, such as the declared type of a source,
+// and is only used to type chain reads whose result shape is given by their ABI signature.
+func (c *checker) expr(x gate.Expr, sc *scope, expected Type) Type {
+	typ := c.exprInternal(x, sc, expected)
+	c.info.Types[x] = typ
+	return typ
+}
+
+func (c *checker) exprInternal(x gate.Expr, sc *scope, expected Type) Type {
+	switch x := x.(type) {
+	case *gate.Ident:
+		if typ, ok := sc.lookup(x.Name); ok {
+			return typ
+		}
+		if typ, ok := c.globals[x.Name]; ok {
+			return typ
+		}
+		c.errorf(x, "undefined: %s", x.Name)
+		return Unknown
+
+	case *gate.BasicLit:
+		switch x.Kind {
+		case gate.INT:
+			return Integer
+		case gate.HEX:
+			if len(x.Value) == 42 {
+				return Address
+			}
+			return Bytes
+		case gate.STRING:
+			return String
+		}
+		return Unknown
+
+	case *gate.BoolLit:
+		return Boolean
+
+	case *gate.ParenExpr:
+		return c.expr(x.X, sc, expected)
+
+	case *gate.UnaryExpr:
+		switch x.Op {
+		case gate.NOT:
+			c.operand(x.X, sc, Boolean, x.Op)
+			return Boolean
+		case gate.SUB:
+			c.operand(x.X, sc, Integer, x.Op)
+			return Integer
+		}
+		c.errorf(x, "unsupported unary operator %s", x.Op)
+		return Unknown
+
+	case *gate.BinaryExpr:
+		return c.binary(x, sc)
+
+	case *gate.TernaryExpr:
+		if typ := c.expr(x.Cond, sc, Boolean); !Identical(typ, Boolean) {
+			c.errorf(x.Cond, "ternary condition must be boolean, got %s", typ)
+		}
+		then := c.expr(x.Then, sc, expected)
+		els := c.expr(x.Else, sc, expected)
+		if !Identical(then, els) {
+			c.errorf(x, "ternary branches have mismatched types %s and %s", then, els)
+			return Unknown
+		}
+		if then == Unknown {
+			return els
+		}
+		return then
+
+	case *gate.IndexExpr:
+		return c.index(x, sc)
+
+	case *gate.CallExpr:
+		return c.call(x, sc, expected)
+
+	case *gate.StructCallExpr:
+		return c.structCall(x, sc, expected)
+
+	case *gate.ListLit:
+		var elemExpected Type
+		if list, ok := expected.(*List); ok {
+			elemExpected = list.Elem
+		}
+		return &List{Elem: c.elements(x, x.Elems, sc, elemExpected)}
+
+	case *gate.ListComp:
+		var elemExpected Type
+		if list, ok := expected.(*List); ok {
+			elemExpected = list.Elem
+		}
+		inner := c.comprehension(x.Var, x.Seq, x.Cond, sc)
+		return &List{Elem: c.expr(x.Elem, inner, elemExpected)}
+
+	case *gate.MapComp:
+		var keyExpected, valueExpected Type
+		if m, ok := expected.(*Map); ok {
+			keyExpected, valueExpected = m.Key, m.Value
+		}
+		inner := c.comprehension(x.Var, x.Seq, x.Cond, sc)
+		return &Map{Key: c.expr(x.Key, inner, keyExpected), Value: c.expr(x.Value, inner, valueExpected)}
+	}
+
+	c.errorf(x, "unsupported expression %T", x)
+	return Unknown
+}
+
+func (c *checker) operand(x gate.Expr, sc *scope, want Type, op gate.Token) {
+	if typ := c.expr(x, sc, want); !Identical(typ, want) {
+		c.errorf(x, "operator %s expects %s operand, got %s", op, want, typ)
+	}
+}
+
+func (c *checker) binary(x *gate.BinaryExpr, sc *scope) Type {
+	switch x.Op {
+	case gate.AND, gate.OR:
+		c.operand(x.X, sc, Boolean, x.Op)
+		c.operand(x.Y, sc, Boolean, x.Op)
+		return Boolean
+	}
+
+	left := c.expr(x.X, sc, nil)
+	right := c.expr(x.Y, sc, left)
+	if left == Unknown {
+		left = right
+	}
+
+	switch x.Op {
+	case gate.EQL, gate.NEQ:
+		if !Identical(left, right) {
+			c.errorf(x, "mismatched types %s and %s in comparison %s", left, right, x.Op)
+		}
+		return Boolean
+	case gate.LSS, gate.LEQ, gate.GTR, gate.GEQ:
+		if !Identical(left, Integer) || !Identical(right, Integer) {
+			c.errorf(x, "operator %s expects integer operands, got %s and %s", x.Op, left, right)
+		}
+		return Boolean
+	case gate.ADD:
+		// + also concatenates bytes and strings
+		if Identical(left, right) && (left == Integer || left == Bytes || left == String || left == Unknown) {
+			return left
+		}
+		c.errorf(x, "invalid operation: %s + %s", left, right)
+		return Unknown
+	case gate.SUB, gate.MUL, gate.QUO, gate.REM, gate.POW:
+		if !Identical(left, Integer) || !Identical(right, Integer) {
+			c.errorf(x, "operator %s expects integer operands, got %s and %s", x.Op, left, right)
+		}
+		return Integer
+	}
+
+	c.errorf(x, "unsupported binary operator %s", x.Op)
+	return Unknown
+}
+
+func (c *checker) index(x *gate.IndexExpr, sc *scope) Type {
+	container := c.expr(x.X, sc, nil)
+	index := c.expr(x.Index, sc, nil)
+
+	switch t := container.(type) {
+	case *List:
+		if !Identical(index, Integer) {
+			c.errorf(x.Index, "list index must be integer, got %s", index)
+		}
+		return t.Elem
+	case *Map:
+		if !Identical(index, t.Key) {
+			c.errorf(x.Index, "map key must be %s, got %s", t.Key, index)
+		}
+		return t.Value
+	case *Tuple:
+		// tuple elements have different types, so the index has to be known statically
+		lit, ok := x.Index.(*gate.BasicLit)
+		if !ok || lit.Kind != gate.INT {
+			c.errorf(x.Index, "tuple index must be an integer literal")
+			return Unknown
+		}
+		i, ok := new(big.Int).SetString(lit.Value, 10)
+		if !ok || !i.IsInt64() || i.Int64() >= int64(len(t.Elems)) {
+			c.errorf(x, "index %s out of range for %s with %d elements", lit.Value, t, len(t.Elems))
+			return Unknown
+		}
+		return t.Elems[i.Int64()]
+	}
+
+	if container != Unknown {
+		c.errorf(x, "cannot index %s", container)
+	}
+	return Unknown
+}
+
+func (c *checker) elements(node gate.Node, elems []gate.Expr, sc *scope, expected Type) Type {
+	var typ Type = Unknown
+	for _, elem := range elems {
+		elemType := c.expr(elem, sc, expected)
+		if typ == Unknown {
+			typ = elemType
+		} else if !Identical(typ, elemType) {
+			c.errorf(elem, "list element has type %s, expected %s", elemType, typ)
+		}
+	}
+	return typ
+}
+
+func (c *checker) comprehension(variable *gate.Ident, seq, cond gate.Expr, sc *scope) *scope {
+	seqType := c.expr(seq, sc, nil)
+	elem, ok := elemType(seqType)
+	if !ok {
+		c.errorf(seq, "cannot iterate over %s", seqType)
+		elem = Unknown
+	}
+
+	inner := &scope{name: variable.Name, typ: elem, parent: sc}
+	c.info.Types[variable] = elem
+	if cond != nil {
+		if typ := c.expr(cond, inner, Boolean); !Identical(typ, Boolean) {
+			c.errorf(cond, "comprehension filter must be boolean, got %s", typ)
+		}
+	}
+	return inner
+}
+
+func (c *checker) call(x *gate.CallExpr, sc *scope, expected Type) Type {
+	switch x.Fun.Name {
+	case "tuple":
+		var elemsExpected []Type
+		if tuple, ok := expected.(*Tuple); ok && len(tuple.Elems) == len(x.Args) {
+			elemsExpected = tuple.Elems
+		}
+		tuple := &Tuple{Elems: make([]Type, len(x.Args))}
+		for i, arg := range x.Args {
+			var want Type
+			if elemsExpected != nil {
+				want = elemsExpected[i]
+			}
+			tuple.Elems[i] = c.expr(arg, sc, want)
+		}
+		return tuple
+	case "list":
+		var elemExpected Type
+		if list, ok := expected.(*List); ok {
+			elemExpected = list.Elem
+		}
+		return &List{Elem: c.elements(x, x.Args, sc, elemExpected)}
+	case "bytes":
+		if len(x.Args) != 1 {
+			c.errorf(x, "bytes expects 1 argument, got %d", len(x.Args))
+			return Bytes
+		}
+		if typ := c.expr(x.Args[0], sc, nil); !Identical(typ, Bytes) && !Identical(typ, Address) {
+			c.errorf(x.Args[0], "cannot convert %s to bytes", typ)
+		}
+		return Bytes
+	}
+
+	c.errorf(x.Fun, "unknown function %s", x.Fun.Name)
+	return Unknown
+}
+
+func (c *checker) structCall(x *gate.StructCallExpr, sc *scope, expected Type) Type {
+	name := x.Fun.Name
+	spec, ok := builtins[name]
+	if !ok {
+		c.errorf(x.Fun, "unknown builtin %s", name)
+		for _, field := range x.Fields {
+			c.expr(field.Value, sc, nil)
+		}
+		return Unknown
+	}
+	if !c.imports[name] {
+		c.errorf(x.Fun, "%s is not imported from hexagate", name)
+	}
+
+	fields := make(map[string]Type, len(x.Fields))
+	for _, f := range x.Fields {
+		spec, ok := spec.fields[f.Name.Name]
+		if !ok {
+			c.errorf(f.Name, "unknown field %s in %s", f.Name.Name, name)
+			c.expr(f.Value, sc, nil)
+			continue
+		}
+		if _, ok := fields[f.Name.Name]; ok {
+			c.errorf(f.Name, "duplicate field %s in %s", f.Name.Name, name)
+		}
+		typ := c.expr(f.Value, sc, spec.typ)
+		if spec.typ != nil && !assignable(typ, spec.typ) {
+			c.errorf(f.Value, "%s field %s must be %s, got %s", name, f.Name.Name, spec.typ, typ)
+		}
+		fields[f.Name.Name] = typ
+	}
+
+	for fieldName, f := range spec.fields {
+		if _, ok := fields[fieldName]; !ok && f.required {
+			c.errorf(x, "%s is missing required field %s", name, fieldName)
+			fields[fieldName] = Unknown
+		}
+	}
+
+	return spec.result(c, x, fields, expected)
+}
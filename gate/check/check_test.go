@@ -0,0 +1,134 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+func TestCheckMonitors(t *testing.T) {
+	// We expect every monitor to type check without diagnostics
+	paths, err := filepath.Glob("../../monitors/*.gate")
+	if err != nil {
+		t.Fatalf("Error listing monitors: %v", err)
+	}
+	if len(paths) != 10 {
+		t.Fatalf("Expected 10 monitors, found %d", len(paths))
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Error reading %s: %v", path, err)
+			}
+			file, err := gate.ParseFile(path, src)
+			if err != nil {
+				t.Fatalf("Error parsing %s: %v", path, err)
+			}
+
+			for _, d := range Check(file).Diagnostics {
+				t.Errorf("Unexpected diagnostic %s", d)
+			}
+		})
+	}
+}
+
+func TestCheckDiagnostics(t *testing.T) {
+	header := `use Call, Len, Range from hexagate;
+param disputeGame: address;
+source games: list<tuple<integer, tuple<address, integer>>> = list();
+`
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "tuple index out of range",
+			src:      "source x: integer = games[0][1][2];",
+			expected: "test.gate:4:21: index 2 out of range for tuple<address, integer> with 2 elements",
+		},
+		{
+			name:     "mismatched comparison",
+			src:      "source x: boolean = disputeGame == 1;",
+			expected: "test.gate:4:21: mismatched types address and integer in comparison ==",
+		},
+		{
+			name:     "undefined identifier",
+			src:      "source x: integer = claimCount;",
+			expected: "test.gate:4:21: undefined: claimCount",
+		},
+		{
+			name:     "builtin not imported",
+			src:      "source x: integer = Sum { sequence: list(1) };",
+			expected: "test.gate:4:21: Sum is not imported from hexagate",
+		},
+		{
+			name:     "wrong declared type",
+			src:      "source x: address = Len { sequence: games };",
+			expected: "test.gate:4:21: source x declared as address but value has type integer",
+		},
+		{
+			name:     "tuple element type",
+			src:      "source x: list<address> = [g[1][1] for g in games];",
+			expected: "test.gate:4:27: source x declared as list<address> but value has type list<integer>",
+		},
+		{
+			name:     "missing required field",
+			src:      "source x: integer = Call { signature: \"function x() view returns (uint256)\" };",
+			expected: "test.gate:4:21: Call is missing required field contract",
+		},
+		{
+			name:     "non boolean condition",
+			src:      "invariant { description: \"x\", condition: Len { sequence: games } };",
+			expected: "test.gate:4:42: invariant condition must be boolean, got integer",
+		},
+		{
+			name:     "ternary branches",
+			src:      "source x: integer = disputeGame == disputeGame ? 1 : \"one\";",
+			expected: "test.gate:4:21: ternary branches have mismatched types integer and string",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, err := gate.ParseFile("test.gate", []byte(header+test.src))
+			if err != nil {
+				t.Fatalf("Error parsing: %v", err)
+			}
+
+			var got []string
+			for _, d := range Check(file).Diagnostics {
+				got = append(got, d.String())
+			}
+			if len(got) != 1 || got[0] != test.expected {
+				t.Errorf("Expected diagnostic %q, got %q", test.expected, strings.Join(got, "; "))
+			}
+		})
+	}
+}
+
+func TestCheckContextualChainReads(t *testing.T) {
+	// chain reads take the declared type of their source, which then types the expressions using them
+	src := `use Call from hexagate;
+param disputeGame: address;
+source rootClaim: tuple<integer, address> = Call { contract: disputeGame, signature: "function x() view returns (uint32, address)" };
+source claimant: address = rootClaim[1];
+`
+	file, err := gate.ParseFile("test.gate", []byte(src))
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+
+	info := Check(file)
+	if len(info.Diagnostics) != 0 {
+		t.Fatalf("Unexpected diagnostics %v", info.Diagnostics)
+	}
+	if got := info.Types[file.Source("claimant").Value]; got != Address {
+		t.Errorf("Expected claimant to be an address, got %v", got)
+	}
+}
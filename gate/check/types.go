@@ -0,0 +1,138 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// Type is the static type of a gate expression.
+type Type interface {
+	String() string
+}
+
+// Basic is one of the primitive gate types.
+type Basic string
+
+const (
+	Integer Basic = "integer"
+	Address Basic = "address"
+	Bytes   Basic = "bytes"
+	Boolean Basic = "boolean"
+	String  Basic = "string"
+
+	// Unknown is the type of expressions that could not be typed, either because of an earlier error or
+	// because the value comes from a chain builtin without a declared type. It is compatible with every type.
+	Unknown Basic = "unknown"
+)
+
+func (b Basic) String() string { return string(b) }
+
+// List is list<Elem>.
+type List struct {
+	Elem Type
+}
+
+func (l *List) String() string { return "list<" + l.Elem.String() + ">" }
+
+// Tuple is tuple<Elems...>.
+type Tuple struct {
+	Elems []Type
+}
+
+func (t *Tuple) String() string {
+	elems := make([]string, len(t.Elems))
+	for i, elem := range t.Elems {
+		elems[i] = elem.String()
+	}
+	return "tuple<" + strings.Join(elems, ", ") + ">"
+}
+
+// Map is map<Key, Value>.
+type Map struct {
+	Key   Type
+	Value Type
+}
+
+func (m *Map) String() string { return "map<" + m.Key.String() + ", " + m.Value.String() + ">" }
+
+var basics = map[string]Basic{
+	"integer": Integer,
+	"address": Address,
+	"bytes":   Bytes,
+	"boolean": Boolean,
+	"string":  String,
+}
+
+// FromTypeExpr converts a declared type into a Type.
+func FromTypeExpr(expr gate.TypeExpr) (Type, error) {
+	switch t := expr.(type) {
+	case *gate.NamedType:
+		basic, ok := basics[t.Name]
+		if !ok {
+			return Unknown, fmt.Errorf("unknown type %s", t.Name)
+		}
+		return basic, nil
+	case *gate.ListType:
+		elem, err := FromTypeExpr(t.Elem)
+		return &List{Elem: elem}, err
+	case *gate.TupleType:
+		tuple := &Tuple{Elems: make([]Type, len(t.Elems))}
+		for i, elem := range t.Elems {
+			var err error
+			if tuple.Elems[i], err = FromTypeExpr(elem); err != nil {
+				return tuple, err
+			}
+		}
+		return tuple, nil
+	case *gate.MapType:
+		key, err := FromTypeExpr(t.Key)
+		if err != nil {
+			return &Map{Key: key, Value: Unknown}, err
+		}
+		value, err := FromTypeExpr(t.Value)
+		return &Map{Key: key, Value: value}, err
+	}
+	return Unknown, fmt.Errorf("unsupported type %T", expr)
+}
+
+// Identical reports whether a and b are the same type. Unknown is identical to every type.
+func Identical(a, b Type) bool {
+	if a == Unknown || b == Unknown {
+		return true
+	}
+	switch a := a.(type) {
+	case Basic:
+		return a == b
+	case *List:
+		b, ok := b.(*List)
+		return ok && Identical(a.Elem, b.Elem)
+	case *Tuple:
+		b, ok := b.(*Tuple)
+		if !ok || len(a.Elems) != len(b.Elems) {
+			return false
+		}
+		for i := range a.Elems {
+			if !Identical(a.Elems[i], b.Elems[i]) {
+				return false
+			}
+		}
+		return true
+	case *Map:
+		b, ok := b.(*Map)
+		return ok && Identical(a.Key, b.Key) && Identical(a.Value, b.Value)
+	}
+	return false
+}
+
+// elemType returns the element type of a list, or false if t is not a list.
+func elemType(t Type) (Type, bool) {
+	if t == Unknown {
+		return Unknown, true
+	}
+	if list, ok := t.(*List); ok {
+		return list.Elem, true
+	}
+	return nil, false
+}
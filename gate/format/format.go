@@ -0,0 +1,376 @@
+// Package format prints gate files in canonical form.
+//
+// Declarations are separated by at most one blank line, types are written as list<tuple<integer, address>>,
+// blocks are indented with 4 spaces and struct calls, lists and comprehensions are either kept on one line
+// or broken with one element per line, depending on whether they spanned several lines in the source. Line
+// breaks around binary and ternary operators are kept where the author put them. Comments are preserved.
+package format
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// INDENT is the indentation of every nested block.
+const INDENT = 4
+
+// Source formats gate source code. The filename is only used in error messages.
+func Source(filename string, src []byte) ([]byte, error) {
+	file, err := gate.ParseFile(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	return File(file), nil
+}
+
+// File prints a parsed gate file, including its comments, in canonical form.
+func File(file *gate.File) []byte {
+	p := &printer{comments: file.Comments}
+	for i, decl := range file.Decls {
+		if i > 0 {
+			p.linebreak(0)
+		}
+		p.decl(decl)
+	}
+	p.cont = 0
+	for p.next < len(p.comments) {
+		p.comment(p.comments[p.next])
+		p.next++
+	}
+	p.buf.WriteByte('\n')
+	return p.buf.Bytes()
+}
+
+type printer struct {
+	buf      bytes.Buffer
+	comments []*gate.Comment
+	next     int // index of the next comment to print
+
+	// lastLine is the source line of the last printed token or comment, used to keep trailing comments on
+	// their line and to preserve blank lines.
+	lastLine int
+	// indent is the indentation of the current output line and cont the indentation of continuation lines
+	// of the expression being printed.
+	indent int
+	cont   int
+
+	pendingBreak bool
+	breakIndent  int
+	pendingSpace bool
+	// needBreak is set after a line comment, which the next token must not follow on the same line.
+	needBreak bool
+}
+
+func (p *printer) space() {
+	p.pendingSpace = true
+}
+
+func (p *printer) linebreak(indent int) {
+	p.pendingBreak = true
+	p.breakIndent = indent
+	p.pendingSpace = false
+}
+
+// flush prints the comments that appear in the source before pos.
+func (p *printer) flush(pos gate.Pos) {
+	for p.next < len(p.comments) && p.comments[p.next].Pos().Offset < pos.Offset {
+		c := p.comments[p.next]
+		p.next++
+		p.comment(c)
+	}
+}
+
+func (p *printer) comment(c *gate.Comment) {
+	text := strings.TrimRight(c.Text, " \t")
+	if p.lastLine > 0 && c.Pos().Line == p.lastLine {
+		// trailing comment, kept on the line of the token before it
+		p.buf.WriteString(" " + text)
+	} else {
+		if !p.pendingBreak && p.buf.Len() > 0 {
+			p.linebreak(p.cont)
+		}
+		indent := p.breakIndent
+		p.emit(c.Pos(), text)
+		p.linebreak(indent)
+	}
+	p.lastLine = c.End().Line
+	if strings.HasPrefix(text, "//") {
+		p.needBreak = true
+	}
+}
+
+// emit prints a token, preceded by the comments before it and any pending space or line break. Tokens
+// without a source position, such as commas, are printed as is.
+func (p *printer) emit(pos gate.Pos, text string) {
+	if pos.IsValid() {
+		p.flush(pos)
+	}
+	if p.needBreak && !p.pendingBreak {
+		p.linebreak(p.cont)
+	}
+
+	if p.pendingBreak {
+		if p.buf.Len() > 0 {
+			p.buf.WriteByte('\n')
+			// keep at most one blank line from the source
+			if pos.IsValid() && p.lastLine > 0 && pos.Line-p.lastLine > 1 {
+				p.buf.WriteByte('\n')
+			}
+		}
+		p.buf.WriteString(strings.Repeat(" ", p.breakIndent))
+		p.indent = p.breakIndent
+		p.pendingBreak = false
+	} else if p.pendingSpace {
+		p.buf.WriteByte(' ')
+	}
+	p.pendingSpace = false
+	p.needBreak = false
+
+	p.buf.WriteString(text)
+	if pos.IsValid() {
+		p.lastLine = pos.Line
+	}
+}
+
+func (p *printer) decl(decl gate.Decl) {
+	p.cont = INDENT
+	switch d := decl.(type) {
+	case *gate.UseDecl:
+		p.emit(d.Use, "use")
+		for i, name := range d.Names {
+			if i > 0 {
+				p.emit(gate.Pos{}, ",")
+			}
+			p.space()
+			p.emit(name.NamePos, name.Name)
+		}
+		p.space()
+		p.emit(d.From, "from")
+		p.space()
+		p.emit(d.Module.NamePos, d.Module.Name)
+		p.emit(d.Semicolon, ";")
+
+	case *gate.ParamDecl:
+		p.emit(d.Param, "param")
+		p.space()
+		p.emit(d.Name.NamePos, d.Name.Name)
+		p.emit(gate.Pos{}, ":")
+		p.space()
+		p.emit(d.Type.Pos(), gate.TypeString(d.Type))
+		p.emit(d.Semicolon, ";")
+
+	case *gate.SourceDecl:
+		p.emit(d.Source, "source")
+		p.space()
+		p.emit(d.Name.NamePos, d.Name.Name)
+		p.emit(gate.Pos{}, ":")
+		p.space()
+		p.emit(d.Type.Pos(), gate.TypeString(d.Type))
+		p.space()
+		p.emit(d.Assign, "=")
+		p.space()
+		p.expr(d.Value)
+		p.emit(d.Semicolon, ";")
+
+	case *gate.InvariantDecl:
+		p.emit(d.Invariant, "invariant")
+		p.space()
+		p.emit(d.Lbrace, "{")
+		p.fields(d.Fields, true)
+		p.emit(d.Rbrace, "}")
+		p.emit(d.Semicolon, ";")
+	}
+}
+
+// fields prints the fields of an invariant or struct call after the opening brace. Broken fields are
+// printed one per line, otherwise they are separated by spaces from the braces.
+func (p *printer) fields(fields []*gate.Field, broken bool) {
+	open, cont := p.indent, p.cont
+	for i, field := range fields {
+		if broken {
+			p.linebreak(open + INDENT)
+			p.cont = open + 2*INDENT
+		} else {
+			p.space()
+		}
+		p.emit(field.Name.NamePos, field.Name.Name)
+		p.emit(field.Colon, ":")
+		p.space()
+		p.expr(field.Value)
+		if i < len(fields)-1 {
+			p.emit(gate.Pos{}, ",")
+		}
+	}
+	p.cont = cont
+	if broken {
+		p.linebreak(open)
+	} else {
+		p.space()
+	}
+}
+
+// list prints comma separated expressions after an opening bracket or parenthesis.
+func (p *printer) list(elems []gate.Expr, broken bool) {
+	open, cont := p.indent, p.cont
+	for i, elem := range elems {
+		if broken {
+			p.linebreak(open + INDENT)
+			p.cont = open + 2*INDENT
+		} else if i > 0 {
+			p.space()
+		}
+		p.expr(elem)
+		if i < len(elems)-1 {
+			p.emit(gate.Pos{}, ",")
+		}
+	}
+	p.cont = cont
+	if broken {
+		p.linebreak(open)
+	}
+}
+
+// comprehension prints the body of a list or map comprehension after its element.
+func (p *printer) comprehension(open int, broken bool, forPos gate.Pos, v *gate.Ident, seq gate.Expr, ifPos gate.Pos, cond gate.Expr) {
+	if broken {
+		p.linebreak(open + INDENT)
+	} else {
+		p.space()
+	}
+	p.emit(forPos, "for")
+	p.space()
+	p.emit(v.NamePos, v.Name)
+	p.space()
+	p.emit(gate.Pos{}, "in")
+	p.space()
+	p.expr(seq)
+
+	if cond != nil {
+		if broken {
+			p.linebreak(open + INDENT)
+		} else {
+			p.space()
+		}
+		p.emit(ifPos, "if")
+		p.space()
+		p.expr(cond)
+	}
+	if broken {
+		p.linebreak(open)
+	}
+}
+
+// operator prints a binary or ternary operator, keeping a line break before or after it if the source had one.
+func (p *printer) operator(before gate.Node, pos gate.Pos, op string, after gate.Node) {
+	if pos.Line > before.End().Line {
+		p.linebreak(p.cont)
+	} else {
+		p.space()
+	}
+	p.emit(pos, op)
+	if after.Pos().Line > pos.Line {
+		p.linebreak(p.cont)
+	} else {
+		p.space()
+	}
+}
+
+func (p *printer) expr(x gate.Expr) {
+	switch x := x.(type) {
+	case *gate.Ident:
+		p.emit(x.NamePos, x.Name)
+
+	case *gate.BasicLit:
+		p.emit(x.ValuePos, x.Value)
+
+	case *gate.BoolLit:
+		if x.Value {
+			p.emit(x.ValuePos, "true")
+		} else {
+			p.emit(x.ValuePos, "false")
+		}
+
+	case *gate.ParenExpr:
+		p.emit(x.Lparen, "(")
+		p.expr(x.X)
+		p.emit(x.Rparen, ")")
+
+	case *gate.UnaryExpr:
+		p.emit(x.OpPos, x.Op.String())
+		p.expr(x.X)
+
+	case *gate.BinaryExpr:
+		p.expr(x.X)
+		p.operator(x.X, x.OpPos, x.Op.String(), x.Y)
+		p.expr(x.Y)
+
+	case *gate.TernaryExpr:
+		p.expr(x.Cond)
+		p.operator(x.Cond, x.Question, "?", x.Then)
+		p.expr(x.Then)
+		p.operator(x.Then, x.Colon, ":", x.Else)
+		p.expr(x.Else)
+
+	case *gate.IndexExpr:
+		p.expr(x.X)
+		p.emit(x.Lbrack, "[")
+		p.expr(x.Index)
+		p.emit(x.Rbrack, "]")
+
+	case *gate.CallExpr:
+		p.emit(x.Fun.NamePos, x.Fun.Name)
+		p.emit(x.Lparen, "(")
+		p.list(x.Args, len(x.Args) > 0 && x.Rparen.Line > x.Lparen.Line)
+		p.emit(x.Rparen, ")")
+
+	case *gate.StructCallExpr:
+		p.emit(x.Fun.NamePos, x.Fun.Name)
+		p.space()
+		p.emit(x.Lbrace, "{")
+		if len(x.Fields) > 0 {
+			p.fields(x.Fields, x.Rbrace.Line > x.Lbrace.Line)
+		}
+		p.emit(x.Rbrace, "}")
+
+	case *gate.ListLit:
+		p.emit(x.Lbrack, "[")
+		p.list(x.Elems, len(x.Elems) > 0 && x.Rbrack.Line > x.Lbrack.Line)
+		p.emit(x.Rbrack, "]")
+
+	case *gate.ListComp:
+		broken := x.Rbrack.Line > x.Lbrack.Line
+		p.emit(x.Lbrack, "[")
+		open, cont := p.indent, p.cont
+		if broken {
+			p.linebreak(open + INDENT)
+			p.cont = open + 2*INDENT
+		}
+		p.expr(x.Elem)
+		p.comprehension(open, broken, x.For, x.Var, x.Seq, x.If, x.Cond)
+		p.cont = cont
+		p.emit(x.Rbrack, "]")
+
+	case *gate.MapComp:
+		broken := x.Rbrace.Line > x.Lbrace.Line
+		p.emit(x.Lbrace, "{")
+		open, cont := p.indent, p.cont
+		if broken {
+			p.linebreak(open + INDENT)
+			p.cont = open + 2*INDENT
+		} else {
+			p.space()
+		}
+		p.expr(x.Key)
+		p.emit(x.Colon, ":")
+		p.space()
+		p.expr(x.Value)
+		p.comprehension(open, broken, x.For, x.Var, x.Seq, x.If, x.Cond)
+		p.cont = cont
+		if !broken {
+			p.space()
+		}
+		p.emit(x.Rbrace, "}")
+	}
+}
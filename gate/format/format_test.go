@@ -0,0 +1,221 @@
+package format
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestFormatMonitors(t *testing.T) {
+	// We expect every monitor to format to its golden file, and formatting to be idempotent and keep the syntax
+	// tree. The monitors themselves are left as they are deployed, as formatting them would change their hash.
+	paths, err := filepath.Glob("../../monitors/*.gate")
+	if err != nil {
+		t.Fatalf("Error listing monitors: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("Expected monitors to format")
+	}
+
+	for _, path := range paths {
+		name := filepath.Base(path)
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Error reading %s: %v", path, err)
+			}
+			formatted, err := Source(path, src)
+			if err != nil {
+				t.Fatalf("Error formatting %s: %v", path, err)
+			}
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, formatted, 0644); err != nil {
+					t.Fatalf("Error writing %s: %v", golden, err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Error reading %s: %v", golden, err)
+			}
+			if !bytes.Equal(formatted, expected) {
+				t.Errorf("Formatted %s does not match %s, run go test ./gate/format -update", path, golden)
+			}
+
+			again, err := Source(golden, formatted)
+			if err != nil {
+				t.Fatalf("Error formatting %s: %v", golden, err)
+			}
+			if !bytes.Equal(again, formatted) {
+				t.Errorf("Formatting %s is not idempotent", path)
+			}
+
+			if before, after := tree(t, path, src), tree(t, golden, formatted); before != after {
+				t.Errorf("Formatting %s changed the syntax tree", path)
+			}
+		})
+	}
+}
+
+// tree renders the node types, names and literals of a file without positions, so files that only differ
+// in layout render the same.
+func tree(t *testing.T, filename string, src []byte) string {
+	file, err := gate.ParseFile(filename, src)
+	if err != nil {
+		t.Fatalf("Error parsing %s: %v", filename, err)
+	}
+	var sb strings.Builder
+	gate.Inspect(file, func(node gate.Node) bool {
+		switch n := node.(type) {
+		case nil:
+			sb.WriteString(")")
+		case *gate.Ident:
+			fmt.Fprintf(&sb, "(%s", n.Name)
+		case *gate.BasicLit:
+			fmt.Fprintf(&sb, "(%s", n.Value)
+		case *gate.BinaryExpr:
+			fmt.Fprintf(&sb, "(%s", n.Op)
+		case *gate.UnaryExpr:
+			fmt.Fprintf(&sb, "(%s", n.Op)
+		case gate.TypeExpr:
+			fmt.Fprintf(&sb, "(%s", gate.TypeString(n))
+		default:
+			fmt.Fprintf(&sb, "(%T", n)
+		}
+		return true
+	})
+	for _, c := range file.Comments {
+		sb.WriteString(c.Text)
+	}
+	return sb.String()
+}
+
+func TestFormatLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name: "declarations",
+			src: `use   Len,Range from hexagate ;
+param  x :address;
+
+
+source  l: list< tuple<integer,address> > = list();
+source n: integer = BlockNumber{};
+`,
+			expected: `use Len, Range from hexagate;
+param x: address;
+
+source l: list<tuple<integer, address>> = list();
+source n: integer = BlockNumber {};
+`,
+		},
+		{
+			name: "struct calls",
+			src: `source a: integer = Len {sequence: l};
+source b: integer = Call {
+  contract: x,
+        signature: "function f() returns (uint256)"};
+`,
+			expected: `source a: integer = Len { sequence: l };
+source b: integer = Call {
+    contract: x,
+    signature: "function f() returns (uint256)"
+};
+`,
+		},
+		{
+			name: "comprehensions",
+			src: `source a: list<integer> = [x*2 for x in l if x>1];
+source b: map<integer, boolean> = {x:true for x in l};
+source c: list<integer> = [x
+  for x in l if x > 1];
+`,
+			expected: `source a: list<integer> = [x * 2 for x in l if x > 1];
+source b: map<integer, boolean> = { x: true for x in l };
+source c: list<integer> = [
+    x
+    for x in l
+    if x > 1
+];
+`,
+		},
+		{
+			name: "operators",
+			src: `invariant {
+  description: "x",
+  condition: (Len {sequence: t} > 0) ?
+      a
+  and b
+    : true
+};
+`,
+			expected: `invariant {
+    description: "x",
+    condition: (Len { sequence: t } > 0) ?
+        a
+        and b
+        : true
+};
+`,
+		},
+		{
+			name: "comments",
+			src: `// header
+
+// about x
+param x: address; // trailing
+source a: integer = Call {
+    contract: x, // the contract
+    // the signature
+    signature: "function f() returns (uint256)" /* block */
+};
+invariant { description: "x", condition: a > 0 // first
+  and a < 2 };
+// footer
+`,
+			expected: `// header
+
+// about x
+param x: address; // trailing
+source a: integer = Call {
+    contract: x, // the contract
+    // the signature
+    signature: "function f() returns (uint256)" /* block */
+};
+invariant {
+    description: "x",
+    condition: a > 0 // first
+        and a < 2
+};
+// footer
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Source("test.gate", []byte(test.src))
+			if err != nil {
+				t.Fatalf("Error formatting: %v", err)
+			}
+			if string(got) != test.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", test.expected, got)
+			}
+			if again, err := Source("test.gate", got); err != nil || !bytes.Equal(again, got) {
+				t.Errorf("Formatting is not idempotent, got:\n%s", again)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// CHAIN_COLOR fills the nodes of sources that read the chain in both output formats.
+const CHAIN_COLOR = "#fde2b0"
+
+// WriteDOT writes the graph in Graphviz DOT. Params are ellipses, sources boxes, filled when they read the
+// chain, and invariants octagons. Edges point from a value to the declarations computed from it.
+func WriteDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(name(g)))
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, n := range g.Nodes {
+		var attrs string
+		switch {
+		case n.Kind == PARAM:
+			attrs = fmt.Sprintf("shape=ellipse, label=%s", dotQuote("param "+n.Label+"\n"+n.Type))
+		case n.Kind == INVARIANT:
+			attrs = fmt.Sprintf("shape=octagon, label=%s", dotQuote(n.Label))
+		case n.ChainRead != "":
+			attrs = fmt.Sprintf("shape=box, style=filled, fillcolor=%s, label=%s", dotQuote(CHAIN_COLOR), dotQuote(n.Label+"\n"+n.Type+"\n"+n.ChainRead))
+		default:
+			attrs = fmt.Sprintf("shape=box, label=%s", dotQuote(n.Label+"\n"+n.Type))
+		}
+		fmt.Fprintf(&b, "\t%s [%s];\n", dotQuote(n.ID), attrs)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s;\n", dotQuote(e.From), dotQuote(e.To))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart with the same shapes and colors as WriteDOT.
+func WriteMermaid(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\ntitle: %s\n---\n", name(g))
+	b.WriteString("flowchart LR\n")
+	var chain []string
+	for _, n := range g.Nodes {
+		switch {
+		case n.Kind == PARAM:
+			fmt.Fprintf(&b, "    %s([%s])\n", n.ID, mermaidQuote("param "+n.Label+"<br>"+n.Type))
+		case n.Kind == INVARIANT:
+			fmt.Fprintf(&b, "    %s{{%s}}\n", n.ID, mermaidQuote(n.Label))
+		case n.ChainRead != "":
+			fmt.Fprintf(&b, "    %s[%s]\n", n.ID, mermaidQuote(n.Label+"<br>"+n.Type+"<br>"+n.ChainRead))
+			chain = append(chain, n.ID)
+		default:
+			fmt.Fprintf(&b, "    %s[%s]\n", n.ID, mermaidQuote(n.Label+"<br>"+n.Type))
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "    %s --> %s\n", e.From, e.To)
+	}
+	if len(chain) > 0 {
+		fmt.Fprintf(&b, "    classDef chain fill:%s\n", CHAIN_COLOR)
+		fmt.Fprintf(&b, "    class %s chain\n", strings.Join(chain, ","))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// name returns the monitor name without directory or extension, e.g. eth_deficit.
+func name(g *Graph) string {
+	if g.Name == "" {
+		return "monitor"
+	}
+	return strings.TrimSuffix(filepath.Base(g.Name), filepath.Ext(g.Name))
+}
+
+// dotQuote quotes s as a DOT string, keeping line breaks as centered \n escapes.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// mermaidQuote quotes s as a Mermaid label. Type arguments such as list<integer> are escaped as entities,
+// while the <br> line breaks added by WriteMermaid are kept.
+func mermaidQuote(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "<br>", "\x00")
+	s = strings.ReplaceAll(s, "<", "#lt;")
+	s = strings.ReplaceAll(s, ">", "#gt;")
+	s = strings.ReplaceAll(s, "\x00", "<br>")
+	return `"` + s + `"`
+}
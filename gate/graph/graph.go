@@ -0,0 +1,155 @@
+// Package graph builds the dependency graph of a gate monitor: which params and sources every source and
+// invariant is computed from, and which sources read the chain rather than compute from other values.
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/check"
+)
+
+// Kind is the kind of declaration a node stands for.
+type Kind string
+
+const (
+	PARAM     Kind = "param"
+	SOURCE    Kind = "source"
+	INVARIANT Kind = "invariant"
+)
+
+// Node is a param, source or invariant of the monitor.
+type Node struct {
+	ID    string   // unique name, the declared name for params and sources
+	Kind  Kind     // kind of declaration
+	Label string   // the declared name, or the description of an invariant
+	Type  string   // declared type of params and sources
+	Pos   gate.Pos // position of the declaration
+
+	// ChainRead is the builtin a source reads the chain with, e.g. Call, HistoricalEvents or
+	// FilterAddressesInTrace, or "" for sources computed from other values only
+	ChainRead string
+}
+
+// Edge is a dependency of To on the value of From.
+type Edge struct {
+	From, To string
+}
+
+// Graph is the dependency graph of a monitor. Nodes are in declaration order and edges are sorted.
+type Graph struct {
+	Name  string
+	Nodes []*Node
+	Edges []Edge
+}
+
+// Build returns the dependency graph of file. References to names that are not declared are left out, as
+// they are reported by the type checker.
+func Build(file *gate.File) *Graph {
+	g := &Graph{Name: file.Name}
+	declared := make(map[string]bool)
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *gate.ParamDecl:
+			g.Nodes = append(g.Nodes, &Node{ID: d.Name.Name, Kind: PARAM, Label: d.Name.Name, Type: gate.TypeString(d.Type), Pos: d.Pos()})
+			declared[d.Name.Name] = true
+		case *gate.SourceDecl:
+			g.Nodes = append(g.Nodes, &Node{ID: d.Name.Name, Kind: SOURCE, Label: d.Name.Name, Type: gate.TypeString(d.Type), Pos: d.Pos(), ChainRead: chainRead(d.Value)})
+			declared[d.Name.Name] = true
+		}
+	}
+
+	invariants := 0
+	for _, decl := range file.Decls {
+		var id string
+		switch d := decl.(type) {
+		case *gate.SourceDecl:
+			id = d.Name.Name
+		case *gate.InvariantDecl:
+			id = fmt.Sprintf("invariant%d", invariants)
+			invariants++
+			g.Nodes = append(g.Nodes, &Node{ID: id, Kind: INVARIANT, Label: d.Description(), Pos: d.Pos()})
+		default:
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, ref := range gate.References(decl) {
+			if declared[ref.Name] && !seen[ref.Name] {
+				seen[ref.Name] = true
+				g.Edges = append(g.Edges, Edge{From: ref.Name, To: id})
+			}
+		}
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g
+}
+
+// chainRead returns the name of the first builtin called in x that reads the chain, or "".
+func chainRead(x gate.Expr) string {
+	var builtin string
+	gate.Inspect(x, func(node gate.Node) bool {
+		if call, ok := node.(*gate.StructCallExpr); ok && builtin == "" && check.IsChainRead(call.Fun.Name) {
+			builtin = call.Fun.Name
+		}
+		return builtin == ""
+	})
+	return builtin
+}
+
+// Node returns the node with the given ID, or nil.
+func (g *Graph) Node(id string) *Node {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// Invariants returns the invariant nodes in declaration order.
+func (g *Graph) Invariants() []*Node {
+	var invariants []*Node
+	for _, n := range g.Nodes {
+		if n.Kind == INVARIANT {
+			invariants = append(invariants, n)
+		}
+	}
+	return invariants
+}
+
+// Mocks returns the sources that must be mocked to evaluate the node with the given ID without reading the
+// chain: the chain reading sources it depends on, not counting those only needed to compute another mocked
+// source. The names are sorted.
+func (g *Graph) Mocks(id string) []string {
+	deps := make(map[string][]string)
+	for _, e := range g.Edges {
+		deps[e.To] = append(deps[e.To], e.From)
+	}
+
+	var mocks []string
+	seen := map[string]bool{id: true}
+	queue := deps[id]
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if n := g.Node(name); n != nil && n.ChainRead != "" {
+			// a mocked source is not evaluated, so its own dependencies are not needed
+			mocks = append(mocks, name)
+			continue
+		}
+		queue = append(queue, deps[name]...)
+	}
+	sort.Strings(mocks)
+	return mocks
+}
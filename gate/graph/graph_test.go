@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+const MONITOR = `use Call, Len, FilterAddressesInTrace from hexagate;
+
+param disputeGame: address;
+
+source addressesInTrace: list<address> = FilterAddressesInTrace { addresses: list(disputeGame) };
+source delayedWETH: address = Call { contract: disputeGame, signature: "function weth() returns (address)" };
+source balance: integer = Call {
+    contract: delayedWETH,
+    signature: "function balanceOf(address) returns (uint256)",
+    params: tuple(disputeGame)
+};
+source credits: list<integer> = [c for c in list(balance, balance) if c > 0];
+source total: integer = Len { sequence: credits };
+
+invariant {
+    description: "Deficit of ETH",
+    condition: (Len { sequence: addressesInTrace } > 0) ? total > 0 : true
+};
+`
+
+func build(t *testing.T) *Graph {
+	file, err := gate.ParseFile("monitors/example.gate", []byte(MONITOR))
+	if err != nil {
+		t.Fatalf("Error parsing monitor: %v", err)
+	}
+	return Build(file)
+}
+
+func TestBuild(t *testing.T) {
+	// We expect an edge from every referenced param and source, and chain reads to be marked
+	g := build(t)
+
+	var chain []string
+	for _, n := range g.Nodes {
+		if n.ChainRead != "" {
+			chain = append(chain, n.ID+":"+n.ChainRead)
+		}
+	}
+	if expected := []string{"addressesInTrace:FilterAddressesInTrace", "delayedWETH:Call", "balance:Call"}; !reflect.DeepEqual(chain, expected) {
+		t.Errorf("Unexpected chain reads:\n got %v\nwant %v", chain, expected)
+	}
+
+	// the comprehension variable c is not a dependency, and balance is referenced twice but has one edge
+	expected := []Edge{
+		{"addressesInTrace", "invariant0"},
+		{"balance", "credits"},
+		{"credits", "total"},
+		{"delayedWETH", "balance"},
+		{"disputeGame", "addressesInTrace"},
+		{"disputeGame", "balance"},
+		{"disputeGame", "delayedWETH"},
+		{"total", "invariant0"},
+	}
+	if !reflect.DeepEqual(g.Edges, expected) {
+		t.Errorf("Unexpected edges:\n got %v\nwant %v", g.Edges, expected)
+	}
+
+	invariants := g.Invariants()
+	if len(invariants) != 1 || invariants[0].Label != "Deficit of ETH" {
+		t.Errorf("Unexpected invariants %v", invariants)
+	}
+}
+
+func TestMocks(t *testing.T) {
+	// We expect the chain reads an invariant needs, without those only needed to compute a mocked source
+	g := build(t)
+	if mocks := g.Mocks("invariant0"); !reflect.DeepEqual(mocks, []string{"addressesInTrace", "balance"}) {
+		t.Errorf("Unexpected mocks %v", mocks)
+	}
+	if mocks := g.Mocks("balance"); !reflect.DeepEqual(mocks, []string{"delayedWETH"}) {
+		t.Errorf("Unexpected mocks for balance %v", mocks)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	// We expect chain reads to be filled and type arguments and descriptions to be quoted
+	var out bytes.Buffer
+	if err := WriteDOT(&out, build(t)); err != nil {
+		t.Fatalf("Error writing DOT: %v", err)
+	}
+	for _, line := range []string{
+		`digraph "example" {`,
+		`	"disputeGame" [shape=ellipse, label="param disputeGame\naddress"];`,
+		`	"balance" [shape=box, style=filled, fillcolor="#fde2b0", label="balance\ninteger\nCall"];`,
+		`	"credits" [shape=box, label="credits\nlist<integer>"];`,
+		`	"invariant0" [shape=octagon, label="Deficit of ETH"];`,
+		`	"delayedWETH" -> "balance";`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("Expected DOT output to contain %s, got:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	// We expect type arguments to be escaped so Mermaid does not read them as HTML
+	var out bytes.Buffer
+	if err := WriteMermaid(&out, build(t)); err != nil {
+		t.Fatalf("Error writing Mermaid: %v", err)
+	}
+	for _, line := range []string{
+		`flowchart LR`,
+		`    disputeGame(["param disputeGame<br>address"])`,
+		`    credits["credits<br>list#lt;integer#gt;"]`,
+		`    invariant0{{"Deficit of ETH"}}`,
+		`    delayedWETH --> balance`,
+		`    class addressesInTrace,delayedWETH,balance chain`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("Expected Mermaid output to contain %s, got:\n%s", line, out.String())
+		}
+	}
+}
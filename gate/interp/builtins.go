@@ -0,0 +1,252 @@
+package interp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// builtin computes a pure hexagate builtin from its evaluated struct fields.
+type builtin func(fields map[string]Value) (Value, error)
+
+var builtins = map[string]builtin{
+	"Contains":    builtinContains,
+	"Keccak256":   builtinKeccak256,
+	"Len":         builtinLen,
+	"MapContains": builtinMapContains,
+	"Max":         builtinMax,
+	"Min":         builtinMin,
+	"Range":       builtinRange,
+	"Sum":         builtinSum,
+	"Unique":      builtinUnique,
+	"Zip":         builtinZip,
+}
+
+// IsBuiltin reports whether name is a hexagate builtin the interpreter knows about.
+func IsBuiltin(name string) bool {
+	_, ok := builtins[name]
+	return ok || IsChainBuiltin(name)
+}
+
+func field(fields map[string]Value, name string) (Value, error) {
+	value, ok := fields[name]
+	if !ok {
+		return nil, fmt.Errorf("missing field %s", name)
+	}
+	return value, nil
+}
+
+func sequenceField(fields map[string]Value, name string) ([]Value, error) {
+	value, err := field(fields, name)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := sequence(value)
+	if !ok {
+		return nil, fmt.Errorf("field %s must be a list, got %s", name, TypeName(value))
+	}
+	return items, nil
+}
+
+func integerField(fields map[string]Value, name string) (*big.Int, error) {
+	value, err := field(fields, name)
+	if err != nil {
+		return nil, err
+	}
+	i, ok := value.(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("field %s must be an integer, got %s", name, TypeName(value))
+	}
+	return i, nil
+}
+
+func integers(items []Value) ([]*big.Int, error) {
+	ints := make([]*big.Int, len(items))
+	for i, item := range items {
+		n, ok := item.(*big.Int)
+		if !ok {
+			return nil, fmt.Errorf("element %d must be an integer, got %s", i, TypeName(item))
+		}
+		ints[i] = n
+	}
+	return ints, nil
+}
+
+func builtinLen(fields map[string]Value) (Value, error) {
+	value, err := field(fields, "sequence")
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := value.(*Map); ok {
+		return big.NewInt(int64(m.Len())), nil
+	}
+	items, ok := sequence(value)
+	if !ok {
+		return nil, fmt.Errorf("field sequence must be a list, got %s", TypeName(value))
+	}
+	return big.NewInt(int64(len(items))), nil
+}
+
+func builtinContains(fields map[string]Value) (Value, error) {
+	items, err := sequenceField(fields, "sequence")
+	if err != nil {
+		return nil, err
+	}
+	item, err := field(fields, "item")
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range items {
+		if Equal(candidate, item) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func builtinMapContains(fields map[string]Value) (Value, error) {
+	value, err := field(fields, "map")
+	if err != nil {
+		return nil, err
+	}
+	m, ok := value.(*Map)
+	if !ok {
+		return nil, fmt.Errorf("field map must be a map, got %s", TypeName(value))
+	}
+	item, err := field(fields, "item")
+	if err != nil {
+		return nil, err
+	}
+	_, found := m.Get(item)
+	return found, nil
+}
+
+func builtinRange(fields map[string]Value) (Value, error) {
+	start, err := integerField(fields, "start")
+	if err != nil {
+		return nil, err
+	}
+	stop, err := integerField(fields, "stop")
+	if err != nil {
+		return nil, err
+	}
+	step := big.NewInt(1)
+	if _, ok := fields["step"]; ok {
+		if step, err = integerField(fields, "step"); err != nil {
+			return nil, err
+		}
+	}
+	if step.Sign() == 0 {
+		return nil, fmt.Errorf("step must not be zero")
+	}
+
+	list := List{}
+	for i := new(big.Int).Set(start); step.Sign() > 0 && i.Cmp(stop) < 0 || step.Sign() < 0 && i.Cmp(stop) > 0; i.Add(i, step) {
+		list = append(list, new(big.Int).Set(i))
+	}
+	return list, nil
+}
+
+func builtinSum(fields map[string]Value) (Value, error) {
+	items, err := sequenceField(fields, "sequence")
+	if err != nil {
+		return nil, err
+	}
+	ints, err := integers(items)
+	if err != nil {
+		return nil, err
+	}
+	sum := new(big.Int)
+	for _, n := range ints {
+		sum.Add(sum, n)
+	}
+	return sum, nil
+}
+
+func builtinMin(fields map[string]Value) (Value, error) {
+	return extremum(fields, -1)
+}
+
+func builtinMax(fields map[string]Value) (Value, error) {
+	return extremum(fields, 1)
+}
+
+// extremum returns the smallest (sign -1) or largest (sign 1) integer of the sequence field.
+func extremum(fields map[string]Value, sign int) (Value, error) {
+	items, err := sequenceField(fields, "sequence")
+	if err != nil {
+		return nil, err
+	}
+	ints, err := integers(items)
+	if err != nil {
+		return nil, err
+	}
+	if len(ints) == 0 {
+		return nil, fmt.Errorf("sequence is empty")
+	}
+	best := ints[0]
+	for _, n := range ints[1:] {
+		if n.Cmp(best) == sign {
+			best = n
+		}
+	}
+	return new(big.Int).Set(best), nil
+}
+
+func builtinUnique(fields map[string]Value) (Value, error) {
+	items, err := sequenceField(fields, "sequence")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	list := List{}
+	for _, item := range items {
+		key := keyString(item)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+func builtinZip(fields map[string]Value) (Value, error) {
+	first, err := sequenceField(fields, "first")
+	if err != nil {
+		return nil, err
+	}
+	second, err := sequenceField(fields, "second")
+	if err != nil {
+		return nil, err
+	}
+	n := len(first)
+	if len(second) < n {
+		n = len(second)
+	}
+	list := make(List, n)
+	for i := 0; i < n; i++ {
+		list[i] = Tuple{first[i], second[i]}
+	}
+	return list, nil
+}
+
+func builtinKeccak256(fields map[string]Value) (Value, error) {
+	value, err := field(fields, "input")
+	if err != nil {
+		return nil, err
+	}
+	s, ok := hexString(value)
+	if !ok {
+		return nil, fmt.Errorf("field input must be bytes, got %s", TypeName(value))
+	}
+	data, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(data)
+	return Bytes("0x" + hex.EncodeToString(hash.Sum(nil))), nil
+}
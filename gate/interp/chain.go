@@ -0,0 +1,47 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrChainAccess is returned when a source needs on-chain data that the configured Chain cannot provide.
+var ErrChainAccess = errors.New("requires chain access")
+
+// Chain provides the data behind the builtins that read from a node, such as Call, Events or BlockNumber.
+type Chain interface {
+	// Read evaluates the named builtin with its already evaluated struct fields.
+	Read(builtin string, fields map[string]Value) (Value, error)
+}
+
+// chainBuiltins are the builtins that are served by a Chain rather than computed locally.
+var chainBuiltins = map[string]bool{
+	"BlockHash":              true,
+	"BlockNumber":            true,
+	"BlockTimestamp":         true,
+	"Call":                   true,
+	"Calls":                  true,
+	"Events":                 true,
+	"FilterAddressesInTrace": true,
+	"HistoricalCalls":        true,
+	"HistoricalEvents":       true,
+	"StateRoot":              true,
+	"StorageHash":            true,
+}
+
+// IsChainBuiltin reports whether the named builtin reads on-chain data.
+func IsChainBuiltin(name string) bool {
+	return chainBuiltins[name]
+}
+
+// EmptyChain is a Chain that has never seen a transaction. Builtins that list calls, events or traced
+// addresses return empty lists, state reads such as Call fail with ErrChainAccess so they must be mocked.
+type EmptyChain struct{}
+
+func (EmptyChain) Read(builtin string, fields map[string]Value) (Value, error) {
+	switch builtin {
+	case "Calls", "Events", "FilterAddressesInTrace", "HistoricalCalls", "HistoricalEvents":
+		return List{}, nil
+	}
+	return nil, fmt.Errorf("%s %w", builtin, ErrChainAccess)
+}
@@ -0,0 +1,572 @@
+// Package interp evaluates gate monitors locally against mocked sources, mirroring the Hexagate validate endpoint.
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// Env holds the inputs of a single evaluation.
+type Env struct {
+	// Params are the monitor params, keyed by param name.
+	Params map[string]any
+	// Mocks replace the value of the named sources, exactly like the mocks of a validate request.
+	Mocks map[string]any
+	// Chain serves the on-chain builtins of sources that are not mocked. Defaults to EmptyChain.
+	Chain Chain
+}
+
+// Alert is an invariant whose condition evaluated to false.
+type Alert struct {
+	Description string
+}
+
+// Exception is an error raised while evaluating a source or an invariant.
+type Exception struct {
+	Source  string
+	Message string
+}
+
+// Result is the outcome of evaluating every source and invariant of a gate file.
+type Result struct {
+	Failed     []Alert
+	Exceptions []Exception
+	Trace      map[string]Value
+}
+
+// sourceError is an evaluation failure attributed to the source it happened in.
+type sourceError struct {
+	source string
+	err    error
+}
+
+func (e *sourceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.source, e.err)
+}
+
+func (e *sourceError) Unwrap() error {
+	return e.err
+}
+
+type evaluator struct {
+	file    *gate.File
+	env     Env
+	imports map[string]bool
+
+	params  map[string]Value
+	values  map[string]Value
+	errs    map[string]error
+	active  map[string]bool
+	sources map[string]*gate.SourceDecl
+}
+
+// scope binds comprehension variables.
+type scope struct {
+	name   string
+	value  Value
+	parent *scope
+}
+
+func (s *scope) lookup(name string) (Value, bool) {
+	for ; s != nil; s = s.parent {
+		if s.name == name {
+			return s.value, true
+		}
+	}
+	return nil, false
+}
+
+// Evaluate evaluates every source and invariant of file. Sources are evaluated in declaration order, with
+// dependencies resolved on demand. Each failing source is reported once as an exception.
+func Evaluate(file *gate.File, env Env) *Result {
+	if env.Chain == nil {
+		env.Chain = EmptyChain{}
+	}
+
+	e := &evaluator{
+		file:    file,
+		env:     env,
+		imports: make(map[string]bool),
+		params:  make(map[string]Value),
+		values:  make(map[string]Value),
+		errs:    make(map[string]error),
+		active:  make(map[string]bool),
+		sources: make(map[string]*gate.SourceDecl),
+	}
+	result := &Result{Trace: e.values}
+
+	for _, use := range file.Uses() {
+		for _, name := range use.Names {
+			e.imports[name.Name] = true
+		}
+	}
+	for _, source := range file.Sources() {
+		e.sources[source.Name.Name] = source
+	}
+
+	for _, param := range file.Params() {
+		name := param.Name.Name
+		raw, ok := env.Params[name]
+		if !ok {
+			result.Exceptions = append(result.Exceptions, Exception{Source: name, Message: "missing param"})
+			continue
+		}
+		value, err := FromJSON(raw, param.Type)
+		if err != nil {
+			result.Exceptions = append(result.Exceptions, Exception{Source: name, Message: fmt.Sprintf("invalid param: %v", err)})
+			continue
+		}
+		e.params[name] = value
+	}
+
+	reported := make(map[string]bool)
+	report := func(err error) {
+		var serr *sourceError
+		if errors.As(err, &serr) {
+			if reported[serr.source] {
+				return
+			}
+			reported[serr.source] = true
+			result.Exceptions = append(result.Exceptions, Exception{Source: serr.source, Message: serr.err.Error()})
+			return
+		}
+		result.Exceptions = append(result.Exceptions, Exception{Message: err.Error()})
+	}
+
+	for _, source := range file.Sources() {
+		if _, err := e.source(source.Name.Name); err != nil {
+			report(err)
+		}
+	}
+
+	for _, invariant := range file.Invariants() {
+		description := invariant.Description()
+		condition := invariant.Condition()
+		if condition == nil {
+			report(&sourceError{source: description, err: errors.New("invariant has no condition")})
+			continue
+		}
+
+		value, err := e.eval(condition, nil)
+		if err != nil {
+			var serr *sourceError
+			if !errors.As(err, &serr) {
+				err = &sourceError{source: description, err: err}
+			}
+			report(err)
+			continue
+		}
+		holds, ok := value.(bool)
+		if !ok {
+			report(&sourceError{source: description, err: fmt.Errorf("condition is %s, not boolean", TypeName(value))})
+			continue
+		}
+		if !holds {
+			result.Failed = append(result.Failed, Alert{Description: description})
+		}
+	}
+
+	return result
+}
+
+// source returns the memoized value of the named source, evaluating it or its mock on first use.
+func (e *evaluator) source(name string) (Value, error) {
+	if value, ok := e.values[name]; ok {
+		return value, nil
+	}
+	if err, ok := e.errs[name]; ok {
+		return nil, err
+	}
+	if e.active[name] {
+		return nil, &sourceError{source: name, err: errors.New("source depends on itself")}
+	}
+
+	decl := e.sources[name]
+	e.active[name] = true
+	value, err := e.evalSource(decl)
+	delete(e.active, name)
+
+	if err != nil {
+		var serr *sourceError
+		if !errors.As(err, &serr) {
+			err = &sourceError{source: name, err: err}
+		}
+		e.errs[name] = err
+		return nil, err
+	}
+	e.values[name] = value
+	return value, nil
+}
+
+func (e *evaluator) evalSource(decl *gate.SourceDecl) (Value, error) {
+	if mock, ok := e.env.Mocks[decl.Name.Name]; ok {
+		value, err := FromJSON(mock, decl.Type)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mock: %w", err)
+		}
+		return value, nil
+	}
+
+	value, err := e.eval(decl.Value, nil)
+	if err != nil {
+		return nil, err
+	}
+	return conform(value, decl.Type)
+}
+
+func errorAt(node gate.Node, format string, args ...any) error {
+	return fmt.Errorf("%s: %s", node.Pos(), fmt.Sprintf(format, args...))
+}
+
+func (e *evaluator) eval(expr gate.Expr, sc *scope) (Value, error) {
+	switch x := expr.(type) {
+	case *gate.Ident:
+		if value, ok := sc.lookup(x.Name); ok {
+			return value, nil
+		}
+		if _, ok := e.sources[x.Name]; ok {
+			return e.source(x.Name)
+		}
+		if value, ok := e.params[x.Name]; ok {
+			return value, nil
+		}
+		return nil, errorAt(x, "undefined: %s", x.Name)
+
+	case *gate.BasicLit:
+		switch x.Kind {
+		case gate.INT:
+			return parseInteger(x.Value)
+		case gate.HEX:
+			// a 20 byte literal is an address, anything else is raw bytes
+			if len(x.Value) == 42 {
+				return Address(normalizeHex(x.Value)), nil
+			}
+			return Bytes(normalizeHex(x.Value)), nil
+		case gate.STRING:
+			return x.Unquote(), nil
+		}
+		return nil, errorAt(x, "invalid literal %s", x.Value)
+
+	case *gate.BoolLit:
+		return x.Value, nil
+
+	case *gate.ParenExpr:
+		return e.eval(x.X, sc)
+
+	case *gate.UnaryExpr:
+		return e.evalUnary(x, sc)
+
+	case *gate.BinaryExpr:
+		return e.evalBinary(x, sc)
+
+	case *gate.TernaryExpr:
+		cond, err := e.evalBool(x.Cond, sc)
+		if err != nil {
+			return nil, err
+		}
+		if cond {
+			return e.eval(x.Then, sc)
+		}
+		return e.eval(x.Else, sc)
+
+	case *gate.IndexExpr:
+		return e.evalIndex(x, sc)
+
+	case *gate.CallExpr:
+		return e.evalCall(x, sc)
+
+	case *gate.StructCallExpr:
+		return e.evalStructCall(x, sc)
+
+	case *gate.ListLit:
+		list := make(List, len(x.Elems))
+		for i, elem := range x.Elems {
+			value, err := e.eval(elem, sc)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return list, nil
+
+	case *gate.ListComp:
+		list := List{}
+		err := e.comprehend(x.Var, x.Seq, x.Cond, sc, func(inner *scope) error {
+			value, err := e.eval(x.Elem, inner)
+			if err != nil {
+				return err
+			}
+			list = append(list, value)
+			return nil
+		})
+		return list, err
+
+	case *gate.MapComp:
+		m := NewMap()
+		err := e.comprehend(x.Var, x.Seq, x.Cond, sc, func(inner *scope) error {
+			key, err := e.eval(x.Key, inner)
+			if err != nil {
+				return err
+			}
+			value, err := e.eval(x.Value, inner)
+			if err != nil {
+				return err
+			}
+			m.Set(key, value)
+			return nil
+		})
+		return m, err
+	}
+	return nil, errorAt(expr, "unsupported expression %T", expr)
+}
+
+func (e *evaluator) evalBool(expr gate.Expr, sc *scope) (bool, error) {
+	value, err := e.eval(expr, sc)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, errorAt(expr, "expected boolean, got %s", TypeName(value))
+	}
+	return b, nil
+}
+
+func (e *evaluator) evalInt(expr gate.Expr, sc *scope) (*big.Int, error) {
+	value, err := e.eval(expr, sc)
+	if err != nil {
+		return nil, err
+	}
+	i, ok := value.(*big.Int)
+	if !ok {
+		return nil, errorAt(expr, "expected integer, got %s", TypeName(value))
+	}
+	return i, nil
+}
+
+func (e *evaluator) comprehend(variable *gate.Ident, seqExpr, cond gate.Expr, sc *scope, yield func(*scope) error) error {
+	seq, err := e.eval(seqExpr, sc)
+	if err != nil {
+		return err
+	}
+	items, ok := sequence(seq)
+	if !ok {
+		return errorAt(seqExpr, "cannot iterate over %s", TypeName(seq))
+	}
+
+	for _, item := range items {
+		inner := &scope{name: variable.Name, value: item, parent: sc}
+		if cond != nil {
+			keep, err := e.evalBool(cond, inner)
+			if err != nil {
+				return err
+			}
+			if !keep {
+				continue
+			}
+		}
+		if err := yield(inner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *evaluator) evalUnary(x *gate.UnaryExpr, sc *scope) (Value, error) {
+	switch x.Op {
+	case gate.NOT:
+		b, err := e.evalBool(x.X, sc)
+		if err != nil {
+			return nil, err
+		}
+		return !b, nil
+	case gate.SUB:
+		i, err := e.evalInt(x.X, sc)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).Neg(i), nil
+	}
+	return nil, errorAt(x, "unsupported unary operator %s", x.Op)
+}
+
+func (e *evaluator) evalBinary(x *gate.BinaryExpr, sc *scope) (Value, error) {
+	// boolean operators short-circuit
+	switch x.Op {
+	case gate.AND, gate.OR:
+		left, err := e.evalBool(x.X, sc)
+		if err != nil {
+			return nil, err
+		}
+		if x.Op == gate.AND && !left || x.Op == gate.OR && left {
+			return left, nil
+		}
+		return e.evalBool(x.Y, sc)
+	}
+
+	left, err := e.eval(x.X, sc)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.eval(x.Y, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x.Op {
+	case gate.EQL:
+		return Equal(left, right), nil
+	case gate.NEQ:
+		return !Equal(left, right), nil
+	case gate.ADD:
+		// + also concatenates bytes and strings
+		if lb, ok := left.(Bytes); ok {
+			rb, ok := hexString(right)
+			if !ok {
+				return nil, errorAt(x, "cannot add %s to bytes", TypeName(right))
+			}
+			return lb + Bytes(rb[2:]), nil
+		}
+		if ls, ok := left.(string); ok {
+			rs, ok := right.(string)
+			if !ok {
+				return nil, errorAt(x, "cannot add %s to string", TypeName(right))
+			}
+			return ls + rs, nil
+		}
+	}
+
+	l, lok := left.(*big.Int)
+	r, rok := right.(*big.Int)
+	if !lok || !rok {
+		return nil, errorAt(x, "invalid operation: %s %s %s", TypeName(left), x.Op, TypeName(right))
+	}
+
+	switch x.Op {
+	case gate.LSS:
+		return l.Cmp(r) < 0, nil
+	case gate.LEQ:
+		return l.Cmp(r) <= 0, nil
+	case gate.GTR:
+		return l.Cmp(r) > 0, nil
+	case gate.GEQ:
+		return l.Cmp(r) >= 0, nil
+	case gate.ADD:
+		return new(big.Int).Add(l, r), nil
+	case gate.SUB:
+		return new(big.Int).Sub(l, r), nil
+	case gate.MUL:
+		return new(big.Int).Mul(l, r), nil
+	case gate.QUO, gate.REM:
+		if r.Sign() == 0 {
+			return nil, errorAt(x, "division by zero")
+		}
+		if x.Op == gate.QUO {
+			return new(big.Int).Quo(l, r), nil
+		}
+		return new(big.Int).Rem(l, r), nil
+	case gate.POW:
+		if r.Sign() < 0 {
+			return nil, errorAt(x, "negative exponent %s", r)
+		}
+		return new(big.Int).Exp(l, r, nil), nil
+	}
+	return nil, errorAt(x, "unsupported binary operator %s", x.Op)
+}
+
+func (e *evaluator) evalIndex(x *gate.IndexExpr, sc *scope) (Value, error) {
+	container, err := e.eval(x.X, sc)
+	if err != nil {
+		return nil, err
+	}
+	index, err := e.eval(x.Index, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	if m, ok := container.(*Map); ok {
+		value, ok := m.Get(index)
+		if !ok {
+			return nil, errorAt(x, "key %v not found in map", ToJSON(index))
+		}
+		return value, nil
+	}
+
+	items, ok := sequence(container)
+	if !ok {
+		return nil, errorAt(x, "cannot index %s", TypeName(container))
+	}
+	i, ok := index.(*big.Int)
+	if !ok {
+		return nil, errorAt(x.Index, "index must be integer, got %s", TypeName(index))
+	}
+	if i.Sign() < 0 || !i.IsInt64() || i.Int64() >= int64(len(items)) {
+		return nil, errorAt(x, "index %s out of range [0:%d]", i, len(items))
+	}
+	return items[i.Int64()], nil
+}
+
+func (e *evaluator) evalCall(x *gate.CallExpr, sc *scope) (Value, error) {
+	args := make([]Value, len(x.Args))
+	for i, arg := range x.Args {
+		value, err := e.eval(arg, sc)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	switch x.Fun.Name {
+	case "list":
+		return List(args), nil
+	case "tuple":
+		return Tuple(args), nil
+	case "bytes":
+		if len(args) != 1 {
+			return nil, errorAt(x, "bytes expects 1 argument, got %d", len(args))
+		}
+		s, ok := hexString(args[0])
+		if !ok {
+			return nil, errorAt(x, "cannot convert %s to bytes", TypeName(args[0]))
+		}
+		return Bytes(s), nil
+	}
+	return nil, errorAt(x, "unknown function %s", x.Fun.Name)
+}
+
+func (e *evaluator) evalStructCall(x *gate.StructCallExpr, sc *scope) (Value, error) {
+	name := x.Fun.Name
+	if !e.imports[name] {
+		return nil, errorAt(x, "%s is not imported from hexagate", name)
+	}
+
+	fields := make(map[string]Value, len(x.Fields))
+	for _, field := range x.Fields {
+		value, err := e.eval(field.Value, sc)
+		if err != nil {
+			return nil, err
+		}
+		fields[field.Name.Name] = value
+	}
+
+	if IsChainBuiltin(name) {
+		value, err := e.env.Chain.Read(name, fields)
+		if err != nil {
+			return nil, errorAt(x, "%v", err)
+		}
+		return value, nil
+	}
+
+	builtin, ok := builtins[name]
+	if !ok {
+		return nil, errorAt(x, "unknown builtin %s", name)
+	}
+	value, err := builtin(fields)
+	if err != nil {
+		return nil, errorAt(x, "%s: %v", name, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,167 @@
+package interp
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/monitors"
+)
+
+const builtinsHeader = "use Contains, Len, MapContains, Max, Min, Range, Sum, Unique, Zip, Keccak256, Call from hexagate;\n"
+
+func evaluate(t *testing.T, src string, params, mocks map[string]any) *Result {
+	t.Helper()
+
+	file, err := gate.Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Error parsing gate source: %v", err)
+	}
+	return Evaluate(file, Env{Params: params, Mocks: mocks})
+}
+
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		name   string
+		typ    string
+		expr   string
+		expect any
+	}{
+		{"len", "integer", "Len { sequence: [1, 2, 3] }", 3},
+		{"contains tuple", "boolean", "Contains { sequence: [tuple(0x00000000000000000000000000000000000000AA, 1)], item: tuple(0x00000000000000000000000000000000000000aa, 1) }", true},
+		{"contains missing", "boolean", "Contains { sequence: [1, 2], item: 3 }", false},
+		{"range", "list<integer>", "Range { start: 0, stop: 5, step: 2 }", []any{0, 2, 4}},
+		{"range default step", "list<integer>", "Range { start: 1, stop: 3 }", []any{1, 2}},
+		{"sum", "integer", "Sum { sequence: [1, 2, 3] }", 6},
+		{"sum empty", "integer", "Sum { sequence: [] }", 0},
+		{"min", "integer", "Min { sequence: [3, 1, 2] }", 1},
+		{"max", "integer", "Max { sequence: [3, 1, 2] }", 3},
+		{"unique", "list<integer>", "Unique { sequence: [1, 2, 1, 3, 2] }", []any{1, 2, 3}},
+		{"zip", "list<tuple<integer, boolean>>", "Zip { first: [1, 2], second: [true, false] }", []any{[]any{1, true}, []any{2, false}}},
+		{"map contains", "boolean", "MapContains { map: { x: true for x in [1, 2] }, item: 2 }", true},
+		{"comprehension filter", "list<integer>", "[x * 2 for x in Range { start: 0, stop: 5 } if x % 2 == 0]", []any{0, 4, 8}},
+		{"ternary", "integer", "Len { sequence: [] } > 0 ? 1 : 2", 2},
+		{"power", "integer", "2 ** 3 ** 2", 512},
+		{"bytes concat", "bytes", "bytes(0x01) + 0x02", "0x0102"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			src := builtinsHeader + "source value: " + tc.typ + " = " + tc.expr + ";\n"
+			file, err := gate.Parse([]byte(src))
+			if err != nil {
+				t.Fatalf("Error parsing gate source: %v", err)
+			}
+			result := Evaluate(file, Env{})
+			if len(result.Exceptions) > 0 {
+				t.Fatalf("Unexpected exceptions: %v", result.Exceptions)
+			}
+
+			expected, err := FromJSON(tc.expect, file.Source("value").Type)
+			if err != nil {
+				t.Fatalf("Error converting expected value: %v", err)
+			}
+			if !Equal(result.Trace["value"], expected) {
+				t.Errorf("Expected %v, got %v", ToJSON(expected), ToJSON(result.Trace["value"]))
+			}
+		})
+	}
+}
+
+func TestKeccak256(t *testing.T) {
+	// keccak256 of a single zero byte
+	result := evaluate(t, builtinsHeader+"source hash: bytes = Keccak256 { input: bytes(0x00) };\n", nil, nil)
+	if len(result.Exceptions) > 0 {
+		t.Fatalf("Unexpected exceptions: %v", result.Exceptions)
+	}
+
+	expected := Bytes("0xbc36789e7a1e281436464229828f817d6612f7b477d66591ff96a9e064bcc98a")
+	if result.Trace["hash"] != expected {
+		t.Errorf("Expected %s, got %v", expected, result.Trace["hash"])
+	}
+}
+
+func TestMocksReplaceSources(t *testing.T) {
+	// We expect mocked sources to never be evaluated and their dependents to use the mocked value
+	src := builtinsHeader + `
+param disputeGame: address;
+source claimCount: integer = Call { contract: disputeGame, signature: "function claimDataLen() view returns (uint256)" };
+source indices: list<integer> = Range { start: 0, stop: claimCount };
+invariant { description: "too many claims", condition: Len { sequence: indices } < 3 };
+`
+	params := map[string]any{"disputeGame": "0x0000000000000000000000000000000000000001"}
+
+	result := evaluate(t, src, params, map[string]any{"claimCount": 5})
+	if len(result.Exceptions) > 0 {
+		t.Fatalf("Unexpected exceptions: %v", result.Exceptions)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Description != "too many claims" {
+		t.Errorf("Expected the invariant to fail, got %v", result.Failed)
+	}
+	if result.Trace["claimCount"].(*big.Int).Int64() != 5 {
+		t.Errorf("Expected the mocked claimCount in the trace, got %v", result.Trace["claimCount"])
+	}
+}
+
+func TestUnmockedChainSourceRaisesException(t *testing.T) {
+	// We expect a state read without a mock to raise a single exception and no alert
+	src := builtinsHeader + `
+param disputeGame: address;
+source claimCount: integer = Call { contract: disputeGame, signature: "function claimDataLen() view returns (uint256)" };
+source doubled: integer = claimCount * 2;
+invariant { description: "no claims", condition: doubled > 0 };
+`
+	result := evaluate(t, src, map[string]any{"disputeGame": "0x0000000000000000000000000000000000000001"}, nil)
+	if len(result.Failed) != 0 {
+		t.Errorf("Expected no alerts, got %v", result.Failed)
+	}
+	if len(result.Exceptions) != 1 || result.Exceptions[0].Source != "claimCount" {
+		t.Fatalf("Expected a single exception for claimCount, got %v", result.Exceptions)
+	}
+	if !strings.Contains(result.Exceptions[0].Message, ErrChainAccess.Error()) {
+		t.Errorf("Expected a chain access exception, got %q", result.Exceptions[0].Message)
+	}
+}
+
+func TestInvalidMockRaisesException(t *testing.T) {
+	// We expect a mock that does not match the declared source type to be reported
+	src := "source pair: tuple<integer, address> = tuple(1, 0x0000000000000000000000000000000000000001);\n"
+	result := evaluate(t, src, nil, map[string]any{"pair": []any{1}})
+	if len(result.Exceptions) != 1 || !strings.Contains(result.Exceptions[0].Message, "invalid mock") {
+		t.Errorf("Expected an invalid mock exception, got %v", result.Exceptions)
+	}
+}
+
+func TestChallengerLosesMonitor(t *testing.T) {
+	// We expect the honest challenger losing a defended subgame to fire only the subgame invariant
+	monitor, err := monitors.Get("challenger_loses")
+	if err != nil {
+		t.Fatalf("Error reading monitor: %v", err)
+	}
+
+	challenger := "0x49277EE36A024120Ee218127354c4a3591dc90A9"
+	params := map[string]any{
+		"disputeGame":      "0x0000000000000000000000000000000000000000",
+		"honestChallenger": challenger,
+	}
+	mocks := map[string]any{
+		"addressesInTrace":     []any{"0x0000000000000000000000000000000000000000"},
+		"resolveEvents":        [][]any{{2}},
+		"historicalMoveEvents": [][]any{{0, "0x00", "0x00000000000000000000000000000000000000AA"}, {1, "0x1a", challenger}},
+		"claimCount":           3,
+		"claimResults": [][]any{
+			{11111111, "0x0000000000000000000000000000000000000000", "0x00000000000000000000000000000000000000BB", 0, "0x00", 0, 123455},
+			{0, challenger, "0x00000000000000000000000000000000000000AA", 1, "0x33", 1, 123456},
+			{1, "0x00000000000000000000000000000000000000AA", challenger, 2, "0x22", 2, 123457},
+		},
+	}
+
+	result := evaluate(t, monitor.Source, params, mocks)
+	if len(result.Exceptions) > 0 {
+		t.Fatalf("Unexpected exceptions: %v", result.Exceptions)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Description != "Challenger lost one or more subgames" {
+		t.Errorf("Expected only the subgame invariant to fail, got %v", result.Failed)
+	}
+}
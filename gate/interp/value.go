@@ -0,0 +1,443 @@
+package interp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// Value is a runtime gate value. It is one of *big.Int, bool, string, Address, Bytes, List, Tuple or *Map.
+type Value interface{}
+
+// Address is a lower-cased, 0x prefixed account address.
+type Address string
+
+// Bytes is a lower-cased, 0x prefixed hex string.
+type Bytes string
+
+// List is a gate list<T>.
+type List []Value
+
+// Tuple is a gate tuple<T...>.
+type Tuple []Value
+
+// Map is a gate map<K, V> that remembers key insertion order.
+type Map struct {
+	keys    []Value
+	entries map[string]Value
+}
+
+// NewMap returns an empty map.
+func NewMap() *Map {
+	return &Map{entries: make(map[string]Value)}
+}
+
+// Set stores value under key, keeping the original insertion position of an existing key.
+func (m *Map) Set(key, value Value) {
+	k := keyString(key)
+	if _, ok := m.entries[k]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.entries[k] = value
+}
+
+// Get returns the value stored under key.
+func (m *Map) Get(key Value) (Value, bool) {
+	value, ok := m.entries[keyString(key)]
+	return value, ok
+}
+
+// Keys returns the map keys in insertion order.
+func (m *Map) Keys() []Value {
+	return m.keys
+}
+
+// Len returns the number of entries in the map.
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+func keyString(v Value) string {
+	switch v := v.(type) {
+	case *big.Int:
+		return "i:" + v.String()
+	case Address:
+		return "h:" + string(v)
+	case Bytes:
+		return "h:" + string(v)
+	case string:
+		return "s:" + v
+	case bool:
+		return fmt.Sprintf("b:%t", v)
+	case List:
+		return "l:" + sequenceKey(v)
+	case Tuple:
+		return "l:" + sequenceKey(v)
+	}
+	return fmt.Sprintf("?:%v", v)
+}
+
+func sequenceKey(values []Value) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = keyString(v)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// TypeName returns the gate name of the value's kind, used in error messages.
+func TypeName(v Value) string {
+	switch v.(type) {
+	case *big.Int:
+		return "integer"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case Address:
+		return "address"
+	case Bytes:
+		return "bytes"
+	case List:
+		return "list"
+	case Tuple:
+		return "tuple"
+	case *Map:
+		return "map"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// Equal reports whether two values are deeply equal. Addresses and bytes compare by their hex value.
+func Equal(a, b Value) bool {
+	switch a := a.(type) {
+	case *big.Int:
+		b, ok := b.(*big.Int)
+		return ok && a.Cmp(b) == 0
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case Address, Bytes:
+		ha, _ := hexString(a)
+		hb, ok := hexString(b)
+		return ok && ha == hb
+	case List:
+		bs, ok := sequence(b)
+		return ok && equalSequence(a, bs)
+	case Tuple:
+		bs, ok := sequence(b)
+		return ok && equalSequence(a, bs)
+	case *Map:
+		b, ok := b.(*Map)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		for _, key := range a.keys {
+			av, _ := a.Get(key)
+			bv, ok := b.Get(key)
+			if !ok || !Equal(av, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func hexString(v Value) (string, bool) {
+	switch v := v.(type) {
+	case Address:
+		return string(v), true
+	case Bytes:
+		return string(v), true
+	}
+	return "", false
+}
+
+func sequence(v Value) ([]Value, bool) {
+	switch v := v.(type) {
+	case List:
+		return v, true
+	case Tuple:
+		return v, true
+	}
+	return nil, false
+}
+
+func equalSequence(a, b []Value) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeHex lower-cases a hex string and makes sure it carries a 0x prefix.
+func normalizeHex(s string) string {
+	s = strings.ToLower(s)
+	if !strings.HasPrefix(s, "0x") {
+		s = "0x" + s
+	}
+	return s
+}
+
+// FromJSON converts a JSON-like Go value, such as a mock or param, into a Value of the declared gate type.
+// Slices, arrays and maps of any element type are accepted, so mocks built in Go can be passed directly.
+func FromJSON(v any, typ gate.TypeExpr) (Value, error) {
+	switch typ := typ.(type) {
+	case *gate.NamedType:
+		return primitiveFromJSON(v, typ.Name)
+	case *gate.ListType:
+		items, err := jsonSequence(v)
+		if err != nil {
+			return nil, err
+		}
+		list := make(List, len(items))
+		for i, item := range items {
+			if list[i], err = FromJSON(item, typ.Elem); err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		return list, nil
+	case *gate.TupleType:
+		items, err := jsonSequence(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) != len(typ.Elems) {
+			return nil, fmt.Errorf("expected tuple of %d elements, got %d", len(typ.Elems), len(items))
+		}
+		tuple := make(Tuple, len(items))
+		for i, item := range items {
+			if tuple[i], err = FromJSON(item, typ.Elems[i]); err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		return tuple, nil
+	case *gate.MapType:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Map {
+			return nil, fmt.Errorf("expected map, got %T", v)
+		}
+		m := NewMap()
+		for _, key := range sortedKeys(rv) {
+			k, err := FromJSON(fmt.Sprint(key.Interface()), typ.Key)
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %w", key.Interface(), err)
+			}
+			value, err := FromJSON(rv.MapIndex(key).Interface(), typ.Value)
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %w", key.Interface(), err)
+			}
+			m.Set(k, value)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", typ)
+}
+
+func sortedKeys(rv reflect.Value) []reflect.Value {
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+func jsonSequence(v any) ([]any, error) {
+	if items, ok := v.([]any); ok {
+		return items, nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected list, got %T", v)
+	}
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, nil
+}
+
+func primitiveFromJSON(v any, name string) (Value, error) {
+	switch name {
+	case "integer":
+		return integerFromJSON(v)
+	case "address", "bytes":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected %s hex string, got %T", name, v)
+		}
+		if name == "address" {
+			return Address(normalizeHex(s)), nil
+		}
+		return Bytes(normalizeHex(s)), nil
+	case "boolean":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected boolean, got %T", v)
+		}
+		return b, nil
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", v)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown type %s", name)
+}
+
+func integerFromJSON(v any) (*big.Int, error) {
+	switch v := v.(type) {
+	case *big.Int:
+		return new(big.Int).Set(v), nil
+	case json.Number:
+		return parseInteger(string(v))
+	case string:
+		return parseInteger(v)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f != math.Trunc(f) {
+			return nil, fmt.Errorf("expected integer, got %v", f)
+		}
+		i, _ := big.NewFloat(f).Int(nil)
+		return i, nil
+	}
+	return nil, fmt.Errorf("expected integer, got %T", v)
+}
+
+func parseInteger(s string) (*big.Int, error) {
+	i, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return i, nil
+}
+
+// ToJSON converts a Value into plain Go values that encoding/json renders the same way Hexagate traces do.
+func ToJSON(v Value) any {
+	switch v := v.(type) {
+	case *big.Int:
+		return json.Number(v.String())
+	case Address:
+		return string(v)
+	case Bytes:
+		return string(v)
+	case List:
+		return sequenceToJSON(v)
+	case Tuple:
+		return sequenceToJSON(v)
+	case *Map:
+		m := make(map[string]any, v.Len())
+		for _, key := range v.keys {
+			value, _ := v.Get(key)
+			m[fmt.Sprint(ToJSON(key))] = ToJSON(value)
+		}
+		return m
+	}
+	return v
+}
+
+func sequenceToJSON(values []Value) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = ToJSON(v)
+	}
+	return out
+}
+
+// conform checks that a computed value matches the declared type of its source. Hex values are converted
+// between address and bytes to follow the declaration, since a literal alone does not say which one it is.
+func conform(v Value, typ gate.TypeExpr) (Value, error) {
+	switch typ := typ.(type) {
+	case *gate.NamedType:
+		switch typ.Name {
+		case "address":
+			if s, ok := hexString(v); ok {
+				return Address(s), nil
+			}
+		case "bytes":
+			if s, ok := hexString(v); ok {
+				return Bytes(s), nil
+			}
+		default:
+			if TypeName(v) == typ.Name {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("expected %s, got %s", typ.Name, TypeName(v))
+	case *gate.ListType:
+		items, ok := sequence(v)
+		if !ok {
+			return nil, fmt.Errorf("expected list, got %s", TypeName(v))
+		}
+		list := make(List, len(items))
+		for i, item := range items {
+			var err error
+			if list[i], err = conform(item, typ.Elem); err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		return list, nil
+	case *gate.TupleType:
+		items, ok := sequence(v)
+		if !ok {
+			return nil, fmt.Errorf("expected tuple, got %s", TypeName(v))
+		}
+		if len(items) != len(typ.Elems) {
+			return nil, fmt.Errorf("expected tuple of %d elements, got %d", len(typ.Elems), len(items))
+		}
+		tuple := make(Tuple, len(items))
+		for i, item := range items {
+			var err error
+			if tuple[i], err = conform(item, typ.Elems[i]); err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		return tuple, nil
+	case *gate.MapType:
+		m, ok := v.(*Map)
+		if !ok {
+			return nil, fmt.Errorf("expected map, got %s", TypeName(v))
+		}
+		out := NewMap()
+		for _, key := range m.keys {
+			value, _ := m.Get(key)
+			k, err := conform(key, typ.Key)
+			if err != nil {
+				return nil, fmt.Errorf("key: %w", err)
+			}
+			value, err = conform(value, typ.Value)
+			if err != nil {
+				return nil, fmt.Errorf("value: %w", err)
+			}
+			out.Set(k, value)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", typ)
+}
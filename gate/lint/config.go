@@ -0,0 +1,81 @@
+package lint
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config turns rules off for whole monitors, for findings that are expected and whose fix would change the
+// source of a deployed monitor, e.g.
+//
+//	disable:
+//	  - monitor: eth_deficit
+//	    rules: [trace-filter]
+//	    reason: a deficit can be caused by a DelayedWETH transaction that never calls the game
+type Config struct {
+	Disable []Exemption `yaml:"disable"`
+}
+
+// Exemption disables rules for a single monitor, named by its file name without extension.
+type Exemption struct {
+	Monitor string   `yaml:"monitor"`
+	Rules   []string `yaml:"rules"`
+	// Reason explains why the findings are expected, and is required.
+	Reason string `yaml:"reason"`
+}
+
+// ParseConfig decodes and validates a lint config. Unknown fields and rule names are rejected, so that a
+// misspelled exemption does not silently stop applying.
+func ParseConfig(data []byte) (*Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid lint config: %w", err)
+	}
+
+	known := make(map[string]bool)
+	for _, rule := range Rules {
+		known[rule.Name] = true
+	}
+	for i, e := range c.Disable {
+		if e.Monitor == "" {
+			return nil, fmt.Errorf("exemption %d: monitor must be set", i+1)
+		}
+		if len(e.Rules) == 0 {
+			return nil, fmt.Errorf("exemption for %s: rules must be set", e.Monitor)
+		}
+		for _, name := range e.Rules {
+			if !known[name] {
+				return nil, fmt.Errorf("exemption for %s: unknown rule %q", e.Monitor, name)
+			}
+		}
+		if strings.TrimSpace(e.Reason) == "" {
+			return nil, fmt.Errorf("exemption for %s: reason must be set", e.Monitor)
+		}
+	}
+	return &c, nil
+}
+
+// Filter returns the findings that are not disabled for the monitor they were reported in.
+func (c *Config) Filter(findings []Finding) []Finding {
+	disabled := make(map[string]bool)
+	for _, e := range c.Disable {
+		for _, name := range e.Rules {
+			disabled[e.Monitor+" "+name] = true
+		}
+	}
+
+	var kept []Finding
+	for _, f := range findings {
+		monitor := strings.TrimSuffix(path.Base(strings.ReplaceAll(f.Pos.Filename, "\\", "/")), ".gate")
+		if disabled[monitor+" "+f.Rule] {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
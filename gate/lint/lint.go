@@ -0,0 +1,131 @@
+// Package lint reports suspicious constructs in gate monitors, such as imported builtins that are never
+// called or sources whose value never reaches an invariant.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// Severity is how serious a finding is.
+type Severity string
+
+const (
+	Warning Severity = "warning"
+	Error   Severity = "error"
+)
+
+// Rule is a single lint check.
+type Rule struct {
+	// Name identifies the rule in findings, e.g. unused-import.
+	Name string
+	// Doc is a one sentence description of what the rule reports.
+	Doc      string
+	Severity Severity
+	Run      func(pass *Pass)
+}
+
+// Finding is a problem reported by a rule.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Pos      gate.Pos
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Pos, f.Message, f.Rule)
+}
+
+// Pass is the state passed to a rule while it checks a file.
+type Pass struct {
+	File *gate.File
+
+	rule     *Rule
+	findings []Finding
+}
+
+// Reportf records a finding of the running rule at node.
+func (p *Pass) Reportf(node gate.Node, format string, args ...any) {
+	p.findings = append(p.findings, Finding{
+		Rule:     p.rule.Name,
+		Severity: p.rule.Severity,
+		Pos:      node.Pos(),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Rules are the rules run by default.
+var Rules = []*Rule{
+	UnusedImport,
+	UnusedParam,
+	UnreachableSource,
+	ShadowedName,
+	TraceGuard,
+	TraceFilter,
+}
+
+// DISABLE_DIRECTIVE is the annotation comment that turns rules off for a single monitor, followed by a
+// comma separated list of rule names and optionally the reason, e.g.
+//
+//	// gatelint:disable trace-filter evaluated on every block to catch deficits without a transaction
+const DISABLE_DIRECTIVE = "gatelint:disable"
+
+// Lint runs rules over file, or all default Rules if none are given, and returns the findings sorted by position.
+func Lint(file *gate.File, rules ...*Rule) []Finding {
+	if len(rules) == 0 {
+		rules = Rules
+	}
+
+	pass := &Pass{File: file}
+	disabled := directives(pass, rules)
+	for _, rule := range rules {
+		if disabled[rule.Name] {
+			continue
+		}
+		pass.rule = rule
+		rule.Run(pass)
+	}
+
+	sort.SliceStable(pass.findings, func(i, j int) bool {
+		return pass.findings[i].Pos.Offset < pass.findings[j].Pos.Offset
+	})
+	return pass.findings
+}
+
+// annotation is the pseudo rule that reports malformed annotation comments.
+var annotation = &Rule{Name: "annotation", Severity: Error}
+
+// directives returns the rules disabled by annotation comments in the file, reporting unknown rule names.
+func directives(pass *Pass, rules []*Rule) map[string]bool {
+	known := make(map[string]bool)
+	for _, rule := range append(Rules, rules...) {
+		known[rule.Name] = true
+	}
+
+	disabled := make(map[string]bool)
+	for _, comment := range pass.File.Comments {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if !strings.HasPrefix(text, DISABLE_DIRECTIVE) {
+			continue
+		}
+
+		pass.rule = annotation
+		fields := strings.Fields(strings.TrimPrefix(text, DISABLE_DIRECTIVE))
+		if len(fields) == 0 {
+			pass.Reportf(comment, "%s requires a rule name", DISABLE_DIRECTIVE)
+			continue
+		}
+		for _, name := range strings.Split(fields[0], ",") {
+			if !known[name] {
+				pass.Reportf(comment, "%s names unknown rule %q", DISABLE_DIRECTIVE, name)
+				continue
+			}
+			disabled[name] = true
+		}
+	}
+	return disabled
+}
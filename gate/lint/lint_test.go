@@ -0,0 +1,217 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+func lintSource(t *testing.T, src string, rules ...*Rule) []string {
+	file, err := gate.ParseFile("test.gate", []byte(src))
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	var findings []string
+	for _, f := range Lint(file, rules...) {
+		findings = append(findings, f.String())
+	}
+	return findings
+}
+
+func TestLintMonitors(t *testing.T) {
+	// We expect the monitors to lint clean, with every expected finding disabled in the lint config
+	var expected []string
+
+	data, err := os.ReadFile("../../monitors/gatelint.yaml")
+	if err != nil {
+		t.Fatalf("Error reading file %s: %v", "gatelint.yaml", err)
+	}
+	config, err := ParseConfig(data)
+	if err != nil {
+		t.Fatalf("Error parsing lint config: %v", err)
+	}
+
+	paths, err := filepath.Glob("../../monitors/*.gate")
+	if err != nil {
+		t.Fatalf("Error listing monitors: %v", err)
+	}
+
+	var got []string
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", path, err)
+		}
+		file, err := gate.ParseFile(path, src)
+		if err != nil {
+			t.Fatalf("Error parsing %s: %v", path, err)
+		}
+		for _, f := range config.Filter(Lint(file)) {
+			got = append(got, f.String())
+		}
+	}
+
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	src := `use Len, Range, Sum from hexagate;
+
+param disputeGame: address;
+param unused: address;
+
+source claims: list<integer> = Range { start: 0, stop: 3 };
+source total: integer = Sum { sequence: claims };
+source orphan: integer = Len { sequence: [disputeGame for claims in claims] };
+source nested: list<list<integer>> = [[x for x in claims] for x in claims];
+
+invariant {
+    description: "total",
+    condition: total > Len { sequence: nested }
+};
+`
+	expected := []string{
+		"test.gate:4:7: param unused is never used (unused-param)",
+		"test.gate:8:8: source orphan does not reach any invariant (unreachable-source)",
+		"test.gate:8:59: claims shadows source claims (shadowed-name)",
+		"test.gate:9:46: x shadows an enclosing comprehension variable (shadowed-name)",
+	}
+
+	got := lintSource(t, src, UnusedImport, UnusedParam, UnreachableSource, ShadowedName)
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestLintTraceGuard(t *testing.T) {
+	src := `use FilterAddressesInTrace, Len from hexagate;
+
+param disputeGame: address;
+
+source addressesInTrace: list<address> = FilterAddressesInTrace {
+    addresses: list(disputeGame)
+};
+
+invariant {
+    description: "guarded",
+    condition: (Len { sequence: addressesInTrace } > 0) ? (1 == 1) : true
+};
+
+invariant {
+    description: "guarded without parens",
+    condition: Len { sequence: addressesInTrace } > 0 ? 1 == 1 : true
+};
+
+invariant {
+    description: "unguarded",
+    condition: 1 == 1
+};
+
+invariant {
+    description: "alerts outside the trace",
+    condition: (Len { sequence: addressesInTrace } > 0) ? (1 == 1) : false
+};
+`
+	expected := []string{
+		"test.gate:21:16: invariant \"unguarded\" is not guarded by (Len { sequence: addressesInTrace } > 0) ? ... : true (trace-guard)",
+		"test.gate:26:16: invariant \"alerts outside the trace\" is not guarded by (Len { sequence: addressesInTrace } > 0) ? ... : true (trace-guard)",
+	}
+	got := lintSource(t, src)
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	// the annotation disables the rule for this monitor only
+	got = lintSource(t, "// gatelint:disable trace-guard,unused-param known to be unguarded\n"+src)
+	if len(got) != 0 {
+		t.Errorf("Expected no findings with the rule disabled, got %q", got)
+	}
+}
+
+func TestLintAnnotations(t *testing.T) {
+	src := `// gatelint:disable trace-filter
+// gatelint:disable trace-gaurd
+param disputeGame: address;
+source x: address = disputeGame;
+`
+	expected := []string{
+		"test.gate:2:1: gatelint:disable names unknown rule \"trace-gaurd\" (annotation)",
+		"test.gate:4:8: source x does not reach any invariant (unreachable-source)",
+	}
+	got := lintSource(t, src)
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`disable:
+  - monitor: test
+    rules: [unused-param]
+    reason: kept for the deployed hash
+`))
+	if err != nil {
+		t.Fatalf("Error parsing lint config: %v", err)
+	}
+
+	// We expect only the disabled rule to be filtered, and only for the monitor it is disabled for
+	file, err := gate.ParseFile("monitors/test.gate", []byte("use Len from hexagate;\nparam unused: address;\n"))
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	var got []string
+	for _, f := range config.Filter(Lint(file, UnusedImport, UnusedParam)) {
+		got = append(got, f.String())
+	}
+	expected := []string{"monitors/test.gate:1:5: Len is imported but never used (unused-import)"}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	// We expect exemptions without a known rule or a reason to be rejected
+	for _, data := range []string{
+		"disable:\n  - monitor: test\n    rules: [unused-parm]\n    reason: typo\n",
+		"disable:\n  - monitor: test\n    rules: [unused-param]\n",
+		"disable:\n  - monitor: test\n    rule: unused-param\n    reason: unknown field\n",
+	} {
+		if _, err := ParseConfig([]byte(data)); err == nil {
+			t.Errorf("Expected an error parsing %q", data)
+		}
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	file, err := gate.ParseFile("monitors/test.gate", []byte("use Len from hexagate;\nparam unused: address;\n"))
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	findings := Lint(file, UnusedImport, UnusedParam)
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, "gatelint", []*Rule{UnusedImport, UnusedParam}, findings); err != nil {
+		t.Fatalf("Error writing SARIF: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Error decoding SARIF: %v", err)
+	}
+	if log.Version != SARIF_VERSION || len(log.Runs) != 1 || len(log.Runs[0].Tool.Driver.Rules) != 2 {
+		t.Fatalf("Unexpected SARIF log %s", buf.String())
+	}
+	results := log.Runs[0].Results
+	if len(results) != 2 || results[1].RuleID != "unused-param" || results[1].Level != Warning {
+		t.Fatalf("Unexpected SARIF results %s", buf.String())
+	}
+	location := results[1].Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "monitors/test.gate" || location.Region.StartLine != 2 || location.Region.StartColumn != 7 {
+		t.Errorf("Unexpected SARIF location %+v", location)
+	}
+}
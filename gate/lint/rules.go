@@ -0,0 +1,108 @@
+package lint
+
+import (
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// UnusedImport reports builtins imported with use that are never called.
+var UnusedImport = &Rule{
+	Name:     "unused-import",
+	Doc:      "Reports builtins imported with use that are never called.",
+	Severity: Warning,
+	Run: func(pass *Pass) {
+		called := make(map[string]bool)
+		gate.Inspect(pass.File, func(node gate.Node) bool {
+			if call, ok := node.(*gate.StructCallExpr); ok {
+				called[call.Fun.Name] = true
+			}
+			return true
+		})
+
+		for _, use := range pass.File.Uses() {
+			for _, name := range use.Names {
+				if !called[name.Name] {
+					pass.Reportf(name, "%s is imported but never used", name.Name)
+				}
+			}
+		}
+	},
+}
+
+// UnusedParam reports params that are not referenced by any source or invariant.
+var UnusedParam = &Rule{
+	Name:     "unused-param",
+	Doc:      "Reports params that are not referenced by any source or invariant.",
+	Severity: Warning,
+	Run: func(pass *Pass) {
+		used := make(map[string]bool)
+		for _, decl := range pass.File.Decls {
+			for _, ref := range gate.References(decl) {
+				used[ref.Name] = true
+			}
+		}
+
+		for _, param := range pass.File.Params() {
+			if !used[param.Name.Name] {
+				pass.Reportf(param.Name, "param %s is never used", param.Name.Name)
+			}
+		}
+	},
+}
+
+// UnreachableSource reports sources whose value does not flow into any invariant, directly or through
+// other sources. Hexagate still evaluates them on every block.
+var UnreachableSource = &Rule{
+	Name:     "unreachable-source",
+	Doc:      "Reports sources that do not reach any invariant.",
+	Severity: Warning,
+	Run: func(pass *Pass) {
+		reached := make(map[string]bool)
+		var queue []string
+		for _, invariant := range pass.File.Invariants() {
+			for _, ref := range gate.References(invariant) {
+				queue = append(queue, ref.Name)
+			}
+		}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			if reached[name] {
+				continue
+			}
+			reached[name] = true
+			if source := pass.File.Source(name); source != nil {
+				for _, ref := range gate.References(source) {
+					queue = append(queue, ref.Name)
+				}
+			}
+		}
+
+		for _, source := range pass.File.Sources() {
+			if !reached[source.Name.Name] {
+				pass.Reportf(source.Name, "source %s does not reach any invariant", source.Name.Name)
+			}
+		}
+	},
+}
+
+// ShadowedName reports comprehension variables that hide a param, a source or an enclosing comprehension
+// variable of the same name.
+var ShadowedName = &Rule{
+	Name:     "shadowed-name",
+	Doc:      "Reports comprehension variables that shadow a param, source or outer variable.",
+	Severity: Warning,
+	Run: func(pass *Pass) {
+		for _, decl := range pass.File.Decls {
+			gate.Resolve(decl, func(*gate.Ident, bool) {}, func(v *gate.Ident, outer *gate.Scope) {
+				switch {
+				case outer.Lookup(v.Name):
+					pass.Reportf(v, "%s shadows an enclosing comprehension variable", v.Name)
+				case pass.File.Param(v.Name) != nil:
+					pass.Reportf(v, "%s shadows param %s", v.Name, v.Name)
+				case pass.File.Source(v.Name) != nil:
+					pass.Reportf(v, "%s shadows source %s", v.Name, v.Name)
+				}
+			})
+		}
+	},
+}
@@ -0,0 +1,88 @@
+package lint
+
+import (
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// TraceGuard reports invariants of monitors with a FilterAddressesInTrace source that are not wrapped in
+//
+//	(Len { sequence: addressesInTrace } > 0) ? ... : true
+//
+// Without the guard the invariant is evaluated on every block, not only on blocks that touch the filtered
+// addresses, and alerts on state that the monitor was not meant to look at.
+var TraceGuard = &Rule{
+	Name:     "trace-guard",
+	Doc:      "Reports invariants that are not guarded by the FilterAddressesInTrace source of their monitor.",
+	Severity: Warning,
+	Run: func(pass *Pass) {
+		filter := traceFilter(pass.File)
+		if filter == nil {
+			return
+		}
+		for _, invariant := range pass.File.Invariants() {
+			if condition := invariant.Condition(); condition != nil && !guarded(condition, filter.Name.Name) {
+				pass.Reportf(condition, "invariant %q is not guarded by (Len { sequence: %s } > 0) ? ... : true", invariant.Description(), filter.Name.Name)
+			}
+		}
+	},
+}
+
+// TraceFilter reports Per DisputeGame monitors, recognized by their disputeGame param, that do not filter
+// on the dispute game being in the block trace at all.
+var TraceFilter = &Rule{
+	Name:     "trace-filter",
+	Doc:      "Reports monitors with a disputeGame param that have no FilterAddressesInTrace source.",
+	Severity: Warning,
+	Run: func(pass *Pass) {
+		param := pass.File.Param("disputeGame")
+		if param == nil || traceFilter(pass.File) != nil {
+			return
+		}
+		pass.Reportf(param.Name, "monitor takes a disputeGame param but has no FilterAddressesInTrace source")
+	},
+}
+
+// traceFilter returns the source of file whose value is a FilterAddressesInTrace call, or nil.
+func traceFilter(file *gate.File) *gate.SourceDecl {
+	for _, source := range file.Sources() {
+		if call, ok := source.Value.(*gate.StructCallExpr); ok && call.Fun.Name == "FilterAddressesInTrace" {
+			return source
+		}
+	}
+	return nil
+}
+
+// guarded reports whether condition has the form (Len { sequence: filter } > 0) ? ... : true.
+func guarded(condition gate.Expr, filter string) bool {
+	ternary, ok := unparen(condition).(*gate.TernaryExpr)
+	if !ok {
+		return false
+	}
+	if lit, ok := unparen(ternary.Else).(*gate.BoolLit); !ok || !lit.Value {
+		return false
+	}
+
+	cmp, ok := unparen(ternary.Cond).(*gate.BinaryExpr)
+	if !ok || cmp.Op != gate.GTR {
+		return false
+	}
+	if zero, ok := unparen(cmp.Y).(*gate.BasicLit); !ok || zero.Kind != gate.INT || zero.Value != "0" {
+		return false
+	}
+	length, ok := unparen(cmp.X).(*gate.StructCallExpr)
+	if !ok || length.Fun.Name != "Len" {
+		return false
+	}
+	sequence, ok := length.Field("sequence").(*gate.Ident)
+	return ok && sequence.Name == filter
+}
+
+func unparen(x gate.Expr) gate.Expr {
+	for {
+		paren, ok := x.(*gate.ParenExpr)
+		if !ok {
+			return x
+		}
+		x = paren.X
+	}
+}
@@ -0,0 +1,193 @@
+// Package mutate generates mutants of gate monitors: copies of a monitor with a single small change to its
+// logic, such as a flipped comparison or a dropped filter. A test suite that still passes against a mutant
+// would not notice the same mistake in the monitor.
+package mutate
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/check"
+)
+
+// Operators are the names of the mutation operators.
+var Operators = []string{
+	"comparison",
+	"logical",
+	"drop-operand",
+	"arithmetic",
+	"negation",
+	"boolean",
+	"constant",
+	"drop-filter",
+}
+
+// Mutant is a monitor with a single change applied.
+type Mutant struct {
+	Pos      gate.Pos // position of the changed code
+	Operator string   // name of the mutation operator, one of Operators
+	Original string   // the code that was replaced
+	Mutated  string   // the code it was replaced with
+	Source   []byte   // the source of the mutated monitor
+}
+
+// String describes the mutant as file:line:col: operator: original -> mutated, on a single line.
+func (m Mutant) String() string {
+	return fmt.Sprintf("%s: %s: %s -> %s", m.Pos, m.Operator, OneLine(m.Original), OneLine(m.Mutated))
+}
+
+// OneLine collapses whitespace, including line breaks and comments inside the code, to single spaces.
+func OneLine(code string) string {
+	var lines []string
+	for _, line := range strings.Split(code, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+}
+
+// edit replaces src[start:end] with text.
+type edit struct {
+	start, end int
+	text       string
+}
+
+// comparisons maps each comparison to its boundary or inverse, e.g. a flipped <= in a deficit check.
+var comparisons = map[gate.Token]gate.Token{
+	gate.LSS: gate.LEQ,
+	gate.LEQ: gate.LSS,
+	gate.GTR: gate.GEQ,
+	gate.GEQ: gate.GTR,
+	gate.EQL: gate.NEQ,
+	gate.NEQ: gate.EQL,
+}
+
+var arithmetic = map[gate.Token]gate.Token{
+	gate.ADD: gate.SUB,
+	gate.SUB: gate.ADD,
+	gate.MUL: gate.QUO,
+	gate.QUO: gate.MUL,
+}
+
+// Mutants returns every mutant of the parsed file, ordered by position. Mutants whose source does not parse
+// are left out, and so is code inside chain reads such as Call and Events.
+func Mutants(file *gate.File, src []byte) []Mutant {
+	m := &mutator{src: src}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *gate.SourceDecl:
+			gate.Inspect(d.Value, m.visit)
+		case *gate.InvariantDecl:
+			// the description only names the invariant, so only the condition is mutated
+			if cond := d.Condition(); cond != nil {
+				gate.Inspect(cond, m.visit)
+			}
+		}
+	}
+
+	var mutants []Mutant
+	for _, mutant := range m.mutants {
+		if _, err := gate.ParseFile(file.Name, mutant.Source); err != nil {
+			continue
+		}
+		mutants = append(mutants, mutant)
+	}
+	sort.SliceStable(mutants, func(i, j int) bool { return mutants[i].Pos.Offset < mutants[j].Pos.Offset })
+	return mutants
+}
+
+type mutator struct {
+	src     []byte
+	mutants []Mutant
+}
+
+func (m *mutator) visit(node gate.Node) bool {
+	switch x := node.(type) {
+	case *gate.StructCallExpr:
+		// the arguments of a chain read only shape the request to the chain, which tests replace with a mock
+		return !check.IsChainRead(x.Fun.Name)
+
+	case *gate.BinaryExpr:
+		op := len(x.Op.String())
+		if to, ok := comparisons[x.Op]; ok {
+			m.add("comparison", x.OpPos, edit{x.OpPos.Offset, x.OpPos.Offset + op, to.String()})
+		}
+		if to, ok := arithmetic[x.Op]; ok {
+			m.add("arithmetic", x.OpPos, edit{x.OpPos.Offset, x.OpPos.Offset + op, to.String()})
+		}
+		if x.Op == gate.AND || x.Op == gate.OR {
+			to := gate.OR
+			if x.Op == gate.OR {
+				to = gate.AND
+			}
+			m.add("logical", x.OpPos, edit{x.OpPos.Offset, x.OpPos.Offset + op, to.String()})
+			// keep only one side, as if the other clause had been forgotten
+			start, end := x.Pos().Offset, x.End().Offset
+			m.add("drop-operand", x.OpPos, edit{start, end, string(m.src[x.Y.Pos().Offset:end])})
+			m.add("drop-operand", x.OpPos, edit{start, end, string(m.src[start:x.X.End().Offset])})
+		}
+
+	case *gate.UnaryExpr:
+		if x.Op == gate.NOT {
+			m.add("negation", x.OpPos, edit{x.OpPos.Offset, x.X.Pos().Offset, ""})
+		}
+
+	case *gate.TernaryExpr:
+		m.add("negation", x.Cond.Pos(), edit{x.Cond.Pos().Offset, x.Cond.Pos().Offset, "!("}, edit{x.Cond.End().Offset, x.Cond.End().Offset, ")"})
+
+	case *gate.BoolLit:
+		m.add("boolean", x.ValuePos, edit{x.ValuePos.Offset, x.End().Offset, fmt.Sprint(!x.Value)})
+
+	case *gate.BasicLit:
+		if x.Kind != gate.INT {
+			break
+		}
+		n, ok := new(big.Int).SetString(x.Value, 10)
+		if !ok {
+			break
+		}
+		m.add("constant", x.ValuePos, edit{x.ValuePos.Offset, x.End().Offset, new(big.Int).Add(n, big.NewInt(1)).String()})
+		if n.Sign() > 0 {
+			m.add("constant", x.ValuePos, edit{x.ValuePos.Offset, x.End().Offset, new(big.Int).Sub(n, big.NewInt(1)).String()})
+		}
+
+	case *gate.ListComp:
+		if x.Cond != nil {
+			m.add("drop-filter", x.If, edit{x.If.Offset, x.Cond.End().Offset, ""})
+		}
+
+	case *gate.MapComp:
+		if x.Cond != nil {
+			m.add("drop-filter", x.If, edit{x.If.Offset, x.Cond.End().Offset, ""})
+		}
+	}
+	return true
+}
+
+// add records a mutant applying the edits, which must not overlap and are given in source order.
+func (m *mutator) add(operator string, pos gate.Pos, edits ...edit) {
+	var src []byte
+	last := 0
+	for _, e := range edits {
+		src = append(src, m.src[last:e.start]...)
+		src = append(src, e.text...)
+		last = e.end
+	}
+	src = append(src, m.src[last:]...)
+
+	first, final := edits[0], edits[len(edits)-1]
+	original := string(m.src[first.start:final.end])
+	mutated := string(src[first.start : len(src)-(len(m.src)-final.end)])
+	m.mutants = append(m.mutants, Mutant{
+		Pos:      pos,
+		Operator: operator,
+		Original: original,
+		Mutated:  mutated,
+		Source:   src,
+	})
+}
@@ -0,0 +1,99 @@
+package mutate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+const MONITOR = `use Call, Len from hexagate;
+
+param disputeGame: address;
+
+source credit: integer = Call {
+    contract: disputeGame,
+    signature: "function credit(address) returns (uint256)",
+    params: tuple(disputeGame)
+};
+source credits: list<integer> = [c for c in list(credit) if c > 0];
+
+// the credit must be covered and not zero
+invariant {
+    description: "Deficit of ETH",
+    condition: credit <= 10 and !(credit == 0) ? true : false
+};
+`
+
+func mutants(t *testing.T) []Mutant {
+	file, err := gate.ParseFile("example.gate", []byte(MONITOR))
+	if err != nil {
+		t.Fatalf("Error parsing monitor: %v", err)
+	}
+	return Mutants(file, []byte(MONITOR))
+}
+
+func TestMutants(t *testing.T) {
+	// We expect one mutant per operator application, outside of chain reads and invariant descriptions
+	var got []string
+	for _, m := range mutants(t) {
+		got = append(got, m.String())
+	}
+
+	expected := []string{
+		"example.gate:10:58: drop-filter: if c > 0 -> ",
+		"example.gate:10:63: comparison: > -> >=",
+		"example.gate:10:65: constant: 0 -> 1",
+		"example.gate:15:16: negation: credit <= 10 and !(credit == 0) -> !(credit <= 10 and !(credit == 0))",
+		"example.gate:15:23: comparison: <= -> <",
+		"example.gate:15:26: constant: 10 -> 11",
+		"example.gate:15:26: constant: 10 -> 9",
+		"example.gate:15:29: logical: and -> or",
+		"example.gate:15:29: drop-operand: credit <= 10 and !(credit == 0) -> !(credit == 0)",
+		"example.gate:15:29: drop-operand: credit <= 10 and !(credit == 0) -> credit <= 10",
+		"example.gate:15:33: negation: ! -> ",
+		"example.gate:15:42: comparison: == -> !=",
+		"example.gate:15:45: constant: 0 -> 1",
+		"example.gate:15:50: boolean: true -> false",
+		"example.gate:15:57: boolean: false -> true",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected mutants:\n got %s\nwant %s", strings.Join(got, "\n     "), strings.Join(expected, "\n     "))
+	}
+}
+
+func TestMutantSource(t *testing.T) {
+	// We expect every mutant to parse and to differ from the monitor only at the mutated code
+	for _, m := range mutants(t) {
+		if _, err := gate.ParseFile("example.gate", m.Source); err != nil {
+			t.Errorf("Mutant %s does not parse: %v", m, err)
+		}
+		if !replacedOnce(MONITOR, m.Original, m.Mutated, string(m.Source)) {
+			t.Errorf("Mutant %s changes more than its code:\n%s", m, m.Source)
+		}
+	}
+
+	m := mutants(t)[0]
+	if strings.Contains(string(m.Source), "if c > 0") || !strings.Contains(string(m.Source), "[c for c in list(credit) ]") {
+		t.Errorf("Expected the filter to be dropped, got:\n%s", m.Source)
+	}
+}
+
+// replacedOnce reports whether mutated is src with one occurrence of original replaced.
+func replacedOnce(src, original, replacement, mutated string) bool {
+	for i := 0; i+len(original) <= len(src); i++ {
+		if src[i:i+len(original)] == original && src[:i]+replacement+src[i+len(original):] == mutated {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOneLine(t *testing.T) {
+	// We expect line breaks and comments inside mutated code to collapse to single spaces
+	code := "a and // first\n    b"
+	if got := OneLine(code); got != "a and b" {
+		t.Errorf("OneLine(%q) = %q", code, got)
+	}
+}
@@ -0,0 +1,412 @@
+package gate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrorList is a list of syntax errors, sorted by position.
+type ErrorList []*Error
+
+func (list ErrorList) Error() string {
+	switch len(list) {
+	case 0:
+		return "no errors"
+	case 1:
+		return list[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", list[0], len(list)-1)
+}
+
+// Err returns the list as an error, or nil if it is empty.
+func (list ErrorList) Err() error {
+	if len(list) == 0 {
+		return nil
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Pos.Offset < list[j].Pos.Offset
+	})
+	return list
+}
+
+// bailout is used to unwind the parser to the next declaration after a syntax error.
+type bailout struct{}
+
+type parser struct {
+	scanner  *Scanner
+	errors   ErrorList
+	comments []*Comment
+
+	pos Pos
+	tok Token
+	lit string
+}
+
+// ParseFile parses the source text of the named gate file. Every position in the returned syntax tree
+// carries the filename, so errors and diagnostics can be reported as file:line:col.
+// On syntax errors the returned file holds every declaration that parsed and the error is an ErrorList.
+func ParseFile(filename string, src []byte) (*File, error) {
+	p := &parser{scanner: NewScanner(filename, src)}
+	p.next()
+
+	file := &File{Name: filename}
+	for p.tok != EOF {
+		if decl := p.parseDeclSafely(); decl != nil {
+			file.Decls = append(file.Decls, decl)
+		}
+	}
+	file.Comments = p.comments
+
+	p.errors = append(p.errors, p.scanner.Errors...)
+	return file, p.errors.Err()
+}
+
+// Parse parses gate source text that does not come from a named file.
+func Parse(src []byte) (*File, error) {
+	return ParseFile("", src)
+}
+
+func (p *parser) next() {
+	for {
+		p.pos, p.tok, p.lit = p.scanner.Scan()
+		if p.tok != COMMENT {
+			return
+		}
+		p.comments = append(p.comments, &Comment{Slash: p.pos, Text: p.lit})
+	}
+}
+
+func (p *parser) errorf(pos Pos, format string, args ...any) {
+	p.errors = append(p.errors, &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)})
+	panic(bailout{})
+}
+
+func (p *parser) describe() string {
+	switch p.tok {
+	case IDENT:
+		return "identifier " + p.lit
+	case INT, HEX, STRING:
+		return fmt.Sprintf("%s %s", strings.ToLower(p.tok.String()), p.lit)
+	case EOF:
+		return "end of file"
+	}
+	return fmt.Sprintf("'%s'", p.tok)
+}
+
+func (p *parser) expect(tok Token) Pos {
+	pos := p.pos
+	if p.tok != tok {
+		p.errorf(pos, "expected '%s', found %s", tok, p.describe())
+	}
+	p.next()
+	return pos
+}
+
+func (p *parser) parseIdent() *Ident {
+	pos, name := p.pos, p.lit
+	if p.tok != IDENT {
+		p.errorf(pos, "expected identifier, found %s", p.describe())
+	}
+	p.next()
+	return &Ident{NamePos: pos, Name: name}
+}
+
+// parseFieldName accepts keywords as well as identifiers since builtins may use either as field names.
+func (p *parser) parseFieldName() *Ident {
+	if p.tok.IsKeyword() {
+		ident := &Ident{NamePos: p.pos, Name: p.lit}
+		p.next()
+		return ident
+	}
+	return p.parseIdent()
+}
+
+// ----------------------------------------------------------------------------
+// Declarations
+
+func (p *parser) parseDeclSafely() (decl Decl) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+			// skip to the end of the broken declaration
+			for p.tok != EOF && p.tok != SEMICOLON {
+				p.next()
+			}
+			if p.tok == SEMICOLON {
+				p.next()
+			}
+			decl = nil
+		}
+	}()
+	return p.parseDecl()
+}
+
+func (p *parser) parseDecl() Decl {
+	switch p.tok {
+	case USE:
+		return p.parseUseDecl()
+	case PARAM:
+		return p.parseParamDecl()
+	case SOURCE:
+		return p.parseSourceDecl()
+	case INVARIANT:
+		return p.parseInvariantDecl()
+	}
+	p.errorf(p.pos, "expected declaration, found %s", p.describe())
+	return nil
+}
+
+func (p *parser) parseUseDecl() *UseDecl {
+	decl := &UseDecl{Use: p.expect(USE)}
+	decl.Names = append(decl.Names, p.parseIdent())
+	for p.tok == COMMA {
+		p.next()
+		decl.Names = append(decl.Names, p.parseIdent())
+	}
+	decl.From = p.expect(FROM)
+	decl.Module = p.parseIdent()
+	decl.Semicolon = p.expect(SEMICOLON)
+	return decl
+}
+
+func (p *parser) parseParamDecl() *ParamDecl {
+	decl := &ParamDecl{Param: p.expect(PARAM)}
+	decl.Name = p.parseIdent()
+	p.expect(COLON)
+	decl.Type = p.parseType()
+	decl.Semicolon = p.expect(SEMICOLON)
+	return decl
+}
+
+func (p *parser) parseSourceDecl() *SourceDecl {
+	decl := &SourceDecl{Source: p.expect(SOURCE)}
+	decl.Name = p.parseIdent()
+	p.expect(COLON)
+	decl.Type = p.parseType()
+	decl.Assign = p.expect(ASSIGN)
+	decl.Value = p.parseExpr()
+	decl.Semicolon = p.expect(SEMICOLON)
+	return decl
+}
+
+func (p *parser) parseInvariantDecl() *InvariantDecl {
+	decl := &InvariantDecl{Invariant: p.expect(INVARIANT)}
+	decl.Lbrace, decl.Fields, decl.Rbrace = p.parseFields()
+	decl.Semicolon = p.expect(SEMICOLON)
+	return decl
+}
+
+func (p *parser) parseFields() (Pos, []*Field, Pos) {
+	lbrace := p.expect(LBRACE)
+	var fields []*Field
+	for p.tok != RBRACE && p.tok != EOF {
+		field := &Field{Name: p.parseFieldName()}
+		field.Colon = p.expect(COLON)
+		field.Value = p.parseExpr()
+		fields = append(fields, field)
+		if p.tok != COMMA {
+			break
+		}
+		p.next()
+	}
+	rbrace := p.expect(RBRACE)
+	return lbrace, fields, rbrace
+}
+
+// ----------------------------------------------------------------------------
+// Types
+
+func (p *parser) parseType() TypeExpr {
+	name := p.parseIdent()
+	switch name.Name {
+	case "list":
+		typ := &ListType{List: name.NamePos}
+		p.expect(LSS)
+		typ.Elem = p.parseType()
+		typ.Rangle = p.expect(GTR)
+		return typ
+	case "tuple":
+		typ := &TupleType{Tuple: name.NamePos}
+		p.expect(LSS)
+		typ.Elems = append(typ.Elems, p.parseType())
+		for p.tok == COMMA {
+			p.next()
+			typ.Elems = append(typ.Elems, p.parseType())
+		}
+		typ.Rangle = p.expect(GTR)
+		return typ
+	case "map":
+		typ := &MapType{Map: name.NamePos}
+		p.expect(LSS)
+		typ.Key = p.parseType()
+		p.expect(COMMA)
+		typ.Value = p.parseType()
+		typ.Rangle = p.expect(GTR)
+		return typ
+	}
+	return &NamedType{NamePos: name.NamePos, Name: name.Name}
+}
+
+// ----------------------------------------------------------------------------
+// Expressions
+
+func (p *parser) parseExpr() Expr {
+	cond := p.parseBinaryExpr(1)
+	if p.tok != QUESTION {
+		return cond
+	}
+
+	expr := &TernaryExpr{Cond: cond, Question: p.pos}
+	p.next()
+	expr.Then = p.parseExpr()
+	expr.Colon = p.expect(COLON)
+	expr.Else = p.parseExpr()
+	return expr
+}
+
+func (p *parser) parseBinaryExpr(prec1 int) Expr {
+	x := p.parseUnaryExpr()
+	for {
+		op := p.tok
+		prec := op.Precedence()
+		if prec < prec1 {
+			return x
+		}
+		pos := p.pos
+		p.next()
+
+		// exponentiation is right associative, everything else is left associative
+		var y Expr
+		if op == POW {
+			y = p.parseBinaryExpr(prec)
+		} else {
+			y = p.parseBinaryExpr(prec + 1)
+		}
+		x = &BinaryExpr{X: x, OpPos: pos, Op: op, Y: y}
+	}
+}
+
+func (p *parser) parseUnaryExpr() Expr {
+	switch p.tok {
+	case NOT, SUB:
+		pos, op := p.pos, p.tok
+		p.next()
+		return &UnaryExpr{OpPos: pos, Op: op, X: p.parseUnaryExpr()}
+	}
+	return p.parsePostfixExpr(p.parsePrimaryExpr())
+}
+
+func (p *parser) parsePostfixExpr(x Expr) Expr {
+	for p.tok == LBRACK {
+		expr := &IndexExpr{X: x, Lbrack: p.pos}
+		p.next()
+		expr.Index = p.parseExpr()
+		expr.Rbrack = p.expect(RBRACK)
+		x = expr
+	}
+	return x
+}
+
+func (p *parser) parsePrimaryExpr() Expr {
+	switch p.tok {
+	case IDENT:
+		ident := p.parseIdent()
+		switch p.tok {
+		case LBRACE:
+			call := &StructCallExpr{Fun: ident}
+			call.Lbrace, call.Fields, call.Rbrace = p.parseFields()
+			return call
+		case LPAREN:
+			return p.parseCallExpr(ident)
+		}
+		return ident
+	case INT, HEX, STRING:
+		lit := &BasicLit{ValuePos: p.pos, Kind: p.tok, Value: p.lit}
+		p.next()
+		return lit
+	case TRUE, FALSE:
+		lit := &BoolLit{ValuePos: p.pos, Value: p.tok == TRUE}
+		p.next()
+		return lit
+	case LPAREN:
+		expr := &ParenExpr{Lparen: p.pos}
+		p.next()
+		expr.X = p.parseExpr()
+		expr.Rparen = p.expect(RPAREN)
+		return expr
+	case LBRACK:
+		return p.parseListExpr()
+	case LBRACE:
+		return p.parseMapComp()
+	}
+	p.errorf(p.pos, "expected expression, found %s", p.describe())
+	return nil
+}
+
+func (p *parser) parseCallExpr(fun *Ident) *CallExpr {
+	call := &CallExpr{Fun: fun, Lparen: p.expect(LPAREN)}
+	for p.tok != RPAREN && p.tok != EOF {
+		call.Args = append(call.Args, p.parseExpr())
+		if p.tok != COMMA {
+			break
+		}
+		p.next()
+	}
+	call.Rparen = p.expect(RPAREN)
+	return call
+}
+
+func (p *parser) parseListExpr() Expr {
+	lbrack := p.expect(LBRACK)
+	if p.tok == RBRACK {
+		return &ListLit{Lbrack: lbrack, Rbrack: p.expect(RBRACK)}
+	}
+
+	first := p.parseExpr()
+	if p.tok == FOR {
+		comp := &ListComp{Lbrack: lbrack, Elem: first}
+		comp.For, comp.Var, comp.Seq, comp.If, comp.Cond = p.parseComprehensionClause()
+		comp.Rbrack = p.expect(RBRACK)
+		return comp
+	}
+
+	list := &ListLit{Lbrack: lbrack, Elems: []Expr{first}}
+	for p.tok == COMMA {
+		p.next()
+		if p.tok == RBRACK {
+			break
+		}
+		list.Elems = append(list.Elems, p.parseExpr())
+	}
+	list.Rbrack = p.expect(RBRACK)
+	return list
+}
+
+func (p *parser) parseMapComp() *MapComp {
+	comp := &MapComp{Lbrace: p.expect(LBRACE)}
+	comp.Key = p.parseExpr()
+	comp.Colon = p.expect(COLON)
+	comp.Value = p.parseExpr()
+	if p.tok != FOR {
+		p.errorf(p.pos, "expected 'for' in map comprehension, found %s", p.describe())
+	}
+	comp.For, comp.Var, comp.Seq, comp.If, comp.Cond = p.parseComprehensionClause()
+	comp.Rbrace = p.expect(RBRACE)
+	return comp
+}
+
+func (p *parser) parseComprehensionClause() (forPos Pos, variable *Ident, seq Expr, ifPos Pos, cond Expr) {
+	forPos = p.expect(FOR)
+	variable = p.parseIdent()
+	p.expect(IN)
+	seq = p.parseExpr()
+	if p.tok == IF {
+		ifPos = p.pos
+		p.next()
+		cond = p.parseExpr()
+	}
+	return forPos, variable, seq, ifPos, cond
+}
@@ -0,0 +1,243 @@
+package gate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMonitors(t *testing.T) {
+	// We expect every monitor to parse without errors and every node to carry a position in the monitor file
+	invariants := map[string]int{
+		"challenged_proposal.gate":          1,
+		"challenger_loses.gate":             3,
+		"credit_and_bond_discrepancy.gate":  1,
+		"duplicate_dispute_game.gate":       1,
+		"eth_deficit.gate":                  1,
+		"eth_withdrawn_early.gate":          1,
+		"fault_proof_detection_child.gate":  2,
+		"fault_proof_detection_parent.gate": 2,
+		"incorrect_bond_balance.gate":       1,
+		"unresolvable_dispute_game.gate":    1,
+	}
+
+	paths, err := filepath.Glob("../monitors/*.gate")
+	if err != nil {
+		t.Fatalf("Error listing monitors: %v", err)
+	}
+	if len(paths) != len(invariants) {
+		t.Fatalf("Expected %d monitors, found %d", len(invariants), len(paths))
+	}
+
+	for _, path := range paths {
+		name := filepath.Base(path)
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Error reading %s: %v", path, err)
+			}
+
+			file, err := ParseFile(path, src)
+			if err != nil {
+				t.Fatalf("Error parsing %s: %v", path, err)
+			}
+
+			if got := len(file.Invariants()); got != invariants[name] {
+				t.Errorf("Expected %d invariants, got %d", invariants[name], got)
+			}
+			if len(file.Uses()) != 1 || file.Uses()[0].Module.Name != "hexagate" {
+				t.Errorf("Expected a single use declaration from hexagate")
+			}
+
+			Inspect(file, func(node Node) bool {
+				if node == nil {
+					return false
+				}
+				pos, end := node.Pos(), node.End()
+				if !pos.IsValid() || pos.Filename != path {
+					t.Errorf("Node %T has invalid position %v", node, pos)
+				}
+				if end.Offset <= pos.Offset {
+					t.Errorf("Node %T at %v ends at %v", node, pos, end)
+				}
+				return true
+			})
+		})
+	}
+}
+
+func TestParseDeclarations(t *testing.T) {
+	src := `use Call, Range, Len from hexagate;
+
+param disputeGame: address;
+
+source claimData: list<
+  tuple<integer,address,address,integer,bytes,integer,integer>
+> = [
+    Call {
+        contract: disputeGame,
+        signature: "function claimData(uint256 idx) view returns (uint32,address,address,uint128,bytes32,uint128,uint128)",
+        params: tuple(index)
+    }
+    for index in Range { start: 0, stop: 3 }
+    if index > 0
+];
+
+source games: map<bytes, tuple<integer,tuple<address,integer,bytes>>> = { g[0][1][2]: g for g in list() };
+
+invariant {
+    description: "No claims",
+    condition: Len { sequence: claimData } > 0 ? claimData[0][1] != disputeGame : true
+};
+`
+	file, err := ParseFile("test.gate", []byte(src))
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+
+	uses := file.Uses()[0]
+	if len(uses.Names) != 3 || uses.Names[2].Name != "Len" {
+		t.Errorf("Unexpected use names: %v", uses.Names)
+	}
+
+	param := file.Param("disputeGame")
+	if param == nil || TypeString(param.Type) != "address" {
+		t.Fatalf("Expected param disputeGame: address")
+	}
+
+	claimData := file.Source("claimData")
+	if got := TypeString(claimData.Type); got != "list<tuple<integer, address, address, integer, bytes, integer, integer>>" {
+		t.Errorf("Unexpected claimData type %s", got)
+	}
+	comp, ok := claimData.Value.(*ListComp)
+	if !ok {
+		t.Fatalf("Expected claimData to be a list comprehension, got %T", claimData.Value)
+	}
+	call, ok := comp.Elem.(*StructCallExpr)
+	if !ok || call.Fun.Name != "Call" || len(call.Fields) != 3 {
+		t.Fatalf("Expected a Call with 3 fields, got %#v", comp.Elem)
+	}
+	if params, ok := call.Field("params").(*CallExpr); !ok || params.Fun.Name != "tuple" {
+		t.Errorf("Expected params to be a tuple call")
+	}
+	if comp.Var.Name != "index" || comp.Cond == nil {
+		t.Errorf("Expected comprehension over index with a filter")
+	}
+	if comp.Var.Pos().String() != "test.gate:13:9" {
+		t.Errorf("Unexpected position of comprehension variable: %s", comp.Var.Pos())
+	}
+
+	games := file.Source("games")
+	if got := TypeString(games.Type); got != "map<bytes, tuple<integer, tuple<address, integer, bytes>>>" {
+		t.Errorf("Unexpected games type %s", got)
+	}
+	mapComp, ok := games.Value.(*MapComp)
+	if !ok {
+		t.Fatalf("Expected games to be a map comprehension, got %T", games.Value)
+	}
+	index, ok := mapComp.Key.(*IndexExpr)
+	if !ok || index.Index.(*BasicLit).Value != "2" {
+		t.Errorf("Expected key g[0][1][2], got %#v", mapComp.Key)
+	}
+
+	invariant := file.Invariants()[0]
+	if invariant.Description() != "No claims" {
+		t.Errorf("Unexpected description %q", invariant.Description())
+	}
+	if _, ok := invariant.Condition().(*TernaryExpr); !ok {
+		t.Errorf("Expected ternary condition, got %T", invariant.Condition())
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	tests := map[string]string{
+		"!a and b == c + d * e ** f ** g":  "((!a) and (b == (c + (d * (e ** (f ** g))))))",
+		"a or b and c":                     "(a or (b and c))",
+		"a ? b : c ? d : e":                "(a ? b : (c ? d : e))",
+		"x[0][1] - 1 - 2 < 3":              "(((x[0][1] - 1) - 2) < 3)",
+		"Len { sequence: a } > 0 ? !b : c": "((Len{...} > 0) ? (!b) : c)",
+	}
+
+	for expr, expected := range tests {
+		file, err := Parse([]byte("source x: boolean = " + expr + ";"))
+		if err != nil {
+			t.Fatalf("Error parsing %q: %v", expr, err)
+		}
+		if got := sexpr(file.Sources()[0].Value); got != expected {
+			t.Errorf("Parsing %q: expected %s, got %s", expr, expected, got)
+		}
+	}
+}
+
+// sexpr renders an expression fully parenthesized so tests can check how it was grouped.
+func sexpr(expr Expr) string {
+	switch x := expr.(type) {
+	case *Ident:
+		return x.Name
+	case *BasicLit:
+		return x.Value
+	case *UnaryExpr:
+		return "(" + x.Op.String() + sexpr(x.X) + ")"
+	case *BinaryExpr:
+		return "(" + sexpr(x.X) + " " + x.Op.String() + " " + sexpr(x.Y) + ")"
+	case *TernaryExpr:
+		return "(" + sexpr(x.Cond) + " ? " + sexpr(x.Then) + " : " + sexpr(x.Else) + ")"
+	case *IndexExpr:
+		return sexpr(x.X) + "[" + sexpr(x.Index) + "]"
+	case *StructCallExpr:
+		return x.Fun.Name + "{...}"
+	}
+	return "?"
+}
+
+func TestParseErrors(t *testing.T) {
+	// We expect errors to be reported as file:line:col and parsing to resume at the next declaration
+	src := `param disputeGame address;
+source ok: integer = 1;
+source broken: integer = (1 + ;
+invariant { description: "x", condition: ok > 0 };
+`
+	file, err := ParseFile("broken.gate", []byte(src))
+	if err == nil {
+		t.Fatalf("Expected syntax errors")
+	}
+
+	list, ok := err.(ErrorList)
+	if !ok || len(list) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", err)
+	}
+	if got := list[0].Error(); got != "broken.gate:1:19: expected ':', found identifier address" {
+		t.Errorf("Unexpected first error %q", got)
+	}
+	if !strings.HasPrefix(list[1].Error(), "broken.gate:3:31: expected expression") {
+		t.Errorf("Unexpected second error %q", list[1].Error())
+	}
+
+	if file.Source("ok") == nil || len(file.Invariants()) != 1 {
+		t.Errorf("Expected the valid declarations to be kept")
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	src := `// leading comment
+source claimCount: integer = 1; // trailing comment
+/* block
+   comment */
+source other: integer = claimCount;
+`
+	file, err := ParseFile("comments.gate", []byte(src))
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+
+	if len(file.Comments) != 3 {
+		t.Fatalf("Expected 3 comments, got %d", len(file.Comments))
+	}
+	if file.Comments[1].Text != "// trailing comment" || file.Comments[1].Pos().String() != "comments.gate:2:33" {
+		t.Errorf("Unexpected trailing comment %q at %s", file.Comments[1].Text, file.Comments[1].Pos())
+	}
+	if end := file.Comments[2].End(); end.Line != 4 || end.Column != 14 {
+		t.Errorf("Unexpected block comment end %s", end)
+	}
+}
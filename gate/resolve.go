@@ -0,0 +1,95 @@
+package gate
+
+// References returns the identifiers in node that refer to params or sources, skipping builtin names,
+// field names and comprehension variables.
+func References(node Node) []*Ident {
+	var refs []*Ident
+	Resolve(node, func(id *Ident, bound bool) {
+		if !bound {
+			refs = append(refs, id)
+		}
+	}, func(*Ident, *Scope) {})
+	return refs
+}
+
+// Scope is the set of comprehension variables visible at a point in an expression. The nil scope is empty.
+type Scope struct {
+	Name   string
+	Parent *Scope
+}
+
+// Lookup reports whether a comprehension variable of the given name is visible in the scope.
+func (s *Scope) Lookup(name string) bool {
+	for ; s != nil; s = s.Parent {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve calls use for every identifier in node that names a value, reporting whether it is bound by an
+// enclosing comprehension, and bind for every comprehension variable with the scope it is declared in.
+func Resolve(node Node, use func(id *Ident, bound bool), bind func(v *Ident, outer *Scope)) {
+	var expr func(x Expr, sc *Scope)
+	comprehension := func(v *Ident, seq, cond Expr, sc *Scope) *Scope {
+		expr(seq, sc)
+		bind(v, sc)
+		inner := &Scope{Name: v.Name, Parent: sc}
+		if cond != nil {
+			expr(cond, inner)
+		}
+		return inner
+	}
+
+	expr = func(x Expr, sc *Scope) {
+		switch x := x.(type) {
+		case *Ident:
+			use(x, sc.Lookup(x.Name))
+		case *ParenExpr:
+			expr(x.X, sc)
+		case *UnaryExpr:
+			expr(x.X, sc)
+		case *BinaryExpr:
+			expr(x.X, sc)
+			expr(x.Y, sc)
+		case *TernaryExpr:
+			expr(x.Cond, sc)
+			expr(x.Then, sc)
+			expr(x.Else, sc)
+		case *IndexExpr:
+			expr(x.X, sc)
+			expr(x.Index, sc)
+		case *CallExpr:
+			for _, arg := range x.Args {
+				expr(arg, sc)
+			}
+		case *StructCallExpr:
+			for _, field := range x.Fields {
+				expr(field.Value, sc)
+			}
+		case *ListLit:
+			for _, elem := range x.Elems {
+				expr(elem, sc)
+			}
+		case *ListComp:
+			inner := comprehension(x.Var, x.Seq, x.Cond, sc)
+			expr(x.Elem, inner)
+		case *MapComp:
+			inner := comprehension(x.Var, x.Seq, x.Cond, sc)
+			expr(x.Key, inner)
+			expr(x.Value, inner)
+		}
+	}
+
+	switch n := node.(type) {
+	case *SourceDecl:
+		expr(n.Value, nil)
+	case *InvariantDecl:
+		for _, field := range n.Fields {
+			expr(field.Value, nil)
+		}
+	case Expr:
+		expr(n, nil)
+	}
+}
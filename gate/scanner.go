@@ -0,0 +1,259 @@
+package gate
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Pos describes a location in a gate source file. Lines and columns start at 1.
+type Pos struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+// IsValid reports whether the position has been set.
+func (p Pos) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as file:line:col, or line:col when the file name is unknown.
+func (p Pos) String() string {
+	if !p.IsValid() {
+		if p.Filename != "" {
+			return p.Filename
+		}
+		return "-"
+	}
+	if p.Filename != "" {
+		return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Error is a syntax error found while scanning or parsing a gate file.
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Scanner tokenizes gate source text.
+type Scanner struct {
+	filename string
+	src      []byte
+
+	ch       rune
+	offset   int
+	rdOffset int
+	line     int
+	column   int
+
+	Errors []*Error
+}
+
+// NewScanner returns a scanner positioned at the start of src. The filename is recorded in every position.
+func NewScanner(filename string, src []byte) *Scanner {
+	s := &Scanner{filename: filename, src: src, line: 1}
+	s.next()
+	return s
+}
+
+func (s *Scanner) next() {
+	if s.rdOffset >= len(s.src) {
+		if s.ch == '\n' {
+			s.line++
+			s.column = 0
+		}
+		s.offset = len(s.src)
+		s.ch = -1
+		return
+	}
+
+	if s.ch == '\n' {
+		s.line++
+		s.column = 0
+	}
+	s.offset = s.rdOffset
+	r, w := rune(s.src[s.rdOffset]), 1
+	if r >= utf8.RuneSelf {
+		r, w = utf8.DecodeRune(s.src[s.rdOffset:])
+	}
+	s.rdOffset += w
+	s.column++
+	s.ch = r
+}
+
+func (s *Scanner) peek() byte {
+	if s.rdOffset < len(s.src) {
+		return s.src[s.rdOffset]
+	}
+	return 0
+}
+
+func (s *Scanner) pos() Pos {
+	return Pos{Filename: s.filename, Offset: s.offset, Line: s.line, Column: s.column}
+}
+
+func (s *Scanner) error(pos Pos, msg string) {
+	s.Errors = append(s.Errors, &Error{Pos: pos, Msg: msg})
+}
+
+func (s *Scanner) skipWhitespace() {
+	for s.ch == ' ' || s.ch == '\t' || s.ch == '\n' || s.ch == '\r' {
+		s.next()
+	}
+}
+
+// Scan returns the next token, its position and its literal text.
+// Comments are returned as COMMENT tokens so callers can decide whether to keep them.
+func (s *Scanner) Scan() (Pos, Token, string) {
+	s.skipWhitespace()
+
+	pos := s.pos()
+	start := s.offset
+
+	switch ch := s.ch; {
+	case ch == -1:
+		return pos, EOF, ""
+	case isLetter(ch):
+		for isLetter(s.ch) || isDigit(s.ch) {
+			s.next()
+		}
+		lit := string(s.src[start:s.offset])
+		return pos, Lookup(lit), lit
+	case isDigit(ch):
+		if ch == '0' && (s.peek() == 'x' || s.peek() == 'X') {
+			s.next()
+			s.next()
+			for isHex(s.ch) {
+				s.next()
+			}
+			lit := string(s.src[start:s.offset])
+			if len(lit) == 2 {
+				s.error(pos, "hexadecimal literal has no digits")
+			}
+			return pos, HEX, lit
+		}
+		for isDigit(s.ch) {
+			s.next()
+		}
+		return pos, INT, string(s.src[start:s.offset])
+	case ch == '"':
+		s.next()
+		for s.ch != '"' {
+			if s.ch == '\n' || s.ch == -1 {
+				s.error(pos, "string literal not terminated")
+				return pos, STRING, string(s.src[start:s.offset])
+			}
+			if s.ch == '\\' {
+				s.next()
+			}
+			s.next()
+		}
+		s.next()
+		return pos, STRING, string(s.src[start:s.offset])
+	case ch == '/' && s.peek() == '/':
+		for s.ch != '\n' && s.ch != -1 {
+			s.next()
+		}
+		return pos, COMMENT, string(s.src[start:s.offset])
+	case ch == '/' && s.peek() == '*':
+		s.next()
+		s.next()
+		for !(s.ch == '*' && s.peek() == '/') {
+			if s.ch == -1 {
+				s.error(pos, "comment not terminated")
+				return pos, COMMENT, string(s.src[start:s.offset])
+			}
+			s.next()
+		}
+		s.next()
+		s.next()
+		return pos, COMMENT, string(s.src[start:s.offset])
+	}
+
+	ch := s.ch
+	s.next()
+	switch ch {
+	case '+':
+		return pos, ADD, "+"
+	case '-':
+		return pos, SUB, "-"
+	case '*':
+		if s.ch == '*' {
+			s.next()
+			return pos, POW, "**"
+		}
+		return pos, MUL, "*"
+	case '/':
+		return pos, QUO, "/"
+	case '%':
+		return pos, REM, "%"
+	case '=':
+		if s.ch == '=' {
+			s.next()
+			return pos, EQL, "=="
+		}
+		return pos, ASSIGN, "="
+	case '!':
+		if s.ch == '=' {
+			s.next()
+			return pos, NEQ, "!="
+		}
+		return pos, NOT, "!"
+	case '<':
+		if s.ch == '=' {
+			s.next()
+			return pos, LEQ, "<="
+		}
+		return pos, LSS, "<"
+	case '>':
+		// '>>' is never a shift operator in gate, it always closes two nested type arguments
+		if s.ch == '=' {
+			s.next()
+			return pos, GEQ, ">="
+		}
+		return pos, GTR, ">"
+	case '?':
+		return pos, QUESTION, "?"
+	case ':':
+		return pos, COLON, ":"
+	case ',':
+		return pos, COMMA, ","
+	case ';':
+		return pos, SEMICOLON, ";"
+	case '(':
+		return pos, LPAREN, "("
+	case ')':
+		return pos, RPAREN, ")"
+	case '{':
+		return pos, LBRACE, "{"
+	case '}':
+		return pos, RBRACE, "}"
+	case '[':
+		return pos, LBRACK, "["
+	case ']':
+		return pos, RBRACK, "]"
+	}
+
+	s.error(pos, fmt.Sprintf("illegal character %q", ch))
+	return pos, ILLEGAL, string(ch)
+}
+
+func isLetter(ch rune) bool {
+	return ch == '_' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch >= utf8.RuneSelf && unicode.IsLetter(ch)
+}
+
+func isDigit(ch rune) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+func isHex(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
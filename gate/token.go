@@ -0,0 +1,159 @@
+package gate
+
+import "fmt"
+
+// Token is the set of lexical tokens of the gate language.
+type Token int
+
+const (
+	ILLEGAL Token = iota
+	EOF
+	COMMENT
+
+	// literals
+	IDENT  // disputeGame
+	INT    // 12345
+	HEX    // 0x00000000000000000000000000000000000000AA
+	STRING // "Dispute game is unresolved"
+
+	// operators and delimiters
+	ADD // +
+	SUB // -
+	MUL // *
+	QUO // /
+	REM // %
+	POW // **
+
+	EQL // ==
+	NEQ // !=
+	LSS // <
+	GTR // >
+	LEQ // <=
+	GEQ // >=
+	NOT // !
+
+	ASSIGN    // =
+	QUESTION  // ?
+	COLON     // :
+	COMMA     // ,
+	SEMICOLON // ;
+	LPAREN    // (
+	RPAREN    // )
+	LBRACE    // {
+	RBRACE    // }
+	LBRACK    // [
+	RBRACK    // ]
+
+	// keywords
+	USE
+	FROM
+	PARAM
+	SOURCE
+	INVARIANT
+	FOR
+	IN
+	IF
+	AND
+	OR
+	TRUE
+	FALSE
+)
+
+var tokens = [...]string{
+	ILLEGAL: "ILLEGAL",
+	EOF:     "EOF",
+	COMMENT: "COMMENT",
+
+	IDENT:  "IDENT",
+	INT:    "INT",
+	HEX:    "HEX",
+	STRING: "STRING",
+
+	ADD: "+",
+	SUB: "-",
+	MUL: "*",
+	QUO: "/",
+	REM: "%",
+	POW: "**",
+
+	EQL: "==",
+	NEQ: "!=",
+	LSS: "<",
+	GTR: ">",
+	LEQ: "<=",
+	GEQ: ">=",
+	NOT: "!",
+
+	ASSIGN:    "=",
+	QUESTION:  "?",
+	COLON:     ":",
+	COMMA:     ",",
+	SEMICOLON: ";",
+	LPAREN:    "(",
+	RPAREN:    ")",
+	LBRACE:    "{",
+	RBRACE:    "}",
+	LBRACK:    "[",
+	RBRACK:    "]",
+
+	USE:       "use",
+	FROM:      "from",
+	PARAM:     "param",
+	SOURCE:    "source",
+	INVARIANT: "invariant",
+	FOR:       "for",
+	IN:        "in",
+	IF:        "if",
+	AND:       "and",
+	OR:        "or",
+	TRUE:      "true",
+	FALSE:     "false",
+}
+
+func (tok Token) String() string {
+	if tok >= 0 && int(tok) < len(tokens) && tokens[tok] != "" {
+		return tokens[tok]
+	}
+	return fmt.Sprintf("token(%d)", int(tok))
+}
+
+var keywords = map[string]Token{}
+
+func init() {
+	for tok := USE; tok <= FALSE; tok++ {
+		keywords[tokens[tok]] = tok
+	}
+}
+
+// Lookup maps an identifier to its keyword token, or IDENT if it is not a keyword.
+func Lookup(ident string) Token {
+	if tok, ok := keywords[ident]; ok {
+		return tok
+	}
+	return IDENT
+}
+
+// IsKeyword reports whether tok is a keyword.
+func (tok Token) IsKeyword() bool {
+	return tok >= USE && tok <= FALSE
+}
+
+// Precedence returns the binary operator precedence of tok, or 0 if tok is not a binary operator.
+// Ternaries bind looser than every binary operator and are handled separately by the parser.
+func (tok Token) Precedence() int {
+	switch tok {
+	case OR:
+		return 1
+	case AND:
+		return 2
+	case EQL, NEQ, LSS, GTR, LEQ, GEQ:
+		return 3
+	case ADD, SUB:
+		return 4
+	case MUL, QUO, REM:
+		return 5
+	case POW:
+		return 6
+	}
+	return 0
+}
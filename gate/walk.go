@@ -0,0 +1,146 @@
+package gate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Visitor is called for each node encountered by Walk. If the returned visitor w is not nil,
+// Walk visits each of the children of node with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses a syntax tree in depth-first order, in the same way as go/ast.Walk.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *File:
+		for _, decl := range n.Decls {
+			Walk(v, decl)
+		}
+
+	// declarations
+	case *UseDecl:
+		for _, name := range n.Names {
+			Walk(v, name)
+		}
+		Walk(v, n.Module)
+	case *ParamDecl:
+		Walk(v, n.Name)
+		Walk(v, n.Type)
+	case *SourceDecl:
+		Walk(v, n.Name)
+		Walk(v, n.Type)
+		Walk(v, n.Value)
+	case *InvariantDecl:
+		for _, field := range n.Fields {
+			Walk(v, field)
+		}
+	case *Field:
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+
+	// types
+	case *NamedType:
+		// nothing to do
+	case *ListType:
+		Walk(v, n.Elem)
+	case *TupleType:
+		for _, elem := range n.Elems {
+			Walk(v, elem)
+		}
+	case *MapType:
+		Walk(v, n.Key)
+		Walk(v, n.Value)
+
+	// expressions
+	case *Ident, *BasicLit, *BoolLit:
+		// nothing to do
+	case *ParenExpr:
+		Walk(v, n.X)
+	case *UnaryExpr:
+		Walk(v, n.X)
+	case *BinaryExpr:
+		Walk(v, n.X)
+		Walk(v, n.Y)
+	case *TernaryExpr:
+		Walk(v, n.Cond)
+		Walk(v, n.Then)
+		Walk(v, n.Else)
+	case *IndexExpr:
+		Walk(v, n.X)
+		Walk(v, n.Index)
+	case *CallExpr:
+		Walk(v, n.Fun)
+		for _, arg := range n.Args {
+			Walk(v, arg)
+		}
+	case *StructCallExpr:
+		Walk(v, n.Fun)
+		for _, field := range n.Fields {
+			Walk(v, field)
+		}
+	case *ListLit:
+		for _, elem := range n.Elems {
+			Walk(v, elem)
+		}
+	case *ListComp:
+		Walk(v, n.Elem)
+		Walk(v, n.Var)
+		Walk(v, n.Seq)
+		if n.Cond != nil {
+			Walk(v, n.Cond)
+		}
+	case *MapComp:
+		Walk(v, n.Key)
+		Walk(v, n.Value)
+		Walk(v, n.Var)
+		Walk(v, n.Seq)
+		if n.Cond != nil {
+			Walk(v, n.Cond)
+		}
+
+	default:
+		panic(fmt.Sprintf("gate.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a syntax tree in depth-first order, calling f for each node and then f(nil)
+// after its children. Children are skipped when f returns false.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// TypeString renders a type expression in canonical form, e.g. list<tuple<integer, address>>.
+func TypeString(typ TypeExpr) string {
+	switch t := typ.(type) {
+	case *NamedType:
+		return t.Name
+	case *ListType:
+		return "list<" + TypeString(t.Elem) + ">"
+	case *TupleType:
+		elems := make([]string, len(t.Elems))
+		for i, elem := range t.Elems {
+			elems[i] = TypeString(elem)
+		}
+		return "tuple<" + strings.Join(elems, ", ") + ">"
+	case *MapType:
+		return "map<" + TypeString(t.Key) + ", " + TypeString(t.Value) + ">"
+	}
+	return fmt.Sprintf("%T", typ)
+}
@@ -2,4 +2,10 @@ module github.com/base-org/fault-proof-monitors
 
 go 1.21.1
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/crypto v0.21.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.18.0 // indirect
@@ -0,0 +1,265 @@
+// Package hexagate is a client for the Hexagate API, used to validate gate monitors against mocked chain
+// data before they are deployed.
+package hexagate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	DEFAULT_BASE_URL = "https://api.hexagate.com"
+	DEFAULT_TIMEOUT  = 60 * time.Second
+	VALIDATE_PATH    = "/api/v1/invariants/validate"
+	API_KEY_HEADER   = "X-Hexagate-Api-Key"
+	API_KEY_ENV      = "HEXAGATE_API_KEY"
+)
+
+// Chain IDs that monitors are commonly validated against.
+const (
+	CHAIN_ID_MAINNET      = 1
+	CHAIN_ID_SEPOLIA      = 11155111
+	CHAIN_ID_BASE         = 8453
+	CHAIN_ID_BASE_SEPOLIA = 84532
+)
+
+type ValidateRequest struct {
+	Gate    string         `json:"gate"`
+	ChainId int            `json:"chain_id"`
+	Params  map[string]any `json:"params"`
+	Mocks   map[string]any `json:"mocks"`
+	Trace   bool           `json:"trace"`
+}
+
+// KeySource returns the API key requests are authenticated with.
+type KeySource func(ctx context.Context) (string, error)
+
+// StaticKey always returns key.
+func StaticKey(key string) KeySource {
+	return func(context.Context) (string, error) {
+		return key, nil
+	}
+}
+
+// EnvKey reads the key from the named environment variable on every request.
+func EnvKey(name string) KeySource {
+	return func(context.Context) (string, error) {
+		return os.Getenv(name), nil
+	}
+}
+
+// DotEnvKey loads the .env file at path into the environment the first time a key is needed and then reads
+// the key from the named environment variable. A missing file is not an error, as the key may be set in the
+// environment directly.
+func DotEnvKey(path, name string) KeySource {
+	var once sync.Once
+	var loadErr error
+	return func(context.Context) (string, error) {
+		once.Do(func() {
+			if err := godotenv.Load(path); err != nil && !os.IsNotExist(err) {
+				loadErr = fmt.Errorf("error loading %s: %w", path, err)
+			}
+		})
+		if loadErr != nil {
+			return "", loadErr
+		}
+		return os.Getenv(name), nil
+	}
+}
+
+// Client calls the Hexagate API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	chainID    int
+	apiKey     KeySource
+	httpClient *http.Client
+	timeout    time.Duration
+	preflight  func(ctx context.Context, request ValidateRequest) error
+	observer   func(ctx context.Context, request ValidateRequest, response *ValidateResponse)
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets the URL the API paths are resolved against, e.g. the URL of a fake server in tests.
+func WithBaseURL(url string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimSuffix(url, "/") }
+}
+
+// WithChainID sets the chain ID used for requests that do not set one.
+func WithChainID(chainID int) Option {
+	return func(c *Client) { c.chainID = chainID }
+}
+
+// WithAPIKey sets where the API key comes from.
+func WithAPIKey(source KeySource) Option {
+	return func(c *Client) { c.apiKey = source }
+}
+
+// WithHTTPClient sets the HTTP client requests are sent with.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithTimeout bounds how long a single request may take. Zero disables the timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) { c.timeout = timeout }
+}
+
+// WithPreflight sets a check that every validate request must pass before it is sent. A request failing the
+// check is not sent and Validate returns the error of the check.
+func WithPreflight(check func(ctx context.Context, request ValidateRequest) error) Option {
+	return func(c *Client) { c.preflight = check }
+}
+
+// WithObserver sets a function that is called with every validate request and its response, e.g. to record
+// which invariants a test suite fires. It is not called for requests that fail.
+func WithObserver(observe func(ctx context.Context, request ValidateRequest, response *ValidateResponse)) Option {
+	return func(c *Client) { c.observer = observe }
+}
+
+// NewClient creates a client for the Hexagate API on mainnet, authenticated with the HEXAGATE_API_KEY
+// environment variable, unless configured otherwise.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:    DEFAULT_BASE_URL,
+		chainID:    CHAIN_ID_MAINNET,
+		apiKey:     EnvKey(API_KEY_ENV),
+		httpClient: http.DefaultClient,
+		timeout:    DEFAULT_TIMEOUT,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the URL the client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// ChainID returns the chain ID used for requests that do not set one.
+func (c *Client) ChainID() int {
+	return c.chainID
+}
+
+// Validate runs a gate monitor against the mocked sources and params in the request and returns the
+// invariants that failed, the exceptions raised and, if requested, the trace of every source. A request
+// without a chain ID uses the chain ID of the client.
+func (c *Client) Validate(ctx context.Context, request ValidateRequest) (*ValidateResponse, error) {
+	if request.ChainId == 0 {
+		request.ChainId = c.chainID
+	}
+	if c.preflight != nil {
+		if err := c.preflight(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := c.do(ctx, http.MethodPost, VALIDATE_PATH, request)
+	if err != nil {
+		return nil, err
+	}
+
+	// an error body in a successful response, e.g. {"detail": "..."}, is an API error rather than a response
+	// without alerts
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(body, &keys); err == nil {
+		if _, ok := keys["failed"]; !ok {
+			if detail := errorDetail(body); detail != "" {
+				return nil, &APIError{
+					Method:     http.MethodPost,
+					Path:       VALIDATE_PATH,
+					StatusCode: http.StatusOK,
+					Detail:     detail,
+					Body:       truncate(body),
+				}
+			}
+		}
+	}
+
+	var response ValidateResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, invalidResponse(http.MethodPost, VALIDATE_PATH, body, err)
+	}
+	if c.observer != nil {
+		c.observer(ctx, request, &response)
+	}
+	return &response, nil
+}
+
+// do sends a JSON request to the API and returns the body of a successful response. Non-2xx responses are
+// returned as an *APIError.
+func (c *Client) do(ctx context.Context, method, path string, in any) ([]byte, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	// marshal data into expected JSON format, keeping gate operators such as > and & unescaped
+	var reqBody io.Reader
+	if in != nil {
+		data := new(bytes.Buffer)
+		enc := json.NewEncoder(data)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(in); err != nil {
+			return nil, err
+		}
+		reqBody = data
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	key, err := c.apiKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		req.Header.Set(API_KEY_HEADER, key)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &APIError{
+			Method:     method,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Detail:     errorDetail(body),
+			Body:       truncate(body),
+		}
+	}
+	return body, nil
+}
+
+func truncate(body []byte) []byte {
+	if len(body) > MAX_ERROR_BODY {
+		return body[:MAX_ERROR_BODY]
+	}
+	return body
+}
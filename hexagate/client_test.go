@@ -0,0 +1,207 @@
+package hexagate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/base-org/fault-proof-monitors/hexagate"
+	"github.com/base-org/fault-proof-monitors/hexagate/hexagatetest"
+)
+
+const GATE = "source claimCount: integer = 3;\ninvariant { description: \"too many claims\", condition: claimCount < 3 };"
+
+func TestClientChainID(t *testing.T) {
+	// We expect requests without a chain ID to use the client's, and requests with one to keep it
+	server := hexagatetest.NewServer(nil)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		name    string
+		client  int
+		request int
+		want    int
+	}{
+		{"default", 0, 0, hexagate.CHAIN_ID_MAINNET},
+		{"base", hexagate.CHAIN_ID_BASE, 0, hexagate.CHAIN_ID_BASE},
+		{"sepolia", hexagate.CHAIN_ID_SEPOLIA, 0, hexagate.CHAIN_ID_SEPOLIA},
+		{"request overrides client", hexagate.CHAIN_ID_BASE, hexagate.CHAIN_ID_BASE_SEPOLIA, hexagate.CHAIN_ID_BASE_SEPOLIA},
+	} {
+		var opts []hexagate.Option
+		if tc.client != 0 {
+			opts = append(opts, hexagate.WithChainID(tc.client))
+		}
+		client := server.NewClient(opts...)
+
+		_, err := client.Validate(context.Background(), hexagate.ValidateRequest{Gate: GATE, ChainId: tc.request})
+		if err != nil {
+			t.Fatalf("%s: error handling validate request: %v", tc.name, err)
+		}
+		requests := server.Requests()
+		if got := requests[len(requests)-1].ChainId; got != tc.want {
+			t.Errorf("%s: expected chain ID %d, got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestClientRequest(t *testing.T) {
+	// We expect the request to carry the API key and the gate file without HTML escaping
+	var header http.Header
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != hexagate.VALIDATE_PATH {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		header = r.Header.Clone()
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		_ = json.NewEncoder(w).Encode(hexagate.ValidateResponse{Count: 1, Failed: []hexagate.Alert{{Description: "too many claims"}}})
+	}))
+	defer server.Close()
+
+	keys := 0
+	client := hexagate.NewClient(
+		hexagate.WithBaseURL(server.URL+"/"),
+		hexagate.WithAPIKey(func(context.Context) (string, error) {
+			keys++
+			return "secret", nil
+		}),
+		hexagate.WithHTTPClient(server.Client()),
+	)
+	response, err := client.Validate(context.Background(), hexagate.ValidateRequest{Gate: GATE})
+	if err != nil {
+		t.Fatalf("Error handling validate request: %v", err)
+	}
+
+	if got := header.Get(hexagate.API_KEY_HEADER); got != "secret" {
+		t.Errorf("Expected API key header to be secret, got %q", got)
+	}
+	if got := header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected a JSON content type, got %q", got)
+	}
+	if !strings.Contains(body, "claimCount < 3") {
+		t.Errorf("Expected the gate file to be sent unescaped, got %s", body)
+	}
+	if keys != 1 {
+		t.Errorf("Expected the key source to be called once, got %d", keys)
+	}
+	if response.Count != 1 || len(response.Failed) != 1 {
+		t.Errorf("Unexpected response %+v", response)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	// We expect non-2xx responses and error bodies to surface as an *APIError with the detail of the body
+	for _, tc := range []struct {
+		name   string
+		status int
+		body   string
+		detail string
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"detail": "missing API key"}`, "missing API key"},
+		{"validation", http.StatusUnprocessableEntity, `{"detail": [{"loc": ["body", "chain_id"], "msg": "field required"}]}`, "body.chain_id: field required"},
+		{"server error", http.StatusBadGateway, `<html>bad gateway</html>`, ""},
+		{"error body", http.StatusOK, `{"error": "invalid gate"}`, "invalid gate"},
+		{"invalid body", http.StatusOK, `<html>maintenance</html>`, "invalid response: invalid character '<' looking for beginning of value"},
+		{"invalid alert", http.StatusOK, `{"failed": [[1]]}`, "invalid response: invalid alert [1]: description must be a string"},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			_, _ = io.WriteString(w, tc.body)
+		}))
+
+		client := hexagate.NewClient(hexagate.WithBaseURL(server.URL), hexagate.WithAPIKey(hexagate.StaticKey("")))
+		_, err := client.Validate(context.Background(), hexagate.ValidateRequest{Gate: GATE})
+		server.Close()
+
+		var apiErr *hexagate.APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("%s: expected an *APIError, got %v", tc.name, err)
+			continue
+		}
+		if apiErr.StatusCode != tc.status || apiErr.Detail != tc.detail {
+			t.Errorf("%s: expected status %d and detail %q, got %d and %q", tc.name, tc.status, tc.detail, apiErr.StatusCode, apiErr.Detail)
+		}
+		if apiErr.Unauthorized() != (tc.status == http.StatusUnauthorized) {
+			t.Errorf("%s: unexpected Unauthorized() for status %d", tc.name, tc.status)
+		}
+		if tc.detail == "" && !strings.Contains(err.Error(), "bad gateway") {
+			t.Errorf("%s: expected the body in the error message, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	// We expect a slow server to fail the request once the client timeout expires
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := hexagate.NewClient(
+		hexagate.WithBaseURL(server.URL),
+		hexagate.WithAPIKey(hexagate.StaticKey("secret")),
+		hexagate.WithTimeout(10*time.Millisecond),
+	)
+	_, err := client.Validate(context.Background(), hexagate.ValidateRequest{Gate: GATE})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+}
+
+func TestClientPreflight(t *testing.T) {
+	// We expect a request failing the preflight check not to be sent
+	server := hexagatetest.NewServer(nil)
+	defer server.Close()
+
+	errRejected := errors.New("rejected")
+	client := server.NewClient(hexagate.WithPreflight(func(ctx context.Context, request hexagate.ValidateRequest) error {
+		if request.ChainId != hexagate.CHAIN_ID_MAINNET {
+			t.Errorf("Expected the preflight check to see the default chain ID, got %d", request.ChainId)
+		}
+		return errRejected
+	}))
+	_, err := client.Validate(context.Background(), hexagate.ValidateRequest{Gate: GATE})
+	if !errors.Is(err, errRejected) {
+		t.Errorf("Expected the preflight error, got %v", err)
+	}
+	if len(server.Requests()) != 0 {
+		t.Errorf("Expected no request to be sent, got %d", len(server.Requests()))
+	}
+}
+
+func TestClientObserver(t *testing.T) {
+	// We expect the observer to see every successful response, and no request that failed
+	server := hexagatetest.NewServer(nil)
+	defer server.Close()
+
+	var observed []*hexagate.ValidateResponse
+	client := server.NewClient(
+		hexagate.WithPreflight(func(ctx context.Context, request hexagate.ValidateRequest) error {
+			if request.Gate == "" {
+				return errors.New("empty gate")
+			}
+			return nil
+		}),
+		hexagate.WithObserver(func(ctx context.Context, request hexagate.ValidateRequest, response *hexagate.ValidateResponse) {
+			observed = append(observed, response)
+		}),
+	)
+	response, err := client.Validate(context.Background(), hexagate.ValidateRequest{Gate: GATE})
+	if err != nil {
+		t.Fatalf("Error handling validate request: %v", err)
+	}
+	if _, err := client.Validate(context.Background(), hexagate.ValidateRequest{}); err == nil {
+		t.Fatalf("Expected the empty request to fail")
+	}
+
+	if len(observed) != 1 || observed[0] != response {
+		t.Errorf("Expected the observer to see only the successful response, got %v", observed)
+	}
+}
@@ -0,0 +1,116 @@
+package hexagate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MAX_ERROR_BODY is the number of bytes of an error response that are kept for the error message.
+const MAX_ERROR_BODY = 4096
+
+// Errors an *APIError matches with errors.Is, depending on its status code.
+var (
+	ErrUnauthorized = errors.New("hexagate: unauthorized")
+	ErrNotFound     = errors.New("hexagate: not found")
+	ErrConflict     = errors.New("hexagate: conflict")
+	ErrInvalid      = errors.New("hexagate: invalid request")
+	ErrRateLimited  = errors.New("hexagate: rate limited")
+)
+
+// APIError is returned when Hexagate answers with a non-2xx status code, or with an error body instead of
+// the expected response.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// Detail is the error message taken from the response body, if it had one.
+	Detail string
+	// Body is the raw response body, truncated to MAX_ERROR_BODY bytes.
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("hexagate: %s %s", e.Method, e.Path)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" returned %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	} else {
+		msg += " failed"
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	} else if body := strings.TrimSpace(string(e.Body)); body != "" {
+		msg += ": " + body
+	}
+	return msg
+}
+
+// Unauthorized reports whether the request was rejected because of a missing or invalid API key.
+func (e *APIError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// Is matches the sentinel error for the status code, e.g. errors.Is(err, ErrNotFound) for a 404.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Unauthorized()
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrInvalid:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// Temporary reports whether the request may succeed when retried.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// errorDetail extracts the error message from an error body such as {"detail": "..."}, {"error": "..."} or
+// {"message": "..."}. Validation errors reported as {"detail": [{"loc": [...], "msg": "..."}]} are joined
+// into a single message. It returns "" if the body carries no error message.
+func errorDetail(body []byte) string {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return ""
+	}
+	for _, key := range []string{"detail", "error", "message"} {
+		raw, ok := obj[key]
+		if !ok {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s
+		}
+		var items []struct {
+			Loc []any  `json:"loc"`
+			Msg string `json:"msg"`
+		}
+		if err := json.Unmarshal(raw, &items); err == nil && len(items) > 0 {
+			var msgs []string
+			for _, item := range items {
+				var loc []string
+				for _, part := range item.Loc {
+					loc = append(loc, fmt.Sprint(part))
+				}
+				if len(loc) > 0 {
+					msgs = append(msgs, strings.Join(loc, ".")+": "+item.Msg)
+				} else {
+					msgs = append(msgs, item.Msg)
+				}
+			}
+			return strings.Join(msgs, "; ")
+		}
+		return string(raw)
+	}
+	return ""
+}
@@ -0,0 +1,306 @@
+package hexagatetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+// ErrNoCassette is returned in replay mode for a request that has no recorded cassette.
+var ErrNoCassette = errors.New("no cassette recorded for request")
+
+// Cassette is a recorded validate request and the response Hexagate gave to it. The gate file is stored as a
+// hash only, the params and mocks in full so that stale cassettes can be explained.
+type Cassette struct {
+	Hash     string          `json:"hash"`
+	GateHash string          `json:"gate_hash"`
+	ChainId  int             `json:"chain_id"`
+	Params   json.RawMessage `json:"params"`
+	Mocks    json.RawMessage `json:"mocks"`
+	Trace    bool            `json:"trace"`
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+// Cassettes is an http.RoundTripper that records validate requests to, or replays them from, a directory
+// holding one <hash>.json file per request, where the hash covers the whole request body. In record mode
+// requests are sent on to the real API and successful responses are stored. In replay mode responses are
+// served from disk and nothing is sent over the network, so a request that does not match a cassette
+// exactly, because its gate file, params or mocks changed since recording, fails.
+type Cassettes struct {
+	dir    string
+	next   http.RoundTripper
+	record bool
+
+	mu       sync.Mutex
+	recorded []*Cassette // loaded lazily in replay mode to explain misses
+}
+
+// NewRecorder returns a transport that sends requests with next and records every successful response in dir.
+// A nil next uses http.DefaultTransport.
+func NewRecorder(dir string, next http.RoundTripper) *Cassettes {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Cassettes{dir: dir, next: next, record: true}
+}
+
+// NewReplayer returns a transport that answers requests from the cassettes in dir.
+func NewReplayer(dir string) *Cassettes {
+	return &Cassettes{dir: dir}
+}
+
+// HashRequest returns the key a request body is recorded under.
+func HashRequest(body []byte) string {
+	sum := sha256.Sum256(bytes.TrimSpace(body))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (c *Cassettes) path(hash string) string {
+	return filepath.Join(c.dir, hash+".json")
+}
+
+func (c *Cassettes) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	hash := HashRequest(body)
+
+	if c.record {
+		return c.recordRequest(req, body, hash)
+	}
+	return c.replay(req, body, hash)
+}
+
+func (c *Cassettes) recordRequest(req *http.Request, body []byte, hash string) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	resp, err := c.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// errors such as a missing API key or rate limiting are not worth replaying
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, nil
+	}
+
+	cassette, err := newCassette(body, hash)
+	if err != nil {
+		return nil, err
+	}
+	cassette.Status = resp.StatusCode
+	cassette.Response = json.RawMessage(respBody)
+
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(c.path(hash), append(data, '\n'), 0o644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Cassettes) replay(req *http.Request, body []byte, hash string) (*http.Response, error) {
+	data, err := os.ReadFile(c.path(hash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, c.explainMiss(body, hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", c.path(hash), err)
+	}
+	if cassette.Hash != hash {
+		return nil, fmt.Errorf("cassette %s was recorded for request %s", c.path(hash), cassette.Hash)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cassette.Status, http.StatusText(cassette.Status)),
+		StatusCode:    cassette.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(cassette.Response)),
+		ContentLength: int64(len(cassette.Response)),
+		Request:       req,
+	}, nil
+}
+
+// explainMiss returns an ErrNoCassette error that names the recorded cassettes sharing the gate file, params
+// or mocks of the request, as those are most likely stale recordings of the same test, and every field of the
+// request that changed since each of them was recorded. Cassettes that changed in fewer fields are listed first.
+func (c *Cassettes) explainMiss(body []byte, hash string) error {
+	request, err := newCassette(body, hash)
+	if err != nil {
+		return fmt.Errorf("%w %s: %v", ErrNoCassette, hash, err)
+	}
+	recorded, err := c.loadRecorded()
+	if err != nil {
+		return err
+	}
+
+	type candidate struct {
+		path    string
+		changed []string
+	}
+	var stale []candidate
+	for _, cassette := range recorded {
+		var changed []string
+		related := false
+		if cassette.GateHash != request.GateHash {
+			changed = append(changed, "gate file")
+		} else {
+			related = true
+		}
+		if cassette.ChainId != request.ChainId {
+			changed = append(changed, "chain ID")
+		}
+		if !sameJSON(cassette.Params, request.Params) {
+			changed = append(changed, "params")
+		} else if !emptyJSON(request.Params) {
+			related = true
+		}
+		if !sameJSON(cassette.Mocks, request.Mocks) {
+			changed = append(changed, "mocks")
+		} else if !emptyJSON(request.Mocks) {
+			related = true
+		}
+		if cassette.Trace != request.Trace {
+			changed = append(changed, "trace")
+		}
+		if related && len(changed) > 0 {
+			stale = append(stale, candidate{c.path(cassette.Hash), changed})
+		}
+	}
+
+	if len(stale) > 0 {
+		sort.Slice(stale, func(i, j int) bool {
+			if len(stale[i].changed) != len(stale[j].changed) {
+				return len(stale[i].changed) < len(stale[j].changed)
+			}
+			return stale[i].path < stale[j].path
+		})
+		var names []string
+		for _, s := range stale {
+			names = append(names, fmt.Sprintf("%s (%s changed since recording)", s.path, strings.Join(s.changed, ", ")))
+		}
+		return fmt.Errorf("%w %s, stale cassettes: %s; re-record with -record", ErrNoCassette, hash, strings.Join(names, "; "))
+	}
+	return fmt.Errorf("%w %s in %s; record it with -record", ErrNoCassette, hash, c.dir)
+}
+
+func (c *Cassettes) loadRecorded() ([]*Cassette, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.recorded != nil {
+		return c.recorded, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	c.recorded = []*Cassette{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var cassette Cassette
+		if err := json.Unmarshal(data, &cassette); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+		}
+		c.recorded = append(c.recorded, &cassette)
+	}
+	return c.recorded, nil
+}
+
+// sameJSON reports whether two JSON documents are equal up to whitespace, as cassettes are stored indented.
+func sameJSON(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
+
+// emptyJSON reports whether a JSON document is missing, null or an empty object, which many unrelated requests
+// share.
+func emptyJSON(a json.RawMessage) bool {
+	var ca bytes.Buffer
+	if json.Compact(&ca, a) != nil {
+		return len(bytes.TrimSpace(a)) == 0
+	}
+	value := ca.String()
+	return value == "" || value == "null" || value == "{}"
+}
+
+// newCassette decodes a validate request body into the fields a cassette keeps of it.
+func newCassette(body []byte, hash string) (*Cassette, error) {
+	var request struct {
+		Gate    string          `json:"gate"`
+		ChainId int             `json:"chain_id"`
+		Params  json.RawMessage `json:"params"`
+		Mocks   json.RawMessage `json:"mocks"`
+		Trace   bool            `json:"trace"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("invalid validate request: %w", err)
+	}
+	gateHash := sha256.Sum256([]byte(request.Gate))
+	return &Cassette{
+		Hash:     hash,
+		GateHash: hex.EncodeToString(gateHash[:]),
+		ChainId:  request.ChainId,
+		Params:   request.Params,
+		Mocks:    request.Mocks,
+		Trace:    request.Trace,
+	}, nil
+}
+
+// NewRecordingClient returns a Hexagate client whose validate responses are recorded in dir.
+func NewRecordingClient(dir string, opts ...hexagate.Option) *hexagate.Client {
+	return hexagate.NewClient(append(opts, hexagate.WithHTTPClient(&http.Client{Transport: NewRecorder(dir, nil)}))...)
+}
+
+// NewReplayingClient returns a Hexagate client that answers validate requests from the cassettes in dir,
+// without an API key or network access.
+func NewReplayingClient(dir string, opts ...hexagate.Option) *hexagate.Client {
+	return hexagate.NewClient(append([]hexagate.Option{
+		hexagate.WithAPIKey(hexagate.StaticKey("")),
+	}, append(opts, hexagate.WithHTTPClient(&http.Client{Transport: NewReplayer(dir)}))...)...)
+}
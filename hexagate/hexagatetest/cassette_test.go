@@ -0,0 +1,113 @@
+package hexagatetest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+const CASSETTE_GATE = "param limit: integer;\nsource claimCount: integer = 3;\ninvariant { description: \"too many claims\", condition: claimCount < limit };"
+
+func TestCassettesRecordAndReplay(t *testing.T) {
+	// We expect a recorded response to be replayed without the server, and changed requests to fail loudly
+	dir := t.TempDir()
+	server := NewServer(nil)
+
+	request := hexagate.ValidateRequest{
+		Gate:   CASSETTE_GATE,
+		Params: map[string]any{"limit": 3},
+		Mocks:  map[string]any{"claimCount": 3},
+		Trace:  true,
+	}
+
+	recorder := hexagate.NewClient(
+		hexagate.WithBaseURL(server.URL),
+		hexagate.WithAPIKey(hexagate.StaticKey("")),
+		hexagate.WithHTTPClient(&http.Client{Transport: NewRecorder(dir, server.Client().Transport)}),
+	)
+	recorded, err := recorder.Validate(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Error recording validate request: %v", err)
+	}
+	server.Close()
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(paths) != 1 {
+		t.Fatalf("Expected 1 cassette to be recorded, got %v", paths)
+	}
+
+	replayer := NewReplayingClient(dir, hexagate.WithBaseURL(server.URL))
+	replayed, err := replayer.Validate(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Error replaying validate request: %v", err)
+	}
+	if !replayed.Fired("too many claims") || len(replayed.Failed) != len(recorded.Failed) {
+		t.Errorf("Expected the replayed response to match the recorded one, got %v and %v", replayed.Failed, recorded.Failed)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		change  func(*hexagate.ValidateRequest)
+		changed string
+	}{
+		{"gate", func(r *hexagate.ValidateRequest) { r.Gate += "\n" }, "gate file changed"},
+		{"mocks", func(r *hexagate.ValidateRequest) { r.Mocks = map[string]any{"claimCount": 4} }, "mocks changed"},
+		{"params", func(r *hexagate.ValidateRequest) { r.Params = map[string]any{"limit": 4} }, "params changed"},
+		{"chain", func(r *hexagate.ValidateRequest) { r.ChainId = hexagate.CHAIN_ID_BASE }, "chain ID changed"},
+		{"gate and mocks", func(r *hexagate.ValidateRequest) {
+			r.Gate += "\n"
+			r.Mocks = map[string]any{"claimCount": 4}
+		}, "gate file, mocks changed"},
+		{"params, mocks and trace", func(r *hexagate.ValidateRequest) {
+			r.Params = map[string]any{"limit": 4}
+			r.Mocks = map[string]any{"claimCount": 4}
+			r.Trace = false
+		}, "params, mocks, trace changed"},
+	} {
+		changed := request
+		tc.change(&changed)
+
+		_, err := replayer.Validate(context.Background(), changed)
+		if !errors.Is(err, ErrNoCassette) {
+			t.Errorf("%s: expected ErrNoCassette, got %v", tc.name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.changed) || !strings.Contains(err.Error(), paths[0]) {
+			t.Errorf("%s: expected the error to name the stale cassette and %q, got %v", tc.name, tc.changed, err)
+		}
+	}
+
+	// a request unrelated to any cassette is reported as missing rather than stale
+	_, err = replayer.Validate(context.Background(), hexagate.ValidateRequest{Gate: "source x: integer = 1;"})
+	if !errors.Is(err, ErrNoCassette) || strings.Contains(err.Error(), "stale") {
+		t.Errorf("Expected a missing cassette error, got %v", err)
+	}
+}
+
+func TestCassettesSkipErrors(t *testing.T) {
+	// We expect error responses to be passed through without being recorded
+	dir := t.TempDir()
+	server := NewServer(nil)
+	defer server.Close()
+
+	recorder := NewRecordingClient(dir, hexagate.WithBaseURL(server.URL), hexagate.WithAPIKey(hexagate.StaticKey("")))
+	_, err := recorder.Validate(context.Background(), hexagate.ValidateRequest{Gate: ""})
+	var apiErr *hexagate.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError for an empty gate, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no cassettes to be recorded, got %d", len(entries))
+	}
+}
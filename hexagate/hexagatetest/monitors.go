@@ -0,0 +1,217 @@
+package hexagatetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+// monitors is the state of the fake monitor management API: every deployed monitor by ID.
+type monitors struct {
+	byId   map[int]*hexagate.Monitor
+	nextId int
+}
+
+// Monitors returns a copy of every monitor deployed to the fake, ordered by ID.
+func (f *Server) Monitors() []hexagate.Monitor {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sortedMonitors()
+}
+
+// sortedMonitors returns copies of the monitors ordered by ID. The caller must hold f.mu.
+func (f *Server) sortedMonitors() []hexagate.Monitor {
+	monitors := make([]hexagate.Monitor, 0, len(f.monitors.byId))
+	for _, m := range f.monitors.byId {
+		monitors = append(monitors, *m)
+	}
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].Id < monitors[j].Id })
+	return monitors
+}
+
+// handleMonitors serves the collection: GET lists a page of monitors and POST creates one.
+func (f *Server) handleMonitors(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		f.listMonitors(w, r)
+	case http.MethodPost:
+		request, ok := decodeMonitorRequest(w, r)
+		if !ok {
+			return
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.nameTaken(request.Name, 0) {
+			writeError(w, http.StatusConflict, "a monitor named "+request.Name+" already exists")
+			return
+		}
+		f.monitors.nextId++
+		now := time.Now().UTC()
+		monitor := &hexagate.Monitor{Id: f.monitors.nextId, Enabled: true, CreatedAt: now}
+		apply(monitor, request, now)
+		f.monitors.byId[monitor.Id] = monitor
+		writeJSON(w, http.StatusCreated, monitor)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleMonitor serves a single monitor at MONITORS_PATH/{id}, and its enable and disable actions.
+func (f *Server) handleMonitor(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, hexagate.MONITORS_PATH+"/")
+	idText, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.Atoi(idText)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	if action != "" {
+		if r.Method != http.MethodPost || (action != "enable" && action != "disable") {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		monitor, ok := f.monitors.byId[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "monitor "+idText+" not found")
+			return
+		}
+		monitor.Enabled = action == "enable"
+		monitor.UpdatedAt = time.Now().UTC()
+		writeJSON(w, http.StatusOK, monitor)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		monitor, ok := f.monitors.byId[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "monitor "+idText+" not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, monitor)
+	case http.MethodPut:
+		request, ok := decodeMonitorRequest(w, r)
+		if !ok {
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		monitor, ok := f.monitors.byId[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "monitor "+idText+" not found")
+			return
+		}
+		if f.nameTaken(request.Name, id) {
+			writeError(w, http.StatusConflict, "a monitor named "+request.Name+" already exists")
+			return
+		}
+		apply(monitor, request, time.Now().UTC())
+		writeJSON(w, http.StatusOK, monitor)
+	case http.MethodDelete:
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.monitors.byId[id]; !ok {
+			writeError(w, http.StatusNotFound, "monitor "+idText+" not found")
+			return
+		}
+		delete(f.monitors.byId, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (f *Server) listMonitors(w http.ResponseWriter, r *http.Request) {
+	page, err := queryInt(r, "page", 1)
+	if err != nil || page < 1 {
+		writeError(w, http.StatusUnprocessableEntity, "page must be a positive integer")
+		return
+	}
+	pageSize, err := queryInt(r, "page_size", hexagate.DEFAULT_PAGE_SIZE)
+	if err != nil || pageSize < 1 || pageSize > hexagate.MAX_PAGE_SIZE {
+		writeError(w, http.StatusUnprocessableEntity, "page_size must be between 1 and "+strconv.Itoa(hexagate.MAX_PAGE_SIZE))
+		return
+	}
+
+	f.mu.Lock()
+	all := f.sortedMonitors()
+	f.mu.Unlock()
+
+	start := min((page-1)*pageSize, len(all))
+	end := min(start+pageSize, len(all))
+	writeJSON(w, http.StatusOK, hexagate.MonitorPage{Monitors: all[start:end], Page: page, PageSize: pageSize, Total: len(all)})
+}
+
+// nameTaken reports whether a monitor other than id is named name. The caller must hold f.mu.
+func (f *Server) nameTaken(name string, id int) bool {
+	for _, m := range f.monitors.byId {
+		if m.Name == name && m.Id != id {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeMonitorRequest decodes a create or update request strictly and rejects monitors Hexagate would not
+// accept: requests without a name or chain ID, and gate files that do not parse.
+func decodeMonitorRequest(w http.ResponseWriter, r *http.Request) (hexagate.MonitorRequest, bool) {
+	var request hexagate.MonitorRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return request, false
+	}
+	switch {
+	case request.Name == "":
+		writeError(w, http.StatusUnprocessableEntity, "name must not be empty")
+		return request, false
+	case request.ChainId == 0:
+		writeError(w, http.StatusUnprocessableEntity, "chain_id must be set")
+		return request, false
+	}
+	if _, err := gate.Parse([]byte(request.Gate)); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid gate: "+err.Error())
+		return request, false
+	}
+	return request, true
+}
+
+func apply(monitor *hexagate.Monitor, request hexagate.MonitorRequest, now time.Time) {
+	monitor.Name = request.Name
+	monitor.Description = request.Description
+	monitor.Gate = request.Gate
+	monitor.ChainId = request.ChainId
+	monitor.Params = request.Params
+	monitor.NotificationChannelIds = request.NotificationChannelIds
+	monitor.UpdatedAt = now
+}
+
+func queryInt(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,145 @@
+// Package hexagatetest provides an in-process fake of the Hexagate API for tests.
+package hexagatetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/interp"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+// Evaluator computes the response the fake Hexagate server returns for a validate request.
+type Evaluator func(request hexagate.ValidateRequest) hexagate.ValidateResponse
+
+// Server is an in-process stand-in for the Hexagate validate endpoint and monitor management API. It accepts
+// the same ValidateRequest JSON as the real API and answers with whatever its Evaluator returns, and keeps
+// the monitors created through it in memory.
+type Server struct {
+	*httptest.Server
+
+	evaluator Evaluator
+
+	mu       sync.Mutex
+	requests []hexagate.ValidateRequest
+	monitors monitors
+}
+
+// NewServer starts a fake Hexagate server. A nil evaluator falls back to GateEvaluator.
+func NewServer(evaluator Evaluator) *Server {
+	if evaluator == nil {
+		evaluator = GateEvaluator
+	}
+
+	fake := &Server{evaluator: evaluator, monitors: monitors{byId: make(map[int]*hexagate.Monitor)}}
+	mux := http.NewServeMux()
+	mux.HandleFunc(hexagate.VALIDATE_PATH, fake.handleValidate)
+	mux.HandleFunc(hexagate.MONITORS_PATH, fake.handleMonitors)
+	mux.HandleFunc(hexagate.MONITORS_PATH+"/", fake.handleMonitor)
+	fake.Server = httptest.NewServer(mux)
+	return fake
+}
+
+// ValidateEndpoint returns the full URL of the fake validate endpoint.
+func (f *Server) ValidateEndpoint() string {
+	return f.URL + hexagate.VALIDATE_PATH
+}
+
+// NewClient returns a Hexagate client that sends its requests to the fake.
+func (f *Server) NewClient(opts ...hexagate.Option) *hexagate.Client {
+	return hexagate.NewClient(append([]hexagate.Option{
+		hexagate.WithBaseURL(f.URL),
+		hexagate.WithAPIKey(hexagate.StaticKey("")),
+		hexagate.WithHTTPClient(f.Client()),
+	}, opts...)...)
+}
+
+// Requests returns a copy of every validate request the fake has accepted so far.
+func (f *Server) Requests() []hexagate.ValidateRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	requests := make([]hexagate.ValidateRequest, len(f.requests))
+	copy(requests, f.requests)
+	return requests
+}
+
+func (f *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// decode the request strictly so that a malformed harness request fails the same way it would remotely
+	var request hexagate.ValidateRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if request.Gate == "" {
+		writeError(w, http.StatusUnprocessableEntity, "gate must not be empty")
+		return
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, request)
+	f.mu.Unlock()
+
+	response := f.evaluator(request)
+	if response.Failed == nil {
+		response.Failed = []hexagate.Alert{}
+	}
+	if response.Exceptions == nil {
+		response.Exceptions = []hexagate.Exception{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(response)
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"detail": detail})
+}
+
+// GateEvaluator evaluates the requested gate file locally with the gate interpreter, using the mocks as the
+// values of the named sources. Sources that are not mocked only see an empty chain.
+func GateEvaluator(request hexagate.ValidateRequest) hexagate.ValidateResponse {
+	file, err := gate.Parse([]byte(request.Gate))
+	if err != nil {
+		return hexagate.ValidateResponse{Exceptions: []hexagate.Exception{{Message: err.Error()}}}
+	}
+
+	result := interp.Evaluate(file, interp.Env{
+		Params: request.Params,
+		Mocks:  request.Mocks,
+	})
+
+	response := hexagate.ValidateResponse{
+		Count:      1,
+		Failed:     []hexagate.Alert{},
+		Exceptions: []hexagate.Exception{},
+	}
+	for _, alert := range result.Failed {
+		response.Failed = append(response.Failed, hexagate.Alert{Description: alert.Description})
+	}
+	for _, exception := range result.Exceptions {
+		response.Exceptions = append(response.Exceptions, hexagate.Exception{Source: exception.Source, Message: exception.Message})
+	}
+
+	trace := make(map[string]any, len(result.Trace))
+	for name, value := range result.Trace {
+		trace[name] = interp.ToJSON(value)
+	}
+	response.Trace = trace
+	return response
+}
@@ -0,0 +1,146 @@
+package hexagatetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+func TestServerUsesEvaluator(t *testing.T) {
+	// We expect the fake to decode the validate request and answer with the evaluator's response
+	server := NewServer(func(request hexagate.ValidateRequest) hexagate.ValidateResponse {
+		return hexagate.ValidateResponse{
+			Count:  1,
+			Failed: []hexagate.Alert{{Description: "fired for " + request.Params["disputeGame"].(string)}},
+		}
+	})
+	defer server.Close()
+
+	body, err := json.Marshal(hexagate.ValidateRequest{
+		Gate:    "invariant { description: \"test\", condition: false };",
+		ChainId: 1,
+		Params:  map[string]any{"disputeGame": "0x0000000000000000000000000000000000000001"},
+		Mocks:   map[string]any{},
+	})
+	if err != nil {
+		t.Fatalf("Error marshalling request: %v", err)
+	}
+
+	resp, err := http.Post(server.ValidateEndpoint(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Error calling fake endpoint: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status code %d", resp.StatusCode)
+	}
+
+	var response hexagate.ValidateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if len(response.Failed) != 1 || response.Failed[0].Description != "fired for 0x0000000000000000000000000000000000000001" {
+		t.Errorf("Unexpected failed list: %v", response.Failed)
+	}
+	if response.Exceptions == nil {
+		t.Errorf("Expected an empty exceptions list rather than null")
+	}
+	if len(server.Requests()) != 1 {
+		t.Errorf("Expected the fake to record 1 request, got %d", len(server.Requests()))
+	}
+}
+
+func TestServerRejectsMalformedRequest(t *testing.T) {
+	// We expect the fake to reject requests that do not match the hexagate.ValidateRequest schema
+	server := NewServer(nil)
+	defer server.Close()
+
+	for name, body := range map[string]string{
+		"unknown field": `{"gate": "x", "chainId": 1}`,
+		"empty gate":    `{"gate": "", "chain_id": 1}`,
+		"invalid json":  `{"gate":`,
+	} {
+		resp, err := http.Post(server.ValidateEndpoint(), "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("Error calling fake endpoint: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < 400 {
+			t.Errorf("Expected %s request to be rejected, got status %d", name, resp.StatusCode)
+		}
+	}
+
+	if len(server.Requests()) != 0 {
+		t.Errorf("Expected no requests to be recorded, got %d", len(server.Requests()))
+	}
+}
+
+func TestServerNewClient(t *testing.T) {
+	// We expect a client created by the fake to evaluate the gate file locally
+	server := NewServer(nil)
+	defer server.Close()
+
+	client := server.NewClient(hexagate.WithChainID(hexagate.CHAIN_ID_BASE))
+	response, err := client.Validate(context.Background(), hexagate.ValidateRequest{
+		Gate:  "source claimCount: integer = 3;\ninvariant { description: \"too many claims\", condition: claimCount < 3 };",
+		Mocks: map[string]any{"claimCount": 3},
+		Trace: true,
+	})
+	if err != nil {
+		t.Fatalf("Error handling validate request: %v", err)
+	}
+
+	if len(response.Failed) != 1 || len(response.Exceptions) != 0 {
+		t.Errorf("Expected one alert and no exceptions, got %v and %v", response.Failed, response.Exceptions)
+	}
+	if response.Trace["claimCount"] == nil {
+		t.Errorf("Expected claimCount in the trace, got %v", response.Trace)
+	}
+	if requests := server.Requests(); len(requests) != 1 || requests[0].ChainId != hexagate.CHAIN_ID_BASE {
+		t.Errorf("Expected one request for chain %d, got %v", hexagate.CHAIN_ID_BASE, requests)
+	}
+}
+
+func TestServerRejectsMalformedMonitor(t *testing.T) {
+	// We expect the fake to reject monitor requests that do not match the hexagate.MonitorRequest schema, and
+	// routes it does not serve
+	server := NewServer(nil)
+	defer server.Close()
+
+	for name, tc := range map[string]struct {
+		method string
+		path   string
+		body   string
+	}{
+		"unknown field":  {http.MethodPost, hexagate.MONITORS_PATH, `{"name": "x", "gate": "", "chainId": 1}`},
+		"missing chain":  {http.MethodPost, hexagate.MONITORS_PATH, `{"name": "x", "gate": ""}`},
+		"invalid id":     {http.MethodGet, hexagate.MONITORS_PATH + "/x", ``},
+		"unknown action": {http.MethodPost, hexagate.MONITORS_PATH + "/1/pause", ``},
+		"invalid page":   {http.MethodGet, hexagate.MONITORS_PATH + "?page=0", ``},
+	} {
+		req, err := http.NewRequest(tc.method, server.URL+tc.path, bytes.NewBufferString(tc.body))
+		if err != nil {
+			t.Fatalf("Error creating request: %v", err)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Error calling fake endpoint: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < 400 {
+			t.Errorf("Expected %s request to be rejected, got status %d", name, resp.StatusCode)
+		}
+	}
+
+	if len(server.Monitors()) != 0 {
+		t.Errorf("Expected no monitors to be created, got %d", len(server.Monitors()))
+	}
+}
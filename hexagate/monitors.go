@@ -0,0 +1,175 @@
+package hexagate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	MONITORS_PATH = "/api/v1/monitoring/monitors"
+
+	// DEFAULT_PAGE_SIZE is the number of monitors per page the API returns when no page size is requested,
+	// and MAX_PAGE_SIZE the largest page size it accepts.
+	DEFAULT_PAGE_SIZE = 50
+	MAX_PAGE_SIZE     = 100
+)
+
+// MonitorRequest creates or replaces a gate monitor. A request without a chain ID uses the chain ID of the
+// client.
+type MonitorRequest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Gate        string         `json:"gate"`
+	ChainId     int            `json:"chain_id"`
+	Params      map[string]any `json:"params"`
+	// NotificationChannelIds are the channels, e.g. a Slack channel or webhook, alerts are sent to.
+	NotificationChannelIds []int `json:"notification_channel_ids"`
+}
+
+// Monitor is a gate monitor deployed to Hexagate.
+type Monitor struct {
+	Id                     int            `json:"id"`
+	Name                   string         `json:"name"`
+	Description            string         `json:"description,omitempty"`
+	Gate                   string         `json:"gate"`
+	ChainId                int            `json:"chain_id"`
+	Params                 map[string]any `json:"params"`
+	NotificationChannelIds []int          `json:"notification_channel_ids"`
+	Enabled                bool           `json:"enabled"`
+	CreatedAt              time.Time      `json:"created_at"`
+	UpdatedAt              time.Time      `json:"updated_at"`
+}
+
+// ListOptions selects a page of monitors. Pages are numbered from 1, and zero values use the first page and
+// DEFAULT_PAGE_SIZE.
+type ListOptions struct {
+	Page     int
+	PageSize int
+}
+
+// MonitorPage is a page of monitors, ordered by ID.
+type MonitorPage struct {
+	Monitors []Monitor `json:"monitors"`
+	Page     int       `json:"page"`
+	PageSize int       `json:"page_size"`
+	// Total is the number of monitors across all pages.
+	Total int `json:"total"`
+}
+
+// HasNext reports whether there are monitors after this page.
+func (p *MonitorPage) HasNext() bool {
+	return len(p.Monitors) > 0 && p.Page*p.PageSize < p.Total
+}
+
+// CreateMonitor deploys a new gate monitor. Monitors are enabled when they are created.
+func (c *Client) CreateMonitor(ctx context.Context, request MonitorRequest) (*Monitor, error) {
+	if request.ChainId == 0 {
+		request.ChainId = c.chainID
+	}
+	return c.monitor(ctx, http.MethodPost, MONITORS_PATH, request)
+}
+
+// GetMonitor returns the monitor with the given ID. A monitor that does not exist is an *APIError matching
+// ErrNotFound.
+func (c *Client) GetMonitor(ctx context.Context, id int) (*Monitor, error) {
+	return c.monitor(ctx, http.MethodGet, monitorPath(id), nil)
+}
+
+// UpdateMonitor replaces the name, gate file, params and notification channels of a monitor. It does not
+// change whether the monitor is enabled.
+func (c *Client) UpdateMonitor(ctx context.Context, id int, request MonitorRequest) (*Monitor, error) {
+	if request.ChainId == 0 {
+		request.ChainId = c.chainID
+	}
+	return c.monitor(ctx, http.MethodPut, monitorPath(id), request)
+}
+
+// EnableMonitor resumes alerting on a monitor.
+func (c *Client) EnableMonitor(ctx context.Context, id int) (*Monitor, error) {
+	return c.monitor(ctx, http.MethodPost, monitorPath(id)+"/enable", nil)
+}
+
+// DisableMonitor stops a monitor from alerting without deleting it.
+func (c *Client) DisableMonitor(ctx context.Context, id int) (*Monitor, error) {
+	return c.monitor(ctx, http.MethodPost, monitorPath(id)+"/disable", nil)
+}
+
+// DeleteMonitor deletes a monitor.
+func (c *Client) DeleteMonitor(ctx context.Context, id int) error {
+	_, err := c.do(ctx, http.MethodDelete, monitorPath(id), nil)
+	return err
+}
+
+// ListMonitors returns a single page of monitors.
+func (c *Client) ListMonitors(ctx context.Context, opts ListOptions) (*MonitorPage, error) {
+	query := url.Values{}
+	if opts.Page > 0 {
+		query.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(opts.PageSize))
+	}
+	path := MONITORS_PATH
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	body, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var page MonitorPage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, invalidResponse(http.MethodGet, path, body, err)
+	}
+	return &page, nil
+}
+
+// AllMonitors returns the monitors of every page, requesting MAX_PAGE_SIZE monitors at a time.
+func (c *Client) AllMonitors(ctx context.Context) ([]Monitor, error) {
+	var monitors []Monitor
+	opts := ListOptions{Page: 1, PageSize: MAX_PAGE_SIZE}
+	for {
+		page, err := c.ListMonitors(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, page.Monitors...)
+		if !page.HasNext() {
+			return monitors, nil
+		}
+		opts.Page++
+	}
+}
+
+// monitor sends a request answered with a single monitor.
+func (c *Client) monitor(ctx context.Context, method, path string, in any) (*Monitor, error) {
+	body, err := c.do(ctx, method, path, in)
+	if err != nil {
+		return nil, err
+	}
+	var monitor Monitor
+	if err := json.Unmarshal(body, &monitor); err != nil {
+		return nil, invalidResponse(method, path, body, err)
+	}
+	return &monitor, nil
+}
+
+func monitorPath(id int) string {
+	return fmt.Sprintf("%s/%d", MONITORS_PATH, id)
+}
+
+func invalidResponse(method, path string, body []byte, err error) *APIError {
+	return &APIError{
+		Method:     method,
+		Path:       path,
+		StatusCode: http.StatusOK,
+		Detail:     fmt.Sprintf("invalid response: %v", err),
+		Body:       truncate(body),
+	}
+}
@@ -0,0 +1,184 @@
+package hexagate_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/hexagate"
+	"github.com/base-org/fault-proof-monitors/hexagate/hexagatetest"
+)
+
+func TestMonitorLifecycle(t *testing.T) {
+	server := hexagatetest.NewServer(nil)
+	defer server.Close()
+	client := server.NewClient(hexagate.WithChainID(hexagate.CHAIN_ID_BASE))
+	ctx := context.Background()
+
+	// We expect a created monitor to carry the request, the client's chain ID and to start enabled
+	created, err := client.CreateMonitor(ctx, hexagate.MonitorRequest{
+		Name:                   "eth_deficit 0x01",
+		Gate:                   GATE,
+		Params:                 map[string]any{"disputeGame": "0x0000000000000000000000000000000000000001"},
+		NotificationChannelIds: []int{7, 9},
+	})
+	if err != nil {
+		t.Fatalf("Error creating monitor: %v", err)
+	}
+	if created.Id == 0 || !created.Enabled || created.ChainId != hexagate.CHAIN_ID_BASE || created.Gate != GATE {
+		t.Errorf("Unexpected created monitor %+v", created)
+	}
+	if len(created.NotificationChannelIds) != 2 || created.Params["disputeGame"] != "0x0000000000000000000000000000000000000001" {
+		t.Errorf("Expected the params and notification channels to be kept, got %+v", created)
+	}
+
+	// We expect get to return the same monitor
+	got, err := client.GetMonitor(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("Error getting monitor: %v", err)
+	}
+	if got.Name != created.Name || got.Id != created.Id {
+		t.Errorf("Expected %+v, got %+v", created, got)
+	}
+
+	// We expect update to replace the monitor without changing whether it is enabled
+	updated, err := client.UpdateMonitor(ctx, created.Id, hexagate.MonitorRequest{Name: "eth_deficit 0x02", Gate: GATE, ChainId: hexagate.CHAIN_ID_BASE_SEPOLIA})
+	if err != nil {
+		t.Fatalf("Error updating monitor: %v", err)
+	}
+	if updated.Name != "eth_deficit 0x02" || updated.ChainId != hexagate.CHAIN_ID_BASE_SEPOLIA || !updated.Enabled || len(updated.NotificationChannelIds) != 0 {
+		t.Errorf("Unexpected updated monitor %+v", updated)
+	}
+
+	// We expect disable and enable to toggle the monitor
+	disabled, err := client.DisableMonitor(ctx, created.Id)
+	if err != nil || disabled.Enabled {
+		t.Errorf("Expected the monitor to be disabled, got %+v, %v", disabled, err)
+	}
+	enabled, err := client.EnableMonitor(ctx, created.Id)
+	if err != nil || !enabled.Enabled {
+		t.Errorf("Expected the monitor to be enabled, got %+v, %v", enabled, err)
+	}
+
+	// We expect a deleted monitor to be gone
+	if err := client.DeleteMonitor(ctx, created.Id); err != nil {
+		t.Fatalf("Error deleting monitor: %v", err)
+	}
+	if _, err := client.GetMonitor(ctx, created.Id); !errors.Is(err, hexagate.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := client.DeleteMonitor(ctx, created.Id); !errors.Is(err, hexagate.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestMonitorPagination(t *testing.T) {
+	server := hexagatetest.NewServer(nil)
+	defer server.Close()
+	client := server.NewClient()
+	ctx := context.Background()
+
+	for i := 0; i < hexagate.MAX_PAGE_SIZE+5; i++ {
+		if _, err := client.CreateMonitor(ctx, hexagate.MonitorRequest{Name: fmt.Sprintf("monitor %d", i), Gate: GATE}); err != nil {
+			t.Fatalf("Error creating monitor %d: %v", i, err)
+		}
+	}
+
+	// We expect a page to hold the requested number of monitors and to know whether more follow
+	page, err := client.ListMonitors(ctx, hexagate.ListOptions{Page: 2, PageSize: 10})
+	if err != nil {
+		t.Fatalf("Error listing monitors: %v", err)
+	}
+	if len(page.Monitors) != 10 || page.Monitors[0].Name != "monitor 10" || page.Total != hexagate.MAX_PAGE_SIZE+5 || !page.HasNext() {
+		t.Errorf("Unexpected page %d of %d monitors, %d total", page.Page, len(page.Monitors), page.Total)
+	}
+	first, err := client.ListMonitors(ctx, hexagate.ListOptions{})
+	if err != nil || first.Page != 1 || len(first.Monitors) != hexagate.DEFAULT_PAGE_SIZE {
+		t.Errorf("Expected the first page of %d monitors by default, got %+v, %v", hexagate.DEFAULT_PAGE_SIZE, first, err)
+	}
+	last, err := client.ListMonitors(ctx, hexagate.ListOptions{Page: 11, PageSize: 10})
+	if err != nil || len(last.Monitors) != 5 || last.HasNext() {
+		t.Errorf("Expected a last page of 5 monitors, got %+v, %v", last, err)
+	}
+
+	// We expect AllMonitors to follow every page
+	all, err := client.AllMonitors(ctx)
+	if err != nil {
+		t.Fatalf("Error listing all monitors: %v", err)
+	}
+	if len(all) != hexagate.MAX_PAGE_SIZE+5 {
+		t.Errorf("Expected %d monitors, got %d", hexagate.MAX_PAGE_SIZE+5, len(all))
+	}
+	for i, m := range all {
+		if m.Name != fmt.Sprintf("monitor %d", i) {
+			t.Errorf("Expected monitor %d in order, got %s", i, m.Name)
+			break
+		}
+	}
+}
+
+func TestMonitorErrors(t *testing.T) {
+	server := hexagatetest.NewServer(nil)
+	defer server.Close()
+	client := server.NewClient()
+	ctx := context.Background()
+
+	if _, err := client.CreateMonitor(ctx, hexagate.MonitorRequest{Name: "duplicate", Gate: GATE}); err != nil {
+		t.Fatalf("Error creating monitor: %v", err)
+	}
+
+	// We expect rejected requests to match the sentinel error of their status code
+	for _, tc := range []struct {
+		name    string
+		request hexagate.MonitorRequest
+		want    error
+	}{
+		{"duplicate name", hexagate.MonitorRequest{Name: "duplicate", Gate: GATE}, hexagate.ErrConflict},
+		{"missing name", hexagate.MonitorRequest{Gate: GATE}, hexagate.ErrInvalid},
+		{"invalid gate", hexagate.MonitorRequest{Name: "invalid", Gate: "source x: integer = ;"}, hexagate.ErrInvalid},
+	} {
+		_, err := client.CreateMonitor(ctx, tc.request)
+		var apiErr *hexagate.APIError
+		if !errors.Is(err, tc.want) || !errors.As(err, &apiErr) {
+			t.Errorf("%s: expected an *APIError matching %v, got %v", tc.name, tc.want, err)
+		}
+		if errors.Is(err, hexagate.ErrNotFound) {
+			t.Errorf("%s: expected the error not to match ErrNotFound", tc.name)
+		}
+	}
+	if _, err := client.ListMonitors(ctx, hexagate.ListOptions{PageSize: hexagate.MAX_PAGE_SIZE + 1}); !errors.Is(err, hexagate.ErrInvalid) {
+		t.Errorf("Expected a page size above the maximum to be invalid, got %v", err)
+	}
+
+	// We expect authentication, rate limiting and malformed responses to surface as an *APIError
+	for _, tc := range []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"unauthorized", http.StatusForbidden, `{"detail": "invalid API key"}`, hexagate.ErrUnauthorized},
+		{"rate limited", http.StatusTooManyRequests, `{"detail": "slow down"}`, hexagate.ErrRateLimited},
+		{"invalid body", http.StatusOK, `<html>maintenance</html>`, nil},
+	} {
+		fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			_, _ = io.WriteString(w, tc.body)
+		}))
+		client := hexagate.NewClient(hexagate.WithBaseURL(fake.URL), hexagate.WithAPIKey(hexagate.StaticKey("")))
+		_, err := client.GetMonitor(ctx, 1)
+		fake.Close()
+
+		var apiErr *hexagate.APIError
+		if !errors.As(err, &apiErr) || apiErr.Path != hexagate.MONITORS_PATH+"/1" {
+			t.Errorf("%s: expected an *APIError for %s/1, got %v", tc.name, hexagate.MONITORS_PATH, err)
+		}
+		if tc.want != nil && !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected the error to match %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
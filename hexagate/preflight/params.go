@@ -0,0 +1,221 @@
+package preflight
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/check"
+)
+
+var (
+	// integers are 256-bit EVM words, either signed or unsigned
+	MIN_INTEGER = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 255))
+	MAX_INTEGER = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+)
+
+// checkParams requires every declared param to be set to a value of its declared type, and no other params.
+func checkParams(r *Result, file *gate.File, params map[string]any) {
+	declared := make(map[string]check.Type)
+	var names []string
+	for _, decl := range file.Decls {
+		d, ok := decl.(*gate.ParamDecl)
+		if !ok {
+			continue
+		}
+		typ, err := check.FromTypeExpr(d.Type)
+		if err != nil {
+			typ = check.Unknown
+		}
+		declared[d.Name.Name] = typ
+		names = append(names, d.Name.Name)
+	}
+
+	for _, name := range names {
+		value, ok := params[name]
+		if !ok {
+			r.errorf("param %s is declared as %s but not set", name, declared[name])
+			continue
+		}
+		if err := checkValue(declared[name], value); err != nil {
+			r.errorf("param %s: %v", name, err)
+		}
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := declared[key]; ok {
+			continue
+		}
+		if suggestion := Closest(key, names); suggestion != "" {
+			r.errorf("param %s is not declared, did you mean %s?", key, suggestion)
+		} else {
+			r.errorf("param %s is not declared", key)
+		}
+	}
+}
+
+// checkValue reports whether a Go value, as it will be encoded in the request, is a valid value of typ.
+func checkValue(typ check.Type, value any) error {
+	switch typ := typ.(type) {
+	case check.Basic:
+		switch typ {
+		case check.Address:
+			return checkAddress(value)
+		case check.Integer:
+			return checkInteger(value)
+		case check.Bytes:
+			s, ok := value.(string)
+			if !ok || !strings.HasPrefix(s, "0x") || len(s)%2 != 0 {
+				return fmt.Errorf("%v is not 0x-prefixed bytes", value)
+			}
+			if _, err := hex.DecodeString(s[2:]); err != nil {
+				return fmt.Errorf("%s is not 0x-prefixed bytes", s)
+			}
+		case check.Boolean:
+			if _, ok := value.(bool); !ok {
+				return fmt.Errorf("%v is not a boolean", value)
+			}
+		case check.String:
+			if _, ok := value.(string); !ok {
+				return fmt.Errorf("%v is not a string", value)
+			}
+		}
+		return nil
+
+	case *check.List:
+		elems, ok := list(value)
+		if !ok {
+			return fmt.Errorf("%v is not a list", value)
+		}
+		for i, elem := range elems {
+			if err := checkValue(typ.Elem, elem); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return nil
+
+	case *check.Tuple:
+		elems, ok := list(value)
+		if !ok || len(elems) != len(typ.Elems) {
+			return fmt.Errorf("%v is not a tuple of %d elements", value, len(typ.Elems))
+		}
+		for i, elem := range elems {
+			if err := checkValue(typ.Elems[i], elem); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// list returns the elements of a slice of any element type.
+func list(value any) ([]any, bool) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var elems []any
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	if err := dec.Decode(&elems); err != nil {
+		return nil, false
+	}
+	return elems, true
+}
+
+// checkAddress requires a 0x-prefixed 20-byte hex address. Mixed-case addresses must carry a valid EIP-55
+// checksum, while all lower or upper case addresses are accepted without one.
+func checkAddress(value any) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("%v is not an address", value)
+	}
+	if !strings.HasPrefix(s, "0x") {
+		return fmt.Errorf("address %s must start with 0x", s)
+	}
+	digits := s[2:]
+	if len(digits) != 40 {
+		return fmt.Errorf("address %s must be 20 bytes (40 hex digits), got %d hex digits", s, len(digits))
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return fmt.Errorf("address %s is not valid hex", s)
+	}
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return nil
+	}
+	if checksummed := Checksum(s); checksummed != s {
+		return fmt.Errorf("address %s has an invalid EIP-55 checksum, expected %s", s, checksummed)
+	}
+	return nil
+}
+
+// Checksum returns the EIP-55 mixed-case form of a 0x-prefixed 20-byte hex address.
+func Checksum(address string) string {
+	digits := strings.ToLower(strings.TrimPrefix(address, "0x"))
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(digits))
+	sum := hash.Sum(nil)
+
+	out := []byte(digits)
+	for i, c := range out {
+		// a letter is upper case when the matching nibble of the hash is 8 or more
+		nibble := sum[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if c >= 'a' && c <= 'f' && nibble&0xf >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+// checkInteger requires an integral number that fits in a 256-bit word.
+func checkInteger(value any) error {
+	var n *big.Int
+	switch v := value.(type) {
+	case int:
+		n = big.NewInt(int64(v))
+	case int32:
+		n = big.NewInt(int64(v))
+	case int64:
+		n = big.NewInt(v)
+	case uint:
+		n = new(big.Int).SetUint64(uint64(v))
+	case uint32:
+		n = new(big.Int).SetUint64(uint64(v))
+	case uint64:
+		n = new(big.Int).SetUint64(v)
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("%v is not an integer", v)
+		}
+		n, _ = new(big.Float).SetFloat64(v).Int(nil)
+	case json.Number:
+		var ok bool
+		if n, ok = new(big.Int).SetString(string(v), 10); !ok {
+			return fmt.Errorf("%s is not an integer", v)
+		}
+	case *big.Int:
+		n = v
+	default:
+		return fmt.Errorf("%v is not an integer", value)
+	}
+	if n.Cmp(MIN_INTEGER) < 0 || n.Cmp(MAX_INTEGER) > 0 {
+		return fmt.Errorf("integer %s does not fit in 256 bits", n)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Package preflight checks a validate request against the monitor it runs before the request is sent to
+// Hexagate, so that a typo in a test's params or mocks fails the test instead of silently changing what it
+// tests.
+package preflight
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/check"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+// Result holds the problems found in a validate request. Errors make the request meaningless and should fail
+// the test, warnings point at requests that probably do not test what they intend to.
+type Result struct {
+	Monitor  string
+	Errors   []string
+	Warnings []string
+}
+
+func (r *Result) errorf(format string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *Result) warnf(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Err returns the errors as a single error naming the monitor, or nil if there are none.
+func (r *Result) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	errs := make([]error, len(r.Errors))
+	for i, msg := range r.Errors {
+		errs[i] = fmt.Errorf("%s: %s", r.Monitor, msg)
+	}
+	return errors.Join(errs...)
+}
+
+// Check checks the request for the named monitor file against its parsed gate file.
+func Check(monitor string, file *gate.File, request hexagate.ValidateRequest) *Result {
+	r := &Result{Monitor: monitor}
+	checkParams(r, file, request.Params)
+	checkMocks(r, file, request.Mocks)
+	return r
+}
+
+// checkMocks rejects mocks that do not name a declared source, and warns about sources that read the chain
+// but are not mocked, as those are evaluated against whatever the chain returns at validation time.
+func checkMocks(r *Result, file *gate.File, mocks map[string]any) {
+	sources := make(map[string]*gate.SourceDecl)
+	params := make(map[string]bool)
+	var names []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *gate.SourceDecl:
+			sources[d.Name.Name] = d
+			names = append(names, d.Name.Name)
+		case *gate.ParamDecl:
+			params[d.Name.Name] = true
+		}
+	}
+
+	keys := make([]string, 0, len(mocks))
+	for key := range mocks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := sources[key]; ok {
+			continue
+		}
+		switch suggestion := Closest(key, names); {
+		case params[key]:
+			r.errorf("mock %s is a param, not a source; set it in params instead", key)
+		case suggestion != "":
+			r.errorf("mock %s is not a declared source, did you mean %s?", key, suggestion)
+		default:
+			r.errorf("mock %s is not a declared source", key)
+		}
+	}
+
+	for _, name := range names {
+		if _, ok := mocks[name]; ok {
+			continue
+		}
+		if builtin := chainRead(sources[name].Value); builtin != "" {
+			r.warnf("source %s reads the chain with %s but is not mocked", name, builtin)
+		}
+	}
+}
+
+// chainRead returns the name of the first chain read builtin called in x, or "" if there is none.
+func chainRead(x gate.Expr) string {
+	var builtin string
+	gate.Inspect(x, func(node gate.Node) bool {
+		if call, ok := node.(*gate.StructCallExpr); ok && builtin == "" && check.IsChainRead(call.Fun.Name) {
+			builtin = call.Fun.Name
+		}
+		return builtin == ""
+	})
+	return builtin
+}
+
+// Closest returns the candidate closest to name by edit distance, or "" if none is close enough to be a
+// likely typo. Differences in case alone always count as close.
+func Closest(name string, candidates []string) string {
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		if strings.EqualFold(name, candidate) {
+			return candidate
+		}
+		d := levenshtein(name, candidate)
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if bestDistance < 0 || bestDistance > max(2, len(name)/3) {
+		return ""
+	}
+	return best
+}
+
+// levenshtein returns the number of single character insertions, deletions and substitutions needed to turn
+// a into b.
+func levenshtein(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	prev := make([]int, len(y)+1)
+	curr := make([]int, len(y)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		curr[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(y)]
+}
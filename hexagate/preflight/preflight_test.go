@@ -0,0 +1,187 @@
+package preflight
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/gate/check"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+const MONITOR = `use BlockTimestamp, Call, Events, Len from hexagate;
+
+param disputeGame: address;
+
+source claimCount: integer = Call {
+    contract: disputeGame,
+    signature: "function claimDataLen() public view returns (uint256)"
+};
+source claimResults: list<tuple<integer, address>> = Events {
+    contract: disputeGame,
+    signature: "event Move(uint256 indexed parentIndex, bytes32 indexed claim, address indexed claimant)"
+};
+source moveCount: integer = Len { sequence: claimResults };
+source now: integer = BlockTimestamp {};
+
+invariant {
+    description: "too many moves",
+    condition: moveCount < claimCount
+};
+`
+
+func parse(t *testing.T) *gate.File {
+	file, err := gate.ParseFile("example.gate", []byte(MONITOR))
+	if err != nil {
+		t.Fatalf("Error parsing monitor: %v", err)
+	}
+	return file
+}
+
+func TestCheckMocks(t *testing.T) {
+	// We expect mock keys that are not sources to be rejected with the closest source name
+	file := parse(t)
+
+	result := Check("example.gate", file, hexagate.ValidateRequest{
+		Params: map[string]any{"disputeGame": "0x0000000000000000000000000000000000000001"},
+		Mocks: map[string]any{
+			"claimResult": []any{},
+			"claimcount":  3,
+			"disputeGame": "0x0000000000000000000000000000000000000001",
+			"bondTotal":   1,
+		},
+	})
+
+	expected := []string{
+		"mock bondTotal is not a declared source",
+		"mock claimResult is not a declared source, did you mean claimResults?",
+		"mock claimcount is not a declared source, did you mean claimCount?",
+		"mock disputeGame is a param, not a source; set it in params instead",
+	}
+	if !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("Unexpected errors:\n got %q\nwant %q", result.Errors, expected)
+	}
+
+	err := result.Err()
+	if err == nil || !strings.HasPrefix(err.Error(), "example.gate: mock bondTotal") || strings.Count(err.Error(), "example.gate:") != 4 {
+		t.Errorf("Expected every error to name the monitor, got %v", err)
+	}
+}
+
+func TestCheckUnmockedChainReads(t *testing.T) {
+	// We expect a warning for every source that calls a chain read builtin but is not mocked, including the
+	// block, state and trace builtins
+	file := parse(t)
+
+	result := Check("example.gate", file, hexagate.ValidateRequest{
+		Params: map[string]any{"disputeGame": "0x0000000000000000000000000000000000000001"},
+		Mocks:  map[string]any{"claimCount": 3},
+	})
+	if result.Err() != nil {
+		t.Errorf("Expected no errors, got %v", result.Err())
+	}
+
+	// moveCount is computed from other sources and does not read the chain itself
+	expected := []string{
+		"source claimResults reads the chain with Events but is not mocked",
+		"source now reads the chain with BlockTimestamp but is not mocked",
+	}
+	if !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("Unexpected warnings:\n got %q\nwant %q", result.Warnings, expected)
+	}
+}
+
+func TestClosest(t *testing.T) {
+	// We expect suggestions for likely typos only
+	candidates := []string{"claimResults", "claimCount", "resolveEvents", "historicalMoveEvents"}
+	for name, expected := range map[string]string{
+		"claimResult":         "claimResults",
+		"claimCounts":         "claimCount",
+		"CLAIMCOUNT":          "claimCount",
+		"resolvedEvents":      "resolveEvents",
+		"historicalMoveEvent": "historicalMoveEvents",
+		"credits":             "",
+		"x":                   "",
+	} {
+		if got := Closest(name, candidates); got != expected {
+			t.Errorf("Closest(%q) = %q, want %q", name, got, expected)
+		}
+	}
+}
+
+func TestCheckParams(t *testing.T) {
+	// We expect every declared param to be required and params that are not declared to be rejected
+	file := parse(t)
+
+	result := Check("example.gate", file, hexagate.ValidateRequest{
+		Params: map[string]any{"disputegame": "0x0000000000000000000000000000000000000001", "honestChallenger": "0x0000000000000000000000000000000000000002"},
+		Mocks:  map[string]any{"claimCount": 3, "claimResults": []any{}},
+	})
+
+	expected := []string{
+		"param disputeGame is declared as address but not set",
+		"param disputegame is not declared, did you mean disputeGame?",
+		"param honestChallenger is not declared",
+	}
+	if !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("Unexpected errors:\n got %q\nwant %q", result.Errors, expected)
+	}
+}
+
+func TestCheckParamValues(t *testing.T) {
+	// We expect param values to be checked against the declared type of the param
+	for _, test := range []struct {
+		value    any
+		expected string
+	}{
+		{"0x0000000000000000000000000000000000000001", ""},
+		{"0x000000000000000000000000000000000000000", "address 0x000000000000000000000000000000000000000 must be 20 bytes (40 hex digits), got 39 hex digits"},
+		{"0000000000000000000000000000000000000001", "address 0000000000000000000000000000000000000001 must start with 0x"},
+		{"0x000000000000000000000000000000000000000g", "address 0x000000000000000000000000000000000000000g is not valid hex"},
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", ""},
+		{"0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", ""},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ""},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", "address 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD has an invalid EIP-55 checksum, expected 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{1, "1 is not an address"},
+	} {
+		err := checkValue(check.Address, test.value)
+		if (err == nil && test.expected != "") || (err != nil && err.Error() != test.expected) {
+			t.Errorf("checkValue(address, %v) = %v, want %q", test.value, err, test.expected)
+		}
+	}
+
+	max := "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+	for _, test := range []struct {
+		value    any
+		expected string
+	}{
+		{3600, ""},
+		{uint64(1 << 63), ""},
+		{float64(8453), ""},
+		{json.Number(max), ""},
+		{json.Number(max[:len(max)-1] + "6"), "integer " + max[:len(max)-1] + "6 does not fit in 256 bits"},
+		{1.5, "1.5 is not an integer"},
+		{"3600", "3600 is not an integer"},
+	} {
+		err := checkValue(check.Integer, test.value)
+		if (err == nil && test.expected != "") || (err != nil && err.Error() != test.expected) {
+			t.Errorf("checkValue(integer, %v) = %v, want %q", test.value, err, test.expected)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	// We expect the EIP-55 test vectors to round trip from lower case
+	for _, address := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		if got := Checksum(strings.ToLower(address)); got != address {
+			t.Errorf("Checksum(%s) = %s, want %s", strings.ToLower(address), got, address)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package hexagate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type ValidateResponse struct {
+	Count      int         `json:"count"`
+	Failed     []Alert     `json:"failed"`
+	Exceptions []Exception `json:"exceptions"`
+	// Trace maps every source to its evaluated value when the request asked for a trace.
+	Trace map[string]any `json:"trace"`
+}
+
+// Fired reports whether an invariant whose description contains the given text failed, matching the way the
+// monitor tests have always checked alerts.
+func (r *ValidateResponse) Fired(description string) bool {
+	for _, alert := range r.Failed {
+		if strings.Contains(alert.Description, description) {
+			return true
+		}
+	}
+	return false
+}
+
+// FiredExact reports whether an invariant with exactly the given description failed.
+func (r *ValidateResponse) FiredExact(description string) bool {
+	for _, alert := range r.Failed {
+		if alert.Description == description {
+			return true
+		}
+	}
+	return false
+}
+
+// Descriptions returns the descriptions of the failed invariants, in the order they were reported.
+func (r *ValidateResponse) Descriptions() []string {
+	descriptions := make([]string, len(r.Failed))
+	for i, alert := range r.Failed {
+		descriptions[i] = alert.Description
+	}
+	return descriptions
+}
+
+// Raised returns the exceptions raised while evaluating the named source.
+func (r *ValidateResponse) Raised(source string) []Exception {
+	var exceptions []Exception
+	for _, exception := range r.Exceptions {
+		if exception.Source == source {
+			exceptions = append(exceptions, exception)
+		}
+	}
+	return exceptions
+}
+
+// Alert is an invariant that failed during validation.
+//
+// Hexagate reports each failed invariant as a list holding its description, optionally followed by the
+// block it failed at and any values attached to the alert:
+//
+//	["Challenger lost one or more subgames", 19432211, "0x..."]
+//
+// The object form {"description": ..., "block": ..., "values": [...]} is accepted as well.
+type Alert struct {
+	Description string
+	// Block is the block the invariant failed at, or 0 if it was not reported.
+	Block  uint64
+	Values []any
+}
+
+func (a Alert) String() string {
+	if a.Block == 0 {
+		return a.Description
+	}
+	return fmt.Sprintf("%s (block %d)", a.Description, a.Block)
+}
+
+func (a Alert) MarshalJSON() ([]byte, error) {
+	fields := []any{a.Description}
+	if a.Block != 0 || len(a.Values) > 0 {
+		fields = append(fields, a.Block)
+		fields = append(fields, a.Values...)
+	}
+	return json.Marshal(fields)
+}
+
+func (a *Alert) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var obj struct {
+			Description string `json:"description"`
+			Block       uint64 `json:"block"`
+			Values      []any  `json:"values"`
+		}
+		if err := decode(data, &obj); err != nil {
+			return fmt.Errorf("invalid alert %s: %w", data, err)
+		}
+		*a = Alert{Description: obj.Description, Block: obj.Block, Values: obj.Values}
+		return nil
+	}
+
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("invalid alert %s: expected a list or an object", data)
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("invalid alert %s: missing description", data)
+	}
+	*a = Alert{}
+	if err := json.Unmarshal(fields[0], &a.Description); err != nil {
+		return fmt.Errorf("invalid alert %s: description must be a string", data)
+	}
+	rest := fields[1:]
+	if len(rest) > 0 {
+		// the block is only present as a number, anything else is an attached value
+		if err := json.Unmarshal(rest[0], &a.Block); err == nil {
+			rest = rest[1:]
+		}
+	}
+	for _, field := range rest {
+		var value any
+		if err := decode(field, &value); err != nil {
+			return fmt.Errorf("invalid alert %s: %w", data, err)
+		}
+		a.Values = append(a.Values, value)
+	}
+	return nil
+}
+
+// Exception is an error raised while evaluating a source or an invariant. Hexagate reports it as a
+// [source, message] list, or as a {"source": ..., "message": ...} object. Exceptions that are not attributed
+// to a source, such as parse errors, have an empty Source.
+type Exception struct {
+	Source  string
+	Message string
+}
+
+func (e Exception) String() string {
+	if e.Source == "" {
+		return e.Message
+	}
+	return e.Source + ": " + e.Message
+}
+
+func (e Exception) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]string{e.Source, e.Message})
+}
+
+func (e *Exception) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) > 0 && data[0] == '{':
+		var obj struct {
+			Source  string `json:"source"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return fmt.Errorf("invalid exception %s: %w", data, err)
+		}
+		*e = Exception{Source: obj.Source, Message: obj.Message}
+
+	case len(data) > 0 && data[0] == '"':
+		*e = Exception{}
+		return json.Unmarshal(data, &e.Message)
+
+	default:
+		var fields []any
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return fmt.Errorf("invalid exception %s: expected a list or an object", data)
+		}
+		*e = Exception{}
+		switch len(fields) {
+		case 0:
+			return fmt.Errorf("invalid exception %s: missing message", data)
+		case 1:
+			e.Message = text(fields[0])
+		default:
+			e.Source = text(fields[0])
+			var messages []string
+			for _, field := range fields[1:] {
+				messages = append(messages, text(field))
+			}
+			e.Message = strings.Join(messages, ": ")
+		}
+	}
+	return nil
+}
+
+// decode unmarshals JSON keeping numbers as json.Number, so that large integers such as bonds and claims are
+// not rounded to a float64.
+func decode(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func text(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
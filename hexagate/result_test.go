@@ -0,0 +1,99 @@
+package hexagate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeValidateResponse(t *testing.T) {
+	// We expect alerts and exceptions to decode from both the list and the object form
+	body := `{
+		"count": 1,
+		"failed": [
+			["Challenger lost one or more subgames"],
+			["Credit and bond discrepancy", 19432211, "0xc96775081bcA132B0E7cbECDd0B58d9Ec07Fdaa4", 1000000000000000000000],
+			["Duplicate dispute game", "0x01"],
+			{"description": "ETH deficit", "block": 12, "values": [true]}
+		],
+		"exceptions": [
+			["claimData", "execution reverted"],
+			{"source": "credits", "message": "division by zero"},
+			"unexpected token"
+		],
+		"trace": {"claimCount": 3}
+	}`
+
+	var response ValidateResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	expectedAlerts := []Alert{
+		{Description: "Challenger lost one or more subgames"},
+		{Description: "Credit and bond discrepancy", Block: 19432211, Values: []any{"0xc96775081bcA132B0E7cbECDd0B58d9Ec07Fdaa4", json.Number("1000000000000000000000")}},
+		{Description: "Duplicate dispute game", Values: []any{"0x01"}},
+		{Description: "ETH deficit", Block: 12, Values: []any{true}},
+	}
+	if !reflect.DeepEqual(response.Failed, expectedAlerts) {
+		t.Errorf("Unexpected alerts:\n got %#v\nwant %#v", response.Failed, expectedAlerts)
+	}
+
+	expectedExceptions := []Exception{
+		{Source: "claimData", Message: "execution reverted"},
+		{Source: "credits", Message: "division by zero"},
+		{Message: "unexpected token"},
+	}
+	if !reflect.DeepEqual(response.Exceptions, expectedExceptions) {
+		t.Errorf("Unexpected exceptions:\n got %#v\nwant %#v", response.Exceptions, expectedExceptions)
+	}
+
+	if !response.Fired("Challenger lost one or more subgames") {
+		t.Errorf("Expected the subgame alert to have fired")
+	}
+	if !response.Fired("Challenger lost") {
+		t.Errorf("Expected Fired to match part of a description")
+	}
+	if response.FiredExact("Challenger lost") || !response.FiredExact("Challenger lost one or more subgames") {
+		t.Errorf("Expected FiredExact to match whole descriptions only")
+	}
+	if raised := response.Raised("claimData"); len(raised) != 1 || raised[0].String() != "claimData: execution reverted" {
+		t.Errorf("Unexpected exceptions for claimData: %v", raised)
+	}
+}
+
+func TestDecodeInvalidAlert(t *testing.T) {
+	// We expect malformed alerts to fail with an error naming the alert instead of panicking
+	for _, body := range []string{`[]`, `[1]`, `42`} {
+		var alert Alert
+		if err := json.Unmarshal([]byte(body), &alert); err == nil {
+			t.Errorf("Expected alert %s to be rejected, got %+v", body, alert)
+		}
+	}
+}
+
+func TestAlertRoundTrip(t *testing.T) {
+	// We expect alerts and exceptions to encode in the list form Hexagate uses
+	for _, alert := range []Alert{
+		{Description: "only a description"},
+		{Description: "with a block", Block: 7},
+		{Description: "with values", Block: 7, Values: []any{"0x01", json.Number("3")}},
+	} {
+		data, err := json.Marshal(alert)
+		if err != nil {
+			t.Fatalf("Error encoding alert: %v", err)
+		}
+		var decoded Alert
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Error decoding alert %s: %v", data, err)
+		}
+		if !reflect.DeepEqual(decoded, alert) {
+			t.Errorf("Alert %s decoded as %#v, want %#v", data, decoded, alert)
+		}
+	}
+
+	data, err := json.Marshal(Exception{Source: "claimData", Message: "execution reverted"})
+	if err != nil || string(data) != `["claimData","execution reverted"]` {
+		t.Errorf("Unexpected exception encoding %s: %v", data, err)
+	}
+}
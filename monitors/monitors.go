@@ -0,0 +1,229 @@
+// Package monitors embeds the gate monitors of this repository and describes how each of them is deployed.
+// Tests and tools look monitors up in the registry by name rather than reading them from the filesystem, so
+// they work from any directory and always agree on the set of monitors.
+package monitors
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/base-org/fault-proof-monitors/gate"
+)
+
+// Workflow is how a monitor is deployed, as described in the Deployment Workflows section of the README.
+type Workflow string
+
+const (
+	// SINGLE_INSTANCE monitors are deployed once as a single invariant monitor
+	SINGLE_INSTANCE Workflow = "Single Instance"
+
+	// PER_DISPUTE_GAME monitors are deployed to every dispute game following its DisputeGameCreated event
+	PER_DISPUTE_GAME Workflow = "Per DisputeGame"
+
+	// SPECIFIC_DISPUTE_GAME monitors are deployed to a dispute game following an alert from a parent monitor
+	SPECIFIC_DISPUTE_GAME Workflow = "Specific DisputeGame"
+)
+
+// ErrUnknownMonitor is returned by Get for names that are not in the registry.
+var ErrUnknownMonitor = errors.New("unknown monitor")
+
+//go:embed *.gate
+var files embed.FS
+
+// LintConfig is the gatelint config of the monitors, listing the findings expected in them.
+//
+//go:embed gatelint.yaml
+var LintConfig []byte
+
+// Param is a param declared by a monitor, e.g. disputeGame of type address.
+type Param struct {
+	Name string
+	Type string
+}
+
+// Monitor is a gate monitor of this repository.
+type Monitor struct {
+	Name     string   // file name without extension, e.g. eth_deficit
+	File     string   // file name, e.g. eth_deficit.gate
+	Source   string   // gate source text
+	Params   []Param  // declared params in declaration order
+	Workflow Workflow // how the monitor is deployed
+	Doc      string   // path of the documentation relative to the repository root
+}
+
+// Parse parses the source of the monitor.
+func (m *Monitor) Parse() (*gate.File, error) {
+	return gate.ParseFile(m.File, []byte(m.Source))
+}
+
+// Hash returns the hex encoded SHA-256 hash of the source, which identifies the version of the monitor a
+// deployment runs.
+func (m *Monitor) Hash() string {
+	return Hash(m.Source)
+}
+
+// Hash returns the hex encoded SHA-256 hash of a gate source.
+func Hash(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// Param returns the declared param with the given name and whether it exists.
+func (m *Monitor) Param(name string) (Param, bool) {
+	for _, p := range m.Params {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+// deployments holds the workflow and documentation of every monitor, keyed by name. A gate file without an
+// entry fails to load, so new monitors have to be documented here as well as in the README.
+var deployments = map[string]struct {
+	workflow Workflow
+	doc      string
+}{
+	"challenged_proposal":          {PER_DISPUTE_GAME, "docs/challenged_proposal.md"},
+	"challenger_loses":             {PER_DISPUTE_GAME, "docs/challenger_loses.md"},
+	"credit_and_bond_discrepancy":  {PER_DISPUTE_GAME, "docs/credit_and_bond_discrepancy.md"},
+	"duplicate_dispute_game":       {SINGLE_INSTANCE, "docs/duplicate_dispute_game.md"},
+	"eth_deficit":                  {PER_DISPUTE_GAME, "docs/eth_deficit.md"},
+	"eth_withdrawn_early":          {PER_DISPUTE_GAME, "docs/eth_withdrawn_early.md"},
+	"fault_proof_detection_child":  {SPECIFIC_DISPUTE_GAME, "docs/fault_proof_detection_parent_and_child.md#fault-proof-detection-child"},
+	"fault_proof_detection_parent": {SINGLE_INSTANCE, "docs/fault_proof_detection_parent_and_child.md#fault-proof-detection-parent"},
+	"incorrect_bond_balance":       {PER_DISPUTE_GAME, "docs/incorrect_bond_balance.md"},
+	"unresolvable_dispute_game":    {PER_DISPUTE_GAME, "docs/unresolvable_dispute_game.md"},
+}
+
+var (
+	loadOnce sync.Once
+	registry []*Monitor
+	loadErr  error
+)
+
+// loaded returns every embedded monitor sorted by name. The monitors are loaded on first use, and a gate file
+// that fails to parse or has no deployment workflow is returned as an error by every lookup.
+func loaded() ([]*Monitor, error) {
+	loadOnce.Do(func() {
+		registry, loadErr = load(files)
+	})
+	return registry, loadErr
+}
+
+func load(fsys fs.FS) ([]*Monitor, error) {
+	names, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var monitors []*Monitor
+	for _, entry := range names {
+		src, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		m := &Monitor{Name: strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())), File: entry.Name(), Source: string(src)}
+		deployment, ok := deployments[m.Name]
+		if !ok {
+			return nil, fmt.Errorf("monitor %s has no deployment workflow", m.Name)
+		}
+		m.Workflow, m.Doc = deployment.workflow, deployment.doc
+
+		file, err := m.Parse()
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			if d, ok := decl.(*gate.ParamDecl); ok {
+				m.Params = append(m.Params, Param{Name: d.Name.Name, Type: gate.TypeString(d.Type)})
+			}
+		}
+		monitors = append(monitors, m)
+	}
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].Name < monitors[j].Name })
+	return monitors, nil
+}
+
+// All returns every monitor sorted by name.
+func All() ([]*Monitor, error) {
+	registry, err := loaded()
+	if err != nil {
+		return nil, err
+	}
+	return append([]*Monitor{}, registry...), nil
+}
+
+// ByWorkflow returns the monitors deployed with the given workflow, sorted by name.
+func ByWorkflow(workflow Workflow) ([]*Monitor, error) {
+	registry, err := loaded()
+	if err != nil {
+		return nil, err
+	}
+	var monitors []*Monitor
+	for _, m := range registry {
+		if m.Workflow == workflow {
+			monitors = append(monitors, m)
+		}
+	}
+	return monitors, nil
+}
+
+// Get returns the monitor with the given name, e.g. eth_deficit or eth_deficit.gate.
+func Get(name string) (*Monitor, error) {
+	registry, err := loaded()
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSuffix(name, ".gate")
+	for _, m := range registry {
+		if m.Name == name {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownMonitor, name)
+}
+
+// Source is the contents of a gate file and the path it is reported under.
+type Source struct {
+	Path string
+	Src  []byte
+}
+
+// Sources reads the gate files at paths, or returns every embedded monitor under its path from the repository
+// root, e.g. monitors/eth_deficit.gate, if no paths are given. The embedded monitors are not parsed, so that
+// the tools report a gate file that does not parse like any other diagnostic.
+func Sources(paths ...string) ([]Source, error) {
+	var sources []Source
+	if len(paths) > 0 {
+		for _, p := range paths {
+			src, err := os.ReadFile(p)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, Source{Path: p, Src: src})
+		}
+		return sources, nil
+	}
+
+	names, err := files.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range names {
+		src, err := files.ReadFile(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, Source{Path: path.Join("monitors", entry.Name()), Src: src})
+	}
+	return sources, nil
+}
@@ -0,0 +1,205 @@
+package monitors
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func all(t *testing.T) []*Monitor {
+	t.Helper()
+	monitors, err := All()
+	if err != nil {
+		t.Fatalf("Error loading monitors: %v", err)
+	}
+	return monitors
+}
+
+func byWorkflow(t *testing.T, workflow Workflow) []*Monitor {
+	t.Helper()
+	monitors, err := ByWorkflow(workflow)
+	if err != nil {
+		t.Fatalf("Error loading monitors: %v", err)
+	}
+	return monitors
+}
+
+func TestRegistry(t *testing.T) {
+	paths, err := filepath.Glob("*.gate")
+	if err != nil {
+		t.Fatalf("Error listing monitors: %v", err)
+	}
+
+	// We expect every gate file in the directory to be registered, with its source embedded unchanged.
+	all := all(t)
+	if len(all) != len(paths) {
+		t.Fatalf("Expected %d monitors, got %d", len(paths), len(all))
+	}
+	for _, path := range paths {
+		m, err := Get(path)
+		if err != nil {
+			t.Fatalf("Error getting monitor %s: %v", path, err)
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Error reading file %s: %v", path, err)
+		}
+		if m.File != path || m.Source != string(src) {
+			t.Errorf("Expected monitor %s to embed %s", m.Name, path)
+		}
+	}
+
+	// We expect the names to be sorted and to resolve with or without the extension.
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Name >= all[i].Name {
+			t.Errorf("Expected %s to sort before %s", all[i-1].Name, all[i].Name)
+		}
+	}
+	m, err := Get("eth_deficit")
+	if err != nil || m.File != "eth_deficit.gate" {
+		t.Errorf("Expected eth_deficit to resolve to eth_deficit.gate, got %v, %v", m, err)
+	}
+	if _, err := Get("eth_surplus"); !errors.Is(err, ErrUnknownMonitor) {
+		t.Errorf("Expected ErrUnknownMonitor, got %v", err)
+	}
+}
+
+func TestParams(t *testing.T) {
+	m, err := Get("unresolvable_dispute_game")
+	if err != nil {
+		t.Fatalf("Error getting monitor: %v", err)
+	}
+
+	// We expect the params in declaration order with their types.
+	expected := []Param{{"disputeGame", "address"}, {"extraTimeInSeconds", "integer"}}
+	if len(m.Params) != len(expected) {
+		t.Fatalf("Expected params %v, got %v", expected, m.Params)
+	}
+	for i := range expected {
+		if m.Params[i] != expected[i] {
+			t.Errorf("Expected param %v, got %v", expected[i], m.Params[i])
+		}
+	}
+	if p, ok := m.Param("extraTimeInSeconds"); !ok || p.Type != "integer" {
+		t.Errorf("Expected extraTimeInSeconds to be an integer param, got %v", p)
+	}
+	if _, ok := m.Param("honestChallenger"); ok {
+		t.Errorf("Expected honestChallenger not to be declared")
+	}
+}
+
+func TestHash(t *testing.T) {
+	// We expect the hash to identify the source, and every monitor to hash differently
+	seen := make(map[string]string)
+	for _, m := range all(t) {
+		if m.Hash() != Hash(m.Source) || len(m.Hash()) != 64 {
+			t.Errorf("Expected %s to hash its source, got %s", m.Name, m.Hash())
+		}
+		if other, ok := seen[m.Hash()]; ok {
+			t.Errorf("Expected %s and %s to hash differently", m.Name, other)
+		}
+		seen[m.Hash()] = m.Name
+	}
+	if Hash("") != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("Expected the SHA-256 hash of the empty source, got %s", Hash(""))
+	}
+}
+
+func TestWorkflows(t *testing.T) {
+	readme, err := os.ReadFile("../README.md")
+	if err != nil {
+		t.Fatalf("Error reading file README.md: %v", err)
+	}
+
+	// We expect the workflow of every monitor to match its row in the README monitor table.
+	row := regexp.MustCompile(`(?m)^\|\s*\[?(\w+)\]?.*\|\s*([\w ]+?)\s*\|\s*$`)
+	documented := make(map[string]string)
+	for _, match := range row.FindAllStringSubmatch(string(readme), -1) {
+		documented[match[1]] = match[2]
+	}
+	for _, m := range all(t) {
+		if workflow, ok := documented[m.Name]; !ok {
+			t.Errorf("Expected %s in the README monitor table", m.Name)
+		} else if string(m.Workflow) != workflow {
+			t.Errorf("Expected %s to be deployed as %s, got %s", m.Name, workflow, m.Workflow)
+		}
+	}
+
+	// We expect every monitor deployed to a dispute game to declare a disputeGame param.
+	for _, workflow := range []Workflow{PER_DISPUTE_GAME, SPECIFIC_DISPUTE_GAME} {
+		for _, m := range byWorkflow(t, workflow) {
+			if p, ok := m.Param("disputeGame"); !ok || p.Type != "address" {
+				t.Errorf("Expected %s monitor %s to declare param disputeGame: address", workflow, m.Name)
+			}
+		}
+	}
+}
+
+func TestDocs(t *testing.T) {
+	readme, err := os.ReadFile("../README.md")
+	if err != nil {
+		t.Fatalf("Error reading file README.md: %v", err)
+	}
+
+	// We expect every doc path to be linked from the README, to name an existing file and, for shared docs,
+	// an existing heading.
+	for _, m := range all(t) {
+		if !strings.Contains(string(readme), "(./"+m.Doc+")") {
+			t.Errorf("Expected the README to link %s for %s", m.Doc, m.Name)
+		}
+		file, anchor, _ := strings.Cut(m.Doc, "#")
+		data, err := os.ReadFile(filepath.Join("..", file))
+		if err != nil {
+			t.Errorf("Error reading file %s: %v", file, err)
+			continue
+		}
+		if anchor == "" {
+			continue
+		}
+		found := false
+		for _, line := range strings.Split(string(data), "\n") {
+			if heading, ok := strings.CutPrefix(line, "# "); ok && strings.ReplaceAll(strings.ToLower(heading), " ", "-") == anchor {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected %s to have a heading for #%s", file, anchor)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	// We expect a gate file that does not parse, or that has no deployment workflow, to fail loading
+	for name, fsys := range map[string]fstest.MapFS{
+		"eth_deficit.gate": {"eth_deficit.gate": {Data: []byte("source x: integer = ;\n")}},
+		"eth_surplus.gate": {"eth_surplus.gate": {Data: []byte("source x: integer = 1;\n")}},
+	} {
+		if _, err := load(fsys); err == nil || !strings.Contains(err.Error(), strings.TrimSuffix(name, ".gate")) {
+			t.Errorf("Expected loading %s to fail naming it, got %v", name, err)
+		}
+	}
+}
+
+func TestSources(t *testing.T) {
+	// We expect the embedded monitors under their repository paths when no paths are given
+	sources, err := Sources()
+	if err != nil {
+		t.Fatalf("Error reading sources: %v", err)
+	}
+	if len(sources) != len(all(t)) || sources[0].Path != "monitors/challenged_proposal.gate" {
+		t.Errorf("Expected every monitor under monitors/, got %d sources starting with %s", len(sources), sources[0].Path)
+	}
+
+	// We expect the given files otherwise
+	sources, err = Sources("eth_deficit.gate")
+	if err != nil || len(sources) != 1 || sources[0].Path != "eth_deficit.gate" {
+		t.Errorf("Expected eth_deficit.gate, got %v, %v", sources, err)
+	}
+	if _, err := Sources("eth_surplus.gate"); err == nil {
+		t.Errorf("Expected a missing file to fail")
+	}
+}
@@ -0,0 +1,308 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+const (
+	CASES_DIR = "cases"
+)
+
+// Case is a monitor test case loaded from cases/<monitor>/<name>.yaml, where <monitor>.gate is the monitor
+// under test:
+//
+//	description: We expect an alert to be fired when the challenger loses a subgame
+//	params:
+//	  disputeGame: "0x0000000000000000000000000000000000000000"
+//	mocks:
+//	  claimCount: 2
+//	  claimResults:
+//	    - [0, 0x0000000000000000000000000000000000000000, 0x49277EE36A024120Ee218127354c4a3591dc90A9, 1, 0x00, 1, 123456]
+//	fired:
+//	  - Challenger lost one or more subgames
+//
+// Fired lists alerts the case expects and NotFired alerts it expects not to fire. Both match any alert whose
+// description contains the text, like Fired of hexagate.ValidateResponse, and alerts in neither list are not
+// checked. Exceptions lists substrings of the exceptions the case expects, and none are allowed otherwise.
+// Trace optionally pins the evaluated value of sources. A case must set at least one of the four.
+type Case struct {
+	Monitor string `yaml:"-"`
+	Name    string `yaml:"-"`
+	Path    string `yaml:"-"`
+
+	Description string         `yaml:"description"`
+	ChainId     int            `yaml:"chain_id"`
+	Skip        string         `yaml:"skip"`
+	Params      map[string]any `yaml:"-"`
+	Mocks       map[string]any `yaml:"-"`
+	Fired       []string       `yaml:"fired"`
+	NotFired    []string       `yaml:"not_fired"`
+	Exceptions  []string       `yaml:"exceptions"`
+	Trace       map[string]any `yaml:"-"`
+}
+
+// caseFile mirrors Case, keeping the values as YAML nodes so that they can be converted to the JSON values the
+// validate endpoint expects.
+type caseFile struct {
+	Case   `yaml:",inline"`
+	Params yaml.Node `yaml:"params"`
+	Mocks  yaml.Node `yaml:"mocks"`
+	Trace  yaml.Node `yaml:"trace"`
+}
+
+// LoadCases loads every test case in dir, grouped by monitor and sorted by name.
+func LoadCases(dir string) ([]*Case, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var cases []*Case
+	for _, path := range paths {
+		c, err := LoadCase(path)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// LoadCase loads a single test case. The monitor is named after the directory the case is in.
+func LoadCase(path string) (*Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file caseFile
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	c := file.Case
+	c.Path = path
+	c.Monitor = filepath.Base(filepath.Dir(path))
+	c.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	for _, field := range []struct {
+		name string
+		node *yaml.Node
+		out  *map[string]any
+	}{
+		{"params", &file.Params, &c.Params},
+		{"mocks", &file.Mocks, &c.Mocks},
+		{"trace", &file.Trace, &c.Trace},
+	} {
+		value, err := yamlValue(field.node)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, field.name, err)
+		}
+		switch value := value.(type) {
+		case nil:
+			*field.out = map[string]any{}
+		case map[string]any:
+			*field.out = value
+		default:
+			return nil, fmt.Errorf("%s:%d: %s must be a mapping", path, field.node.Line, field.name)
+		}
+	}
+
+	// a case without expectations passes whatever the monitor does
+	if len(c.Fired) == 0 && len(c.NotFired) == 0 && len(c.Exceptions) == 0 && len(c.Trace) == 0 {
+		return nil, fmt.Errorf("%s: case expects nothing: list alerts under fired or not_fired, or set exceptions or trace", path)
+	}
+	return &c, nil
+}
+
+// GateFile returns the file name of the monitor under test.
+func (c *Case) GateFile() string {
+	return c.Monitor + ".gate"
+}
+
+// Request builds the validate request for the case.
+func (c *Case) Request(gate string) hexagate.ValidateRequest {
+	return hexagate.ValidateRequest{
+		Gate:    gate,
+		ChainId: c.ChainId,
+		Params:  c.Params,
+		Mocks:   c.Mocks,
+		Trace:   true,
+	}
+}
+
+// Check compares a validate response against the expectations of the case and returns one line per mismatch.
+func (c *Case) Check(response *hexagate.ValidateResponse) []string {
+	var diffs []string
+
+	for _, description := range c.Fired {
+		if !response.Fired(description) {
+			diffs = append(diffs, fmt.Sprintf("- fired: %q", description))
+		}
+	}
+	for _, description := range c.NotFired {
+		for _, alert := range response.Failed {
+			if strings.Contains(alert.Description, description) {
+				diffs = append(diffs, fmt.Sprintf("+ fired: %q", alert.Description))
+			}
+		}
+	}
+
+	matched := make([]bool, len(response.Exceptions))
+	for _, expected := range c.Exceptions {
+		found := false
+		for i, exception := range response.Exceptions {
+			if strings.Contains(exception.String(), expected) {
+				matched[i], found = true, true
+			}
+		}
+		if !found {
+			diffs = append(diffs, fmt.Sprintf("- exception: %q", expected))
+		}
+	}
+	for i, exception := range response.Exceptions {
+		if !matched[i] {
+			diffs = append(diffs, fmt.Sprintf("+ exception: %q", exception.String()))
+		}
+	}
+
+	names := make([]string, 0, len(c.Trace))
+	for name := range c.Trace {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, ok := response.Trace[name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("- trace %s: %s (missing from trace)", name, canonical(c.Trace[name])))
+			continue
+		}
+		if expected, actual := canonical(c.Trace[name]), canonical(value); expected != actual {
+			diffs = append(diffs, fmt.Sprintf("- trace %s: %s\n+ trace %s: %s", name, expected, name, actual))
+		}
+	}
+	return diffs
+}
+
+// yamlValue converts a YAML node to the value it stands for in a validate request. Integers are kept as
+// json.Number so that values such as bonds survive without rounding, and hex scalars such as addresses, bytes
+// and hashes are kept as strings even when they are not quoted.
+func yamlValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+
+	case yaml.DocumentNode:
+		return yamlValue(node.Content[0])
+
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+
+	case yaml.MappingNode:
+		value := make(map[string]any, len(node.Content)/2)
+		for i := 0; i < len(node.Content); i += 2 {
+			key, elem := node.Content[i], node.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: keys must be scalars", key.Line)
+			}
+			v, err := yamlValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			value[key.Value] = v
+		}
+		return value, nil
+
+	case yaml.SequenceNode:
+		value := make([]any, len(node.Content))
+		for i, elem := range node.Content {
+			v, err := yamlValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = v
+		}
+		return value, nil
+
+	case yaml.ScalarNode:
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			return node.Value, nil
+		}
+		if strings.HasPrefix(node.Value, "0x") || strings.HasPrefix(node.Value, "0X") {
+			return node.Value, nil
+		}
+		switch node.ShortTag() {
+		case "!!null":
+			return nil, nil
+		case "!!bool":
+			var b bool
+			if err := node.Decode(&b); err != nil {
+				return nil, err
+			}
+			return b, nil
+		case "!!int":
+			n, ok := new(big.Int).SetString(strings.ReplaceAll(node.Value, "_", ""), 0)
+			if !ok {
+				return nil, fmt.Errorf("line %d: invalid integer %s", node.Line, node.Value)
+			}
+			return json.Number(n.String()), nil
+		case "!!float":
+			return json.Number(node.Value), nil
+		}
+		return node.Value, nil
+	}
+	return nil, fmt.Errorf("line %d: unsupported YAML node", node.Line)
+}
+
+// canonical renders a trace value as JSON with numbers written out in full, so that expected values loaded from
+// YAML compare equal to the float64 numbers decoded from a response.
+func canonical(v any) string {
+	data, err := json.Marshal(normalize(v))
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func normalize(v any) any {
+	switch v := v.(type) {
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = normalize(elem)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, elem := range v {
+			out[key] = normalize(elem)
+		}
+		return out
+	case float64:
+		return json.Number(new(big.Float).SetFloat64(v).Text('f', -1))
+	case json.Number:
+		if n, ok := new(big.Int).SetString(string(v), 10); ok {
+			return json.Number(n.String())
+		}
+		if f, ok := new(big.Float).SetPrec(256).SetString(string(v)); ok {
+			return json.Number(f.Text('f', -1))
+		}
+		return v
+	case int:
+		return json.Number(fmt.Sprint(v))
+	}
+	return v
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/coverage"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+func TestCases(t *testing.T) {
+	// We expect every YAML test case under cases/ to match the alerts, exceptions and trace it declares
+	cases, err := LoadCases(CASES_DIR)
+	if err != nil {
+		t.Fatalf("Error loading test cases: %v", err)
+	}
+
+	gates := make(map[string]string)
+	for _, c := range cases {
+		c := c
+		t.Run(c.Monitor+"/"+c.Name, func(t *testing.T) {
+			if c.Skip != "" {
+				t.Skip(c.Skip)
+			}
+
+			// read in the gate file once per monitor
+			data, ok := gates[c.Monitor]
+			if !ok {
+				data, err = ReadGateFile(c.GateFile())
+				if err != nil {
+					t.Fatalf("Error reading file %s: %v", c.GateFile(), err)
+				}
+				gates[c.Monitor] = data
+			}
+
+			response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), c.Request(data))
+			if err != nil {
+				t.Fatalf("Error handling validate request for %s: %v", c.Path, err)
+			}
+
+			if diffs := c.Check(response); len(diffs) > 0 {
+				t.Errorf("%s: %s\n%s", c.Path, c.Description, strings.Join(diffs, "\n"))
+			}
+		})
+	}
+}
+
+func TestLoadCase(t *testing.T) {
+	// We expect hex scalars to stay strings and integers to keep their full precision
+	path := filepath.Join(t.TempDir(), "challenger_loses", "example.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := `description: example
+params:
+  disputeGame: 0x0000000000000000000000000000000000000001
+  honestChallenger: "0x49277EE36A024120Ee218127354c4a3591dc90A9"
+mocks:
+  claimCount: 3
+  credit: 1000000000000000000000
+  claimResults:
+    - [0, 0x00, true]
+trace:
+  claimCount: 3
+fired:
+  - Challenger lost one or more subgames
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadCase(path)
+	if err != nil {
+		t.Fatalf("Error loading case: %v", err)
+	}
+	if c.Monitor != "challenger_loses" || c.Name != "example" || c.GateFile() != "challenger_loses.gate" {
+		t.Errorf("Unexpected case identity %s/%s", c.Monitor, c.Name)
+	}
+
+	expectedParams := map[string]any{
+		"disputeGame":      "0x0000000000000000000000000000000000000001",
+		"honestChallenger": "0x49277EE36A024120Ee218127354c4a3591dc90A9",
+	}
+	if !reflect.DeepEqual(c.Params, expectedParams) {
+		t.Errorf("Unexpected params %#v", c.Params)
+	}
+	expectedMocks := map[string]any{
+		"claimCount":   json.Number("3"),
+		"credit":       json.Number("1000000000000000000000"),
+		"claimResults": []any{[]any{json.Number("0"), "0x00", true}},
+	}
+	if !reflect.DeepEqual(c.Mocks, expectedMocks) {
+		t.Errorf("Unexpected mocks %#v", c.Mocks)
+	}
+
+	// a trace value decoded from a response as a float64 still matches the expected integer
+	if canonical(c.Trace["claimCount"]) != canonical(float64(3)) {
+		t.Errorf("Expected trace values to compare by number, got %s and %s", canonical(c.Trace["claimCount"]), canonical(float64(3)))
+	}
+	if canonical(json.Number("1000000000000000000000")) != canonical(float64(1e21)) {
+		t.Errorf("Expected large numbers to compare in full")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	response := &hexagate.ValidateResponse{Failed: []hexagate.Alert{
+		{Description: "Challenger lost the dispute game while challenging a state root"},
+		{Description: "Challenger lost one or more subgames"},
+	}}
+
+	// We expect fired to be a subset of the alerts, matched by substring, so other alerts do not fail the case
+	c := &Case{Fired: []string{"Challenger lost the dispute game while challenging a state root"}}
+	if diffs := c.Check(response); len(diffs) != 0 {
+		t.Errorf("Expected no differences, got %v", diffs)
+	}
+	c = &Case{Fired: []string{"while challenging"}}
+	if diffs := c.Check(response); len(diffs) != 0 {
+		t.Errorf("Expected a substring of the description to match, got %v", diffs)
+	}
+
+	// We expect alerts listed under not_fired to fail the case, and missing alerts under fired as well
+	c = &Case{Fired: []string{"while defending"}, NotFired: []string{"one or more subgames"}}
+	diffs := c.Check(response)
+	expected := []string{`- fired: "while defending"`, `+ fired: "Challenger lost one or more subgames"`}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Expected %v, got %v", expected, diffs)
+	}
+}
+
+func TestLoadCaseRejectsUnknownFields(t *testing.T) {
+	// We expect typos in the case file to fail loudly rather than silently dropping an expectation
+	path := filepath.Join(t.TempDir(), "eth_deficit", "typo.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("description: typo\nfried:\n  - ETH deficit\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadCase(path); err == nil || !strings.Contains(err.Error(), "fried") {
+		t.Errorf("Expected unknown field fried to be rejected, got %v", err)
+	}
+}
+
+func TestLoadCaseRejectsNoExpectations(t *testing.T) {
+	// We expect a case that checks nothing to fail loudly rather than pass whatever the monitor does
+	path := filepath.Join(t.TempDir(), "eth_deficit", "empty.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("description: no expectations\nmocks:\n  delayedWethBalance: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadCase(path); err == nil || !strings.Contains(err.Error(), "case expects nothing") {
+		t.Errorf("Expected a case without expectations to be rejected, got %v", err)
+	}
+}
@@ -1,8 +1,13 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/base-org/fault-proof-monitors/coverage"
+	"github.com/base-org/fault-proof-monitors/disputegame"
+	"github.com/base-org/fault-proof-monitors/hexagate"
 )
 
 var (
@@ -43,10 +48,11 @@ func TestChallengedProposalChallengerAttacksRootClaim(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSixteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSixteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -93,10 +99,11 @@ func TestChallengedProposalChallengerDefendsRootClaim(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSixteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSixteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -144,10 +151,11 @@ func TestChallengedProposalChallengerNotKnown(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSixteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSixteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -196,10 +204,11 @@ func TestChallengedProposalProposerNotKnown(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSixteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSixteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -243,10 +252,11 @@ func TestChallengedProposalOnlyRootClaim(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSixteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSixteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -293,10 +303,11 @@ func TestChallengedProposalNoMoveEventInBlock(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSixteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSixteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -310,3 +321,54 @@ func TestChallengedProposalNoMoveEventInBlock(t *testing.T) {
 		t.Errorf("Monitor fired an alert for %s when it was not supposed to", monitorSixteenFile)
 	}
 }
+
+func TestChallengedProposalChallengerAttacksDeepInGame(t *testing.T) {
+	// We expect an alert to be fired when the challenger attacks a claim that ultimately attacks the root claim,
+	// deeper in the game than the first move
+
+	// set the params, which DO matter for these tests
+	params := map[string]any{
+		"disputeGame":      "0x0000000000000000000000000000000000000000",
+		"honestProposer":   "0x49277EE36A024120Ee218127354c4a3591dc90A9",
+		"honestChallenger": "0xc96775081bcA132B0E7cbECDd0B58d9Ec07Fdaa4",
+	}
+
+	// read in the gate file
+	data, err := ReadGateFile(monitorSixteenFile)
+	if err != nil {
+		t.Errorf("Error reading file %s: %v", monitorSixteenFile, err)
+	}
+
+	// set the mock data that we will pass along with the Gate file and params to the validate request endpoint
+	// a random address attacks the root claim, the honest proposer counters, and the honest challenger attacks
+	// the counter, whose parent index is even
+	game := disputegame.NewGame().
+		Root("0x49277EE36A024120Ee218127354c4a3591dc90A9").
+		Attack(0, "0x09dE888033b1e815419a3fb865f0DA5689332FdB").
+		Attack(1, "0x49277EE36A024120Ee218127354c4a3591dc90A9").
+		Attack(2, "0xc96775081bcA132B0E7cbECDd0B58d9Ec07Fdaa4")
+	mocks := map[string]any{
+		"moveEvents": game.MoveEvents(),
+		"claimCount": game.ClaimCount(),
+		"claimData":  game.ClaimData(),
+	}
+
+	// call the validate request endpoint and parse the results
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
+	if err != nil {
+		t.Fatalf("Error handling validate request for %s: %v", monitorSixteenFile, err)
+	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
+
+	// check if the validate request threw any exceptions
+	if len(exceptions) > 0 {
+		fmt.Println(trace)
+		t.Errorf("Exceptions for %s: %v", monitorSixteenFile, exceptions)
+	}
+
+	// we expect to see the alert fired
+	if len(failed) == 0 {
+		fmt.Println(trace)
+		t.Errorf("Monitor did not fire an alert for %s when it was supposed to", monitorSixteenFile)
+	}
+}
@@ -1,8 +1,12 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/base-org/fault-proof-monitors/coverage"
+	"github.com/base-org/fault-proof-monitors/hexagate"
 )
 
 var (
@@ -25,7 +29,7 @@ func TestCreditAndBondDiscrepancyWrongBondAmount(t *testing.T) {
 
 	// set the mock data that we will pass along with the Gate file and params to the validate request endpoint
 	mocks := map[string]any{
-		"addressesInTrace": []any{"0x000000000000000000000000000000000000000"},
+		"addressesInTrace": []any{"0x0000000000000000000000000000000000000000"},
 		"resolveCalls": [][]interface{}{
 			{0, 111111},
 			{1, 111111},
@@ -44,10 +48,11 @@ func TestCreditAndBondDiscrepancyWrongBondAmount(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSeventeenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSeventeenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -78,7 +83,7 @@ func TestCreditAndBondDiscrepancyWrongCreditAmount(t *testing.T) {
 
 	// set the mock data that we will pass along with the Gate file and params to the validate request endpoint
 	mocks := map[string]any{
-		"addressesInTrace": []any{"0x000000000000000000000000000000000000000"},
+		"addressesInTrace": []any{"0x0000000000000000000000000000000000000000"},
 		"resolveCalls": [][]interface{}{
 			{0, 111111},
 			{1, 111111},
@@ -97,10 +102,11 @@ func TestCreditAndBondDiscrepancyWrongCreditAmount(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSeventeenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSeventeenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -131,7 +137,7 @@ func TestCreditAndBondDiscrepancyWrongClaimantAddress(t *testing.T) {
 
 	// set the mock data that we will pass along with the Gate file and params to the validate request endpoint
 	mocks := map[string]any{
-		"addressesInTrace": []any{"0x000000000000000000000000000000000000000"},
+		"addressesInTrace": []any{"0x0000000000000000000000000000000000000000"},
 		"resolveCalls": [][]interface{}{
 			{0, 111111},
 			{1, 111111},
@@ -150,10 +156,11 @@ func TestCreditAndBondDiscrepancyWrongClaimantAddress(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSeventeenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSeventeenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -185,7 +192,7 @@ func TestCreditAndBondDiscrepancyCorrectAmountsAndAddresses(t *testing.T) {
 
 	// set the mock data that we will pass along with the Gate file and params to the validate request endpoint
 	mocks := map[string]any{
-		"addressesInTrace": []any{"0x000000000000000000000000000000000000000"},
+		"addressesInTrace": []any{"0x0000000000000000000000000000000000000000"},
 		"resolveCalls": [][]interface{}{
 			{0, 111111},
 			{1, 111111},
@@ -204,10 +211,11 @@ func TestCreditAndBondDiscrepancyCorrectAmountsAndAddresses(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSeventeenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSeventeenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -256,10 +264,11 @@ func TestCreditAndBondDiscrepancyNoFilterAddress(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorSeventeenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorSeventeenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -1,8 +1,12 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/base-org/fault-proof-monitors/coverage"
+	"github.com/base-org/fault-proof-monitors/hexagate"
 )
 
 var (
@@ -47,10 +51,11 @@ func TestDuplicateDisputeGameCreated(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorFiveFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorFiveFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -108,10 +113,11 @@ func TestMultipleDuplicateDisputeGamesCreated(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorFiveFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorFiveFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -126,7 +132,7 @@ func TestMultipleDuplicateDisputeGamesCreated(t *testing.T) {
 	}
 
 	// check to make sure two duplicate dispute game instances were identified
-	duplicateGames := trace.(map[string]interface{})["foundDuplicateGameInfo"]
+	duplicateGames := trace["foundDuplicateGameInfo"]
 	if len(duplicateGames.([]interface{})) != 2 || duplicateGames.([]interface{})[0].(bool) != true || duplicateGames.([]interface{})[1].(bool) != true {
 		fmt.Println(trace)
 		t.Errorf("Monitor did not identify the correct number of duplicate dispute games")
@@ -174,10 +180,11 @@ func TestDuplicateDisputeGameCreatedInSameBlock(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorFiveFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorFiveFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -234,10 +241,11 @@ func TestDuplicateDisputeGameCreatedDifferentGameTypes(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorFiveFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorFiveFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -286,10 +294,11 @@ func TestNoDuplicateDisputeGameCreatedNoHistory(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorFiveFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorFiveFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -338,10 +347,11 @@ func TestNoDuplicateDisputeGameCreatedNoGameCreatedInCurrBlock(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorFiveFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorFiveFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
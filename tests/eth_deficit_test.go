@@ -1,8 +1,12 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/base-org/fault-proof-monitors/coverage"
+	"github.com/base-org/fault-proof-monitors/hexagate"
 )
 
 var (
@@ -33,10 +37,11 @@ func TestETHDeficitTotalCreditDeficit(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorElevenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorElevenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -75,10 +80,11 @@ func TestETHDeficitTotalETHBalanceDeficit(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorElevenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorElevenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -117,10 +123,11 @@ func TestETHDeficitCurrCreditZeroTotalCreditNonZero(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorElevenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorElevenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -159,10 +166,11 @@ func TestETHDeficitNoDeficit(t *testing.T) {
 	}
 
 	// call the validate request endpoint and parse the results
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorElevenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorElevenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
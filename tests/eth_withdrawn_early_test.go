@@ -1,8 +1,12 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/base-org/fault-proof-monitors/coverage"
+	"github.com/base-org/fault-proof-monitors/hexagate"
 )
 
 var (
@@ -49,10 +53,11 @@ func TestETHWithdrawnTooEarly(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorTenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorTenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -105,10 +110,11 @@ func TestETHWithdrawnTooEarlyNoMatchingUnlock(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorTenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorTenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -162,10 +168,11 @@ func TestETHWithdrawnTooEarlyIncorrectAmount(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorTenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorTenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -220,10 +227,11 @@ func TestETHWithdrawnTooEarlyCorrectWithdrawal(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorTenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorTenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -271,10 +279,11 @@ func TestETHWithdrawnTooEarlyNoClaimInBlock(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorTenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorTenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -328,10 +337,11 @@ func TestETHWithdrawnTooEarlyNoFilterAddress(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorTenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorTenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
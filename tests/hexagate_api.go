@@ -1,102 +1,173 @@
 package tests
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-
-	"github.com/joho/godotenv"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/coverage"
+	"github.com/base-org/fault-proof-monitors/gate"
+	"github.com/base-org/fault-proof-monitors/hexagate"
+	"github.com/base-org/fault-proof-monitors/hexagate/hexagatetest"
+	"github.com/base-org/fault-proof-monitors/hexagate/preflight"
+	"github.com/base-org/fault-proof-monitors/monitors"
 )
 
 const (
-	VALIDATE_ENDPOINT = "https://api.hexagate.com/api/v1/invariants/validate"
+	CASSETTES_DIR = "testdata/cassettes"
 )
 
-type ValidateRequest struct {
-	Gate    string         `json:"gate"`
-	ChainId int            `json:"chain_id"`
-	Params  map[string]any `json:"params"`
-	Mocks   map[string]any `json:"mocks"`
-	Trace   bool           `json:"trace"`
-}
+var (
+	// offline routes every validate request to an in-process fake instead of the Hexagate API.
+	// It can also be enabled with HEXAGATE_OFFLINE=true. Without it, a missing API key fails the run.
+	offline = flag.Bool("offline", false, "run validate requests against the in-process fake Hexagate server")
 
-type ValidateResponse struct {
-	Count      int   `json:"count"`
-	Failed     []any `json:"failed"`
-	Exceptions []any `json:"exceptions"`
-	Trace      any   `json:"trace"`
-}
+	// record stores every validate response from the Hexagate API as a cassette, and replay answers validate
+	// requests from those cassettes without network access or an API key.
+	record = flag.Bool("record", false, "record Hexagate validate responses to "+CASSETTES_DIR)
+	replay = flag.Bool("replay", false, "replay Hexagate validate responses from "+CASSETTES_DIR)
+
+	// warnUnmocked prints a warning for every source that reads the chain but is not mocked by a test.
+	warnUnmocked = flag.Bool("warn-unmocked", false, "warn about sources that read the chain but are not mocked")
+
+	// chainId selects the chain the monitors are validated on. It can also be set with HEXAGATE_CHAIN_ID.
+	chainId = flag.Int("chain-id", 0, "chain ID to validate the monitors on (default 1, or HEXAGATE_CHAIN_ID)")
+
+	// coverageOut writes the alerts fired by every validate request to a file for the gatecov report.
+	coverageOut = flag.String("coverage", "", "write the invariants fired by each test to this file, for gatecov")
+
+	// monitorsDir overrides the embedded monitors registry with a directory of gate files, e.g. the mutants
+	// written by gatemut.
+	monitorsDir = flag.String("monitors", "", "directory to read the gate monitors from instead of the embedded monitors")
+)
 
+// ReadGateFile returns the source of a monitor from the monitors registry, or from the -monitors directory
+// when it is set.
 func ReadGateFile(filename string) (string, error) {
-	file, err := os.Open(fmt.Sprintf("../monitors/%s", filename))
-	if err != nil {
-		return "", err
+	if *monitorsDir != "" {
+		data, err := os.ReadFile(filepath.Join(*monitorsDir, filename))
+		if err != nil {
+			return "", err
+		}
+		gateFiles.Store(string(data), filename)
+		return string(data), nil
 	}
-	defer file.Close()
 
-	data, err := io.ReadAll(file)
+	m, err := monitors.Get(filename)
 	if err != nil {
 		return "", err
 	}
-	return string(data[:]), nil
+	gateFiles.Store(m.Source, m.File)
+	return m.Source, nil
 }
 
-func HandleValidateRequest(gatefile string, params map[string]any, mocks map[string]any) ([]any, []any, any, error) {
-	requestData := ValidateRequest{
-		Gate:    gatefile,
-		ChainId: 1,
-		Params:  params,
-		Mocks:   mocks,
-		Trace:   true,
-	}
+// gateFiles maps the contents of every gate file read by ReadGateFile to its file name, so that preflight
+// errors can name the monitor a request was built from.
+var gateFiles sync.Map
 
-	// marshal data into expected JSON format
-	data := new(bytes.Buffer)
-	enc := json.NewEncoder(data)
-	enc.SetEscapeHTML(false)
-	err := enc.Encode(requestData)
+// preflightRequest fails validate requests whose params or mocks do not match the declarations of the monitor
+// before they are sent.
+func preflightRequest(ctx context.Context, request hexagate.ValidateRequest) error {
+	monitor := "<gate>"
+	if name, ok := gateFiles.Load(request.Gate); ok {
+		monitor = name.(string)
+	}
+	file, err := gate.ParseFile(monitor, []byte(request.Gate))
 	if err != nil {
-		return []any{}, []any{}, nil, err
+		// leave syntax errors to Hexagate, which reports them as exceptions
+		return nil
 	}
 
-	// retrieve API key from .env file and call the API endpoint
-	err = godotenv.Load("../.env")
-	if err != nil {
-		return []any{}, []any{}, nil, err
+	result := preflight.Check(monitor, file, request)
+	if *warnUnmocked {
+		for _, warning := range result.Warnings {
+			fmt.Printf("Warning: %s: %s\n", monitor, warning)
+		}
 	}
+	return result.Err()
+}
 
-	// create the POST request
-	req, err := http.NewRequest("POST", VALIDATE_ENDPOINT, data)
-	if err != nil {
-		return []any{}, []any{}, nil, err
+// recorder collects the alerts fired by every validate request made by a test with a known monitor.
+var recorder coverage.Recorder
+
+// recordCoverage records which invariants fired for the test named by the context of the request.
+func recordCoverage(ctx context.Context, request hexagate.ValidateRequest, response *hexagate.ValidateResponse) {
+	name, ok := gateFiles.Load(request.Gate)
+	test := coverage.TestFrom(ctx)
+	if !ok || test == "" {
+		return
 	}
+	var exceptions []string
+	for _, exception := range response.Exceptions {
+		exceptions = append(exceptions, exception.String())
+	}
+	recorder.Add(coverage.Record{Test: test, Monitor: name.(string), Fired: response.Descriptions(), Exceptions: exceptions})
+}
 
-	// set the appliable headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Hexagate-Api-Key", os.Getenv("HEXAGATE_API_KEY"))
+// NewClient returns the Hexagate client the monitor tests validate with. The API key is loaded from ../.env
+// or the environment, and the in-process fake is used only when offline mode is requested, so that a run without
+// an API key fails instead of passing without Hexagate. The returned function shuts down the fake, if one was
+// started.
+func NewClient() (*hexagate.Client, func(), error) {
+	if *record && *replay {
+		return nil, nil, fmt.Errorf("-record and -replay cannot be used together")
+	}
 
-	// send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return []any{}, []any{}, nil, err
+	chain := hexagate.CHAIN_ID_MAINNET
+	if value, ok := os.LookupEnv("HEXAGATE_CHAIN_ID"); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid HEXAGATE_CHAIN_ID value %q: %w", value, err)
+		}
+		chain = parsed
+	}
+	if *chainId != 0 {
+		chain = *chainId
 	}
-	defer resp.Body.Close()
 
-	// parse and decode the response
-	var response ValidateResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
+	useFake := *offline
+	if value, ok := os.LookupEnv("HEXAGATE_OFFLINE"); ok {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid HEXAGATE_OFFLINE value %q: %w", value, err)
+		}
+		useFake = useFake || enabled
+	}
+
+	// a missing .env is fine as the key may come from the environment or offline mode may be used
+	keys := hexagate.DotEnvKey("../.env", hexagate.API_KEY_ENV)
+	key, err := keys(context.Background())
 	if err != nil {
-		return []any{}, []any{}, nil, err
+		return nil, nil, err
+	}
+
+	opts := []hexagate.Option{hexagate.WithChainID(chain), hexagate.WithPreflight(preflightRequest), hexagate.WithObserver(recordCoverage)}
+	switch {
+	case useFake:
+		// offline mode takes precedence, so tests that force the fake keep working while recording
+	case *replay:
+		return hexagatetest.NewReplayingClient(CASSETTES_DIR, opts...), func() {}, nil
+	case *record:
+		if key == "" {
+			return nil, nil, fmt.Errorf("-record calls the Hexagate API and needs %s to be set", hexagate.API_KEY_ENV)
+		}
+		return hexagatetest.NewRecordingClient(CASSETTES_DIR, append(opts, hexagate.WithAPIKey(keys))...), func() {}, nil
+	case key == "":
+		return nil, nil, fmt.Errorf("%s is not set: configure it in .env, or run against the local interpreter with -offline or HEXAGATE_OFFLINE=true, or replay recorded responses with -replay", hexagate.API_KEY_ENV)
 	}
 
-	// access the response data
-	failed := response.Failed
-	exceptions := response.Exceptions
-	trace := response.Trace
+	if !useFake {
+		return hexagate.NewClient(append(opts, hexagate.WithAPIKey(keys))...), func() {}, nil
+	}
 
-	return failed, exceptions, trace, nil
+	fake := hexagatetest.NewServer(nil)
+	if testing.Verbose() {
+		fmt.Fprintln(os.Stderr, "Running validate requests against the offline Hexagate fake at", fake.URL)
+	}
+	return fake.NewClient(opts...), fake.Close, nil
 }
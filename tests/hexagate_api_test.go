@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+func TestNewClientOffline(t *testing.T) {
+	// We expect the harness to use the fake on the chain from HEXAGATE_CHAIN_ID when offline mode is enabled
+	t.Setenv("HEXAGATE_OFFLINE", "true")
+	t.Setenv("HEXAGATE_CHAIN_ID", "8453")
+
+	offlineClient, closeFake, err := NewClient()
+	if err != nil {
+		t.Fatalf("Error creating offline client: %v", err)
+	}
+	defer closeFake()
+
+	if offlineClient.ChainID() != hexagate.CHAIN_ID_BASE && *chainId == 0 {
+		t.Errorf("Expected chain ID %d, got %d", hexagate.CHAIN_ID_BASE, offlineClient.ChainID())
+	}
+
+	mocks := map[string]any{"claimCount": 3}
+	response, err := offlineClient.Validate(context.Background(), hexagate.ValidateRequest{Gate: "source claimCount: integer = 3;", Mocks: mocks, Trace: true})
+	if err != nil {
+		t.Fatalf("Error handling offline validate request: %v", err)
+	}
+
+	if len(response.Failed) != 0 || len(response.Exceptions) != 0 {
+		t.Errorf("Expected no alerts or exceptions, got %v and %v", response.Failed, response.Exceptions)
+	}
+	if response.Trace == nil {
+		t.Errorf("Expected a trace to be returned")
+	}
+}
+
+func TestPreflightRejectsMockTypos(t *testing.T) {
+	// We expect a mock key that is not a source to fail the request before it is sent, naming the closest source
+	data, err := ReadGateFile(monitorSixteenFile)
+	if err != nil {
+		t.Fatalf("Error reading file %s: %v", monitorSixteenFile, err)
+	}
+
+	mocks := map[string]any{"claimDatas": [][]interface{}{}}
+	_, err = client.Validate(context.Background(), hexagate.ValidateRequest{Gate: data, Mocks: mocks, Trace: true})
+	if err == nil || !strings.Contains(err.Error(), "challenged_proposal.gate: mock claimDatas is not a declared source, did you mean claimData?") {
+		t.Errorf("Expected the mock typo to be rejected, got %v", err)
+	}
+}
+
+func TestPreflightRejectsInvalidParams(t *testing.T) {
+	// We expect a malformed address, a missing param and an undeclared param to fail the request before it is sent
+	data, err := ReadGateFile(monitorSixteenFile)
+	if err != nil {
+		t.Fatalf("Error reading file %s: %v", monitorSixteenFile, err)
+	}
+
+	params := map[string]any{"disputeGame": "0x000000000000000000000000000000000000000", "honestChallenger": "0x0000000000000000000000000000000000000001", "cbChallenger": "0x0000000000000000000000000000000000000002"}
+	_, err = client.Validate(context.Background(), hexagate.ValidateRequest{Gate: data, Params: params, Trace: true})
+	if err == nil || !strings.Contains(err.Error(), "challenged_proposal.gate: param disputeGame: address 0x000000000000000000000000000000000000000 must be 20 bytes (40 hex digits), got 39 hex digits") {
+		t.Errorf("Expected the malformed address to be rejected, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "challenged_proposal.gate: param honestProposer is declared as address but not set") {
+		t.Errorf("Expected the missing param to be rejected, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "challenged_proposal.gate: param cbChallenger is not declared") {
+		t.Errorf("Expected the undeclared param to be rejected, got %v", err)
+	}
+}
@@ -1,8 +1,12 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/base-org/fault-proof-monitors/coverage"
+	"github.com/base-org/fault-proof-monitors/hexagate"
 )
 
 var (
@@ -44,10 +48,11 @@ func TestIncorrectBondBalanceIncorrectFutureETHUnlocked(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorEighteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorEighteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -96,10 +101,11 @@ func TestIncorrectBondBalancePartiallyResolvedMinClaim(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorEighteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorEighteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -152,10 +158,11 @@ func TestIncorrectBondBalanceIncorrectCurrETHUnlocked(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorEighteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorEighteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -212,10 +219,11 @@ func TestIncorrectBondBalanceCorrectETHValues(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorEighteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorEighteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -261,10 +269,11 @@ func TestIncorrectBondBalanceNoClaimsResolvedYet(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorEighteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorEighteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -314,10 +323,11 @@ func TestIncorrectBondBalanceNoFilterAddress(t *testing.T) {
 	}
 
 	// call out to hexagate API to run the gate file with params and mocks
-	failed, exceptions, trace, err := HandleValidateRequest(data, params, mocks)
+	response, err := client.Validate(coverage.WithTest(context.Background(), t.Name()), hexagate.ValidateRequest{Gate: data, Params: params, Mocks: mocks, Trace: true})
 	if err != nil {
-		t.Errorf("Error handling validate request for %s: %v", monitorEighteenFile, err)
+		t.Fatalf("Error handling validate request for %s: %v", monitorEighteenFile, err)
 	}
+	failed, exceptions, trace := response.Failed, response.Exceptions, response.Trace
 
 	// check if the validate request threw any exceptions
 	if len(exceptions) > 0 {
@@ -0,0 +1,36 @@
+package tests
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/base-org/fault-proof-monitors/hexagate"
+)
+
+// client is shared by every monitor test, so the API key is loaded once per run.
+var client *hexagate.Client
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	var closeFake func()
+	var err error
+	client, closeFake, err = NewClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating Hexagate client: %v\n", err)
+		os.Exit(2)
+	}
+
+	code := m.Run()
+	closeFake()
+
+	if *coverageOut != "" {
+		if err := recorder.WriteFile(*coverageOut); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing coverage records: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	os.Exit(code)
+}